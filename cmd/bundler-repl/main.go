@@ -2,18 +2,19 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
+	"github.com/sombrerosheep/partsbundler/internal/config"
+	"github.com/sombrerosheep/partsbundler/pkg/bom"
 	"github.com/sombrerosheep/partsbundler/pkg/core"
 )
 
-const (
-	dbPath = "../../data/partsbundler.db"
-)
-
 type CannotParseCommand struct {
 	input string
 }
@@ -30,6 +31,119 @@ func printInput(words []string) {
 	fmt.Printf("\n")
 }
 
+// parseKitBuild parses a kit build written as kitId or kitId:count.
+func parseKitBuild(word string) (core.KitBuild, error) {
+	parts := strings.SplitN(word, ":", 2)
+
+	kitId, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return core.KitBuild{}, err
+	}
+
+	count := uint64(1)
+	if len(parts) > 1 {
+		count, err = strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			return core.KitBuild{}, err
+		}
+	}
+
+	return core.KitBuild{KitID: kitId, Count: count}, nil
+}
+
+// parseYesNo parses the yes/no flags of the find command.
+func parseYesNo(word string) (*bool, error) {
+	var b bool
+
+	switch strings.ToLower(word) {
+	case "yes", "y":
+		b = true
+	case "no", "n":
+		b = false
+	default:
+		parsed, err := strconv.ParseBool(word)
+		if err != nil {
+			return nil, err
+		}
+		b = parsed
+	}
+
+	return &b, nil
+}
+
+// parsePartQuery parses key=value filters into a PartQuery.
+func parsePartQuery(words []string) (core.PartQuery, bool, error) {
+	query := core.PartQuery{}
+
+	for _, word := range words {
+		kv := strings.SplitN(word, "=", 2)
+		if len(kv) != 2 {
+			return query, false, nil
+		}
+
+		var err error
+
+		switch strings.ToLower(kv[0]) {
+		case "kind":
+			query.Kind = core.PartType(kv[1])
+		case "text":
+			query.Text = kv[1]
+		case "min":
+			query.Min = kv[1]
+		case "max":
+			query.Max = kv[1]
+		case "used":
+			query.InUse, err = parseYesNo(kv[1])
+		case "links":
+			query.HasLinks, err = parseYesNo(kv[1])
+		default:
+			return query, false, nil
+		}
+
+		if err != nil {
+			return query, false, err
+		}
+	}
+
+	return query, true, nil
+}
+
+// parseBOMMapping reads the ref, value, kind and qty column names and
+// the kinds aliases of the import bom command into the default mapping.
+// The words which are not options are returned for the kit's name.
+func parseBOMMapping(words []string) (bom.Mapping, []string, error) {
+	mapping := bom.DefaultMapping()
+	rest := []string{}
+
+	for _, word := range words {
+		kv := strings.SplitN(word, "=", 2)
+		if len(kv) != 2 {
+			rest = append(rest, word)
+			continue
+		}
+
+		switch strings.ToLower(kv[0]) {
+		case "ref":
+			mapping.Ref = kv[1]
+		case "value":
+			mapping.Value = kv[1]
+		case "kind":
+			mapping.Kind = kv[1]
+		case "qty":
+			mapping.Quantity = kv[1]
+		case "kinds":
+			err := mapping.AddKinds(kv[1])
+			if err != nil {
+				return mapping, nil, err
+			}
+		default:
+			rest = append(rest, word)
+		}
+	}
+
+	return mapping, rest, nil
+}
+
 // GetCommand parses the provided input and returns a
 // ReplCmd to be Executed.
 func GetCommand(input string) (ReplCmd, error) {
@@ -67,22 +181,61 @@ func GetCommand(input string) (ReplCmd, error) {
 				}
 
 				return GetPartCmd{id}, nil
+			} else if words[1] == "duplicates" {
+				return GetDuplicatesCmd{}, nil
+			} else if words[1] == "stock" && len(words) >= 3 {
+				id, err := strconv.ParseInt(words[2], 10, 64)
+				if err != nil {
+					return nil, err
+				}
+
+				return GetStockCmd{id}, nil
+			} else if words[1] == "stock" {
+				return GetAllStockCmd{}, nil
+			} else if words[1] == "stockhistory" && len(words) >= 3 {
+				id, err := strconv.ParseInt(words[2], 10, 64)
+				if err != nil {
+					return nil, err
+				}
+
+				return GetStockHistoryCmd{id}, nil
+			}
+		}
+
+	case "find":
+		{
+			if words[1] == "parts" {
+				query, ok, err := parsePartQuery(words[2:])
+				if err != nil {
+					return nil, err
+				}
+
+				if ok {
+					return FindPartsCmd{query}, nil
+				}
 			}
 		}
 
 	case "new":
 		{
 			if words[1] == "part" && len(words) >= 4 {
-				name := words[3]
-				kind := words[2]
+				part := core.Part{Name: words[3], Kind: core.PartType(words[2])}
+
+				err := part.Validate()
+				if err != nil {
+					return nil, err
+				}
 
-				return NewPartCmd{name, core.PartType(kind)}, nil
+				return NewPartCmd{part.Name, part.Kind}, nil
 			} else if words[1] == "kit" && len(words) >= 5 {
-				name := words[2]
-				schematic := words[3]
-				diagram := words[4]
+				kit := core.Kit{Name: words[2], Schematic: words[3], Diagram: words[4]}
 
-				return NewKitCmd{name, schematic, diagram}, nil
+				err := kit.Validate()
+				if err != nil {
+					return nil, err
+				}
+
+				return NewKitCmd{kit.Name, kit.Schematic, kit.Diagram}, nil
 			}
 		}
 
@@ -95,6 +248,11 @@ func GetCommand(input string) (ReplCmd, error) {
 				}
 				link := words[3]
 
+				err = core.Link{URL: link}.Validate()
+				if err != nil {
+					return nil, err
+				}
+
 				return AddPartLinkCmd{id, link}, nil
 			} else if words[1] == "kitlink" && len(words) >= 4 {
 				id, err := strconv.ParseInt(words[2], 10, 64)
@@ -103,6 +261,11 @@ func GetCommand(input string) (ReplCmd, error) {
 				}
 				link := words[3]
 
+				err = core.Link{URL: link}.Validate()
+				if err != nil {
+					return nil, err
+				}
+
 				return AddKitLinkCmd{id, link}, nil
 			} else if words[1] == "kitpart" && len(words) >= 5 {
 				kitId, err := strconv.ParseInt(words[2], 10, 64)
@@ -120,6 +283,11 @@ func GetCommand(input string) (ReplCmd, error) {
 					return nil, err
 				}
 
+				err = core.ValidateQuantity(qty)
+				if err != nil {
+					return nil, err
+				}
+
 				return AddKitPartCmd{kitId, partId, qty}, nil
 			}
 		}
@@ -183,7 +351,174 @@ func GetCommand(input string) (ReplCmd, error) {
 					return nil, err
 				}
 
+				err = core.ValidateQuantity(qty)
+				if err != nil {
+					return nil, err
+				}
+
 				return SetKitPartQuantityCmd{kitId, partId, qty}, nil
+			} else if words[1] == "designators" && len(words) >= 5 {
+				kitId, err := strconv.ParseInt(words[2], 10, 64)
+				if err != nil {
+					return nil, err
+				}
+
+				partId, err := strconv.ParseInt(words[3], 10, 64)
+				if err != nil {
+					return nil, err
+				}
+
+				designators := core.ParseDesignators(strings.Join(words[4:], " "))
+
+				return SetKitPartDesignatorsCmd{kitId, partId, designators}, nil
+			} else if words[1] == "part" && len(words) >= 5 {
+				id, err := strconv.ParseInt(words[2], 10, 64)
+				if err != nil {
+					return nil, err
+				}
+
+				value := strings.Join(words[4:], " ")
+				patch := core.PartPatch{}
+
+				switch words[3] {
+				case "name":
+					patch.Name = &value
+				case "kind":
+					kind := core.PartType(value)
+					patch.Kind = &kind
+				default:
+					return nil, CannotParseCommand{input}
+				}
+
+				return SetPartCmd{id, patch}, nil
+			} else if words[1] == "kit" && len(words) >= 5 {
+				id, err := strconv.ParseInt(words[2], 10, 64)
+				if err != nil {
+					return nil, err
+				}
+
+				value := strings.Join(words[4:], " ")
+				patch := core.KitPatch{}
+
+				switch words[3] {
+				case "name":
+					patch.Name = &value
+				case "schematic":
+					patch.Schematic = &value
+				case "diagram":
+					patch.Diagram = &value
+				default:
+					return nil, CannotParseCommand{input}
+				}
+
+				return SetKitCmd{id, patch}, nil
+			}
+		}
+
+	case "stock":
+		{
+			adjustments := map[string]core.AdjustmentKind{
+				"add":  core.Received,
+				"use":  core.Consumed,
+				"set":  core.Counted,
+				"lose": core.Lost,
+			}
+
+			if kind, ok := adjustments[words[1]]; ok && len(words) >= 4 {
+				partId, err := strconv.ParseInt(words[2], 10, 64)
+				if err != nil {
+					return nil, err
+				}
+
+				qty, err := strconv.ParseUint(words[3], 10, 64)
+				if err != nil {
+					return nil, err
+				}
+
+				note := strings.Join(words[4:], " ")
+
+				return AdjustStockCmd{partId, kind, qty, note}, nil
+			} else if words[1] == "location" && len(words) >= 4 {
+				partId, err := strconv.ParseInt(words[2], 10, 64)
+				if err != nil {
+					return nil, err
+				}
+
+				location := strings.Join(words[3:], " ")
+
+				return SetStockLocationCmd{partId, location}, nil
+			}
+		}
+
+	case "plan":
+		{
+			cmd := PlanCmd{builds: []core.KitBuild{}}
+
+			for _, word := range words[1:] {
+				if word == "stock" {
+					cmd.useStock = true
+					continue
+				}
+
+				build, err := parseKitBuild(word)
+				if err != nil {
+					return nil, err
+				}
+
+				cmd.builds = append(cmd.builds, build)
+			}
+
+			if len(cmd.builds) > 0 {
+				return cmd, nil
+			}
+		}
+
+	case "export":
+		{
+			if words[1] == "kit" && len(words) >= 4 {
+				id, err := strconv.ParseInt(words[2], 10, 64)
+				if err != nil {
+					return nil, err
+				}
+
+				return ExportKitCmd{id, words[3]}, nil
+			}
+		}
+
+	case "import":
+		{
+			if words[1] == "kit" && len(words) >= 3 {
+				return ImportKitCmd{words[2]}, nil
+			} else if words[1] == "bom" && len(words) >= 3 {
+				mapping, rest, err := parseBOMMapping(words[3:])
+				if err != nil {
+					return nil, err
+				}
+
+				name := strings.Join(rest, " ")
+				if name == "" {
+					base := filepath.Base(words[2])
+					name = strings.TrimSuffix(base, filepath.Ext(base))
+				}
+
+				return ImportBOMCmd{words[2], name, mapping}, nil
+			}
+		}
+
+	case "merge":
+		{
+			if words[1] == "part" && len(words) >= 4 {
+				partId, err := strconv.ParseInt(words[2], 10, 64)
+				if err != nil {
+					return nil, err
+				}
+
+				duplicateId, err := strconv.ParseInt(words[3], 10, 64)
+				if err != nil {
+					return nil, err
+				}
+
+				return MergePartCmd{partId, duplicateId}, nil
 			}
 		}
 
@@ -211,12 +546,21 @@ func GetCommand(input string) (ReplCmd, error) {
 }
 
 func main() {
+	cfg, err := config.Load("bundler-repl", os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error loading config: %s\n", err)
+		os.Exit(2)
+	}
+
 	fmt.Println("Hello")
 
 	state := &ReplState{}
-	err := state.Init()
+	err = state.Init(cfg)
 	if err != nil {
-		fmt.Printf("Error initializing sqlite service: %s\n", err)
+		fmt.Printf("Error initializing bundler service: %s\n", err)
 		return
 	}
 
@@ -243,5 +587,9 @@ func main() {
 		}
 	}
 
+	if err = state.bundler.Close(); err != nil {
+		fmt.Printf("Error closing bundler service: %s\n", err)
+	}
+
 	fmt.Println("byebye.")
 }
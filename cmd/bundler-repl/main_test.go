@@ -5,30 +5,74 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/sombrerosheep/partsbundler/pkg/bom"
 	"github.com/sombrerosheep/partsbundler/pkg/core"
 	"github.com/stretchr/testify/assert"
 )
 
+func strPtr(s string) *string {
+	return &s
+}
+
+func kindPtr(k core.PartType) *core.PartType {
+	return &k
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
 func Test_GetCommand(t *testing.T) {
+	customMapping := bom.DefaultMapping()
+	customMapping.Value = "Part"
+	customMapping.Quantity = "Count"
+	customMapping.AddKinds("Tube:IC,Knob:Potentiometer")
+
 	tests := []struct {
 		input    string
 		expected ReplCmd
 	}{
 		{"get parts", GetPartsCmd{}},
 		{"get part 1234", GetPartCmd{partId: 1234}},
-		{"new part partType partName", NewPartCmd{name: "partName", kind: core.PartType("partType")}},
+		{"new part Resistor 4k7", NewPartCmd{name: "4k7", kind: core.Resistor}},
 		{"delete part 1234", DeletePartCmd{partId: 1234}},
 		{"add partlink 1234 example.com", AddPartLinkCmd{partId: 1234, link: "example.com"}},
 		{"remove partlink 1234 789", RemovePartLinkCmd{partId: 1234, linkId: 789}},
+		{"get duplicates", GetDuplicatesCmd{}},
+		{"find parts", FindPartsCmd{}},
+		{"find parts kind=Capacitor min=10nf max=100nf", FindPartsCmd{query: core.PartQuery{Kind: core.Capacitor, Min: "10nf", Max: "100nf"}}},
+		{"find parts text=tl07 used=no links=yes", FindPartsCmd{query: core.PartQuery{Text: "tl07", InUse: boolPtr(false), HasLinks: boolPtr(true)}}},
+		{"merge part 2 9", MergePartCmd{partId: 2, duplicateId: 9}},
+		{"plan 1:3 2", PlanCmd{builds: []core.KitBuild{{KitID: 1, Count: 3}, {KitID: 2, Count: 1}}}},
+		{"plan stock 1:2", PlanCmd{builds: []core.KitBuild{{KitID: 1, Count: 2}}, useStock: true}},
+		{"export kit 1 ./kit.json", ExportKitCmd{kitId: 1, path: "./kit.json"}},
+		{"import kit ./ts808.json", ImportKitCmd{path: "./ts808.json"}},
+		{"import bom ./boms/ts808.csv", ImportBOMCmd{path: "./boms/ts808.csv", name: "ts808", mapping: bom.DefaultMapping()}},
+		{"import bom ./ts808.csv Tube Screamer", ImportBOMCmd{path: "./ts808.csv", name: "Tube Screamer", mapping: bom.DefaultMapping()}},
+		{"import bom ./amp.csv value=Part qty=Count kinds=Tube:IC,Knob:Potentiometer Tube Amp", ImportBOMCmd{path: "./amp.csv", name: "Tube Amp", mapping: customMapping}},
+		{"get stock", GetAllStockCmd{}},
+		{"get stock 12", GetStockCmd{partId: 12}},
+		{"get stockhistory 12", GetStockHistoryCmd{partId: 12}},
+		{"stock add 12 50 first order", AdjustStockCmd{partId: 12, kind: core.Received, quantity: 50, note: "first order"}},
+		{"stock use 12 5", AdjustStockCmd{partId: 12, kind: core.Consumed, quantity: 5, note: ""}},
+		{"stock set 12 40", AdjustStockCmd{partId: 12, kind: core.Counted, quantity: 40, note: ""}},
+		{"stock lose 12 1", AdjustStockCmd{partId: 12, kind: core.Lost, quantity: 1, note: ""}},
+		{"stock location 12 drawer A1", SetStockLocationCmd{partId: 12, location: "drawer A1"}},
 		{"get kits", GetKitsCmd{}},
 		{"get kit 1234", GetKitCmd{kitId: 1234}},
-		{"new kit kitName kitSchem kitDiag", NewKitCmd{name: "kitName", schematic: "kitSchem", diagram: "kitDiag"}},
+		{"new kit kitName example.com/schem example.com/diag", NewKitCmd{name: "kitName", schematic: "example.com/schem", diagram: "example.com/diag"}},
 		{"delete kit 123", DeleteKitCmd{kitId: 123}},
 		{"add kitlink 1234 example.com/kitlink", AddKitLinkCmd{kitId: 1234, link: "example.com/kitlink"}},
 		{"remove kitlink 1234 789", RemoveKitLinkCmd{kitId: 1234, linkId: 789}},
 		{"add kitpart 123 789 9", AddKitPartCmd{kitId: 123, partId: 789, quantity: 9}},
 		{"set kitpart 123 789 5", SetKitPartQuantityCmd{kitId: 123, partId: 789, quantity: 5}},
 		{"remove kitpart 123 789", RemoveKitPartCmd{kitId: 123, partId: 789}},
+		{"set designators 123 789 R1, r7 R12", SetKitPartDesignatorsCmd{kitId: 123, partId: 789, designators: []string{"R1", "R7", "R12"}}},
+		{"set part 12 name 4k7", SetPartCmd{partId: 12, patch: core.PartPatch{Name: strPtr("4k7")}}},
+		{"set part 12 kind Capacitor", SetPartCmd{partId: 12, patch: core.PartPatch{Kind: kindPtr(core.Capacitor)}}},
+		{"set kit 3 name Tube Screamer", SetKitCmd{kitId: 3, patch: core.KitPatch{Name: strPtr("Tube Screamer")}}},
+		{"set kit 3 schematic example.com/ts", SetKitCmd{kitId: 3, patch: core.KitPatch{Schematic: strPtr("example.com/ts")}}},
+		{"set kit 3 diagram example.com/ts-diag", SetKitCmd{kitId: 3, patch: core.KitPatch{Diagram: strPtr("example.com/ts-diag")}}},
 	}
 
 	for _, test := range tests {
@@ -50,6 +94,32 @@ func Test_GetCommand_Errors(t *testing.T) {
 		{"get part", CannotParseCommand{}},
 		{"get kit", CannotParseCommand{}},
 		{"get kit abc", &strconv.NumError{}},
+		{"merge part 2", CannotParseCommand{}},
+		{"merge part 2 abc", &strconv.NumError{}},
+		{"set designators 123 789", CannotParseCommand{}},
+		{"set part 12 name", CannotParseCommand{}},
+		{"find parts colour=red", CannotParseCommand{}},
+		{"find parts Capacitor", CannotParseCommand{}},
+		{"find parts used=sometimes", &strconv.NumError{}},
+		{"set part 12 colour red", CannotParseCommand{}},
+		{"set part abc name 4k7", &strconv.NumError{}},
+		{"set kit 3 links example.com", CannotParseCommand{}},
+		{"export kit 1", CannotParseCommand{}},
+		{"import kit", CannotParseCommand{}},
+		{"import bom", CannotParseCommand{}},
+		{"import bom ./amp.csv kinds=Tube", core.ValidationError{}},
+		{"import bom ./amp.csv kinds=Tube:Valve", core.InvalidPartType{}},
+		{"plan stock", CannotParseCommand{}},
+		{"plan 1:x", &strconv.NumError{}},
+		{"stock add 12", CannotParseCommand{}},
+		{"stock add 12 many", &strconv.NumError{}},
+		{"stock borrow 12 1", CannotParseCommand{}},
+		{"new part partType partName", core.InvalidPartType{}},
+		{"new kit kitName kitSchem kitDiag", core.ValidationError{}},
+		{"add partlink 12 not-a-link", core.ValidationError{}},
+		{"add kitlink 12 ftp://example.com", core.ValidationError{}},
+		{"add kitpart 123 789 0", core.ValidationError{}},
+		{"set kitpart 123 789 0", core.ValidationError{}},
 	}
 
 	for _, test := range tests {
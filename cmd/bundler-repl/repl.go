@@ -2,7 +2,10 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
+	"github.com/sombrerosheep/partsbundler/pkg/bom"
 	"github.com/sombrerosheep/partsbundler/pkg/core"
 )
 
@@ -14,13 +17,18 @@ type ReplCmd interface {
 // Part Commands
 
 func printPart(part core.Part) {
-	fmt.Printf("| %3d | %20s | %25s |\n", part.ID, part.Kind, part.Name)
+	fmt.Printf("| %3d | %20s | %25s | %15s |\n", part.ID, part.Kind, part.Name, part.Value)
 }
 
 // GetPartsCmd Repl Command to get all parts
 type GetPartsCmd struct{}
 
 func (cmd GetPartsCmd) Exec(state *ReplState) error {
+	err := state.Sync()
+	if err != nil {
+		return err
+	}
+
 	for _, v := range state.GetParts() {
 		printPart(v)
 	}
@@ -32,6 +40,30 @@ func (cmd GetPartsCmd) String() string {
 	return "GetParts"
 }
 
+// FindPartsCmd Repl Command to find parts matching a query
+type FindPartsCmd struct {
+	query core.PartQuery
+}
+
+func (cmd FindPartsCmd) Exec(state *ReplState) error {
+	parts, err := state.FindParts(cmd.query)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range parts {
+		printPart(v)
+	}
+
+	fmt.Printf("%d part(s) found\n", len(parts))
+
+	return nil
+}
+
+func (cmd FindPartsCmd) String() string {
+	return "FindParts"
+}
+
 // GetPartCmd Repl Command to get a part by its Id
 type GetPartCmd struct {
 	partId int64
@@ -73,6 +105,28 @@ func (cmd NewPartCmd) String() string {
 	return fmt.Sprintf("NewPart: %s (%s)", cmd.name, cmd.kind)
 }
 
+// SetPartCmd changes the name or kind of a part
+type SetPartCmd struct {
+	partId int64
+	patch  core.PartPatch
+}
+
+func (cmd SetPartCmd) Exec(state *ReplState) error {
+	part, err := state.UpdatePart(cmd.partId, cmd.patch)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Updated Part:")
+	printPart(part)
+
+	return nil
+}
+
+func (cmd SetPartCmd) String() string {
+	return fmt.Sprintf("SetPart: %d", cmd.partId)
+}
+
 type DeletePartCmd struct {
 	partId int64
 }
@@ -117,12 +171,56 @@ func (cmd RemovePartLinkCmd) String() string {
 	return fmt.Sprintf("RemovePartLink: %d (%d)", cmd.partId, cmd.linkId)
 }
 
+// GetDuplicatesCmd Repl Command to list parts with equivalent values
+type GetDuplicatesCmd struct{}
+
+func (cmd GetDuplicatesCmd) Exec(state *ReplState) error {
+	dupes, err := state.FindDuplicates()
+	if err != nil {
+		return err
+	}
+
+	for _, group := range dupes {
+		for _, p := range group {
+			printPart(p)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func (cmd GetDuplicatesCmd) String() string {
+	return "GetDuplicates"
+}
+
+// MergePartCmd Repl Command to merge a duplicate part into another
+type MergePartCmd struct {
+	partId      int64
+	duplicateId int64
+}
+
+func (cmd MergePartCmd) Exec(state *ReplState) error {
+	err := state.MergeParts(cmd.partId, cmd.duplicateId)
+
+	return err
+}
+
+func (cmd MergePartCmd) String() string {
+	return fmt.Sprintf("MergePart: %d <- %d", cmd.partId, cmd.duplicateId)
+}
+
 // Kits Commands
 
 // GetKitsCmd Repl Command to get kits
 type GetKitsCmd struct{}
 
 func (cmd GetKitsCmd) Exec(state *ReplState) error {
+	err := state.Sync()
+	if err != nil {
+		return err
+	}
+
 	for _, v := range state.GetKits() {
 		fmt.Printf("| %3d | %15s | %10s | %10s | %3d |\n",
 			v.ID, v.Name, v.Schematic, v.Diagram, len(v.Parts))
@@ -149,6 +247,11 @@ func (cmd GetKitCmd) Exec(state *ReplState) error {
 	fmt.Printf("| %3d | %15s | %10s | %10s | %3d |\n",
 		kit.ID, kit.Name, kit.Schematic, kit.Diagram, len(kit.Parts))
 
+	for _, kp := range kit.Parts {
+		fmt.Printf("| %3d | %20s | %25s | %15s | %4d | %s\n",
+			kp.ID, kp.Kind, kp.Name, kp.Value, kp.Quantity, strings.Join(kp.Designators, ", "))
+	}
+
 	return nil
 }
 
@@ -178,6 +281,114 @@ func (cmd NewKitCmd) String() string {
 	return fmt.Sprintf("NewKit: %s | %s | %s", cmd.name, cmd.schematic, cmd.diagram)
 }
 
+// ExportKitCmd Repl Command to write a kit to a JSON kit file
+type ExportKitCmd struct {
+	kitId int64
+	path  string
+}
+
+func (cmd ExportKitCmd) Exec(state *ReplState) error {
+	spec, err := state.ExportKit(cmd.kitId)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(cmd.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = core.WriteKitSpec(f, spec)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported Kit %d to %s\n", cmd.kitId, cmd.path)
+
+	return nil
+}
+
+func (cmd ExportKitCmd) String() string {
+	return fmt.Sprintf("ExportKit: %d %s", cmd.kitId, cmd.path)
+}
+
+// ImportKitCmd Repl Command to create a kit from a JSON kit file
+type ImportKitCmd struct {
+	path string
+}
+
+func (cmd ImportKitCmd) Exec(state *ReplState) error {
+	f, err := os.Open(cmd.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	spec, err := core.ReadKitSpec(f)
+	if err != nil {
+		return err
+	}
+
+	result, err := state.ImportKit(spec)
+	if err != nil {
+		return err
+	}
+
+	printKitImport(result)
+
+	return nil
+}
+
+func printKitImport(result core.KitImport) {
+	fmt.Printf("Imported Kit %d (%s)\n", result.Kit.ID, result.Kit.Name)
+	fmt.Println("Matched Parts:")
+	for _, p := range result.Matched {
+		printPart(p)
+	}
+	fmt.Println("Created Parts:")
+	for _, p := range result.Created {
+		printPart(p)
+	}
+}
+
+func (cmd ImportKitCmd) String() string {
+	return fmt.Sprintf("ImportKit: %s", cmd.path)
+}
+
+// ImportBOMCmd Repl Command to create a kit from a CSV BOM
+type ImportBOMCmd struct {
+	path    string
+	name    string
+	mapping bom.Mapping
+}
+
+func (cmd ImportBOMCmd) Exec(state *ReplState) error {
+	f, err := os.Open(cmd.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	spec, err := bom.Read(f, cmd.name, cmd.mapping)
+	if err != nil {
+		return err
+	}
+
+	result, err := state.ImportKit(spec)
+	if err != nil {
+		return err
+	}
+
+	printKitImport(result)
+
+	return nil
+}
+
+func (cmd ImportBOMCmd) String() string {
+	return fmt.Sprintf("ImportBOM: %s %s", cmd.path, cmd.name)
+}
+
 // AddKitLinkCmd
 type AddKitLinkCmd struct {
 	kitId int64
@@ -227,6 +438,22 @@ func (cmd AddKitPartCmd) String() string {
 	return fmt.Sprintf("AddKitPart: %d:%d (%d)", cmd.kitId, cmd.partId, cmd.quantity)
 }
 
+// SetKitCmd changes the name, schematic or diagram of a kit
+type SetKitCmd struct {
+	kitId int64
+	patch core.KitPatch
+}
+
+func (cmd SetKitCmd) Exec(state *ReplState) error {
+	_, err := state.UpdateKit(cmd.kitId, cmd.patch)
+
+	return err
+}
+
+func (cmd SetKitCmd) String() string {
+	return fmt.Sprintf("SetKit: %d", cmd.kitId)
+}
+
 // SetKitPartQuantityCmd
 type SetKitPartQuantityCmd struct {
 	kitId    int64
@@ -244,6 +471,21 @@ func (cmd SetKitPartQuantityCmd) String() string {
 	return fmt.Sprintf("SetKitPartQuantity: %d:%d (%d)", cmd.kitId, cmd.partId, cmd.quantity)
 }
 
+// SetKitPartDesignatorsCmd
+type SetKitPartDesignatorsCmd struct {
+	kitId       int64
+	partId      int64
+	designators []string
+}
+
+func (cmd SetKitPartDesignatorsCmd) Exec(state *ReplState) error {
+	return state.SetPartDesignators(cmd.partId, cmd.kitId, cmd.designators)
+}
+
+func (cmd SetKitPartDesignatorsCmd) String() string {
+	return fmt.Sprintf("SetKitPartDesignators: %d:%d (%s)", cmd.kitId, cmd.partId, strings.Join(cmd.designators, ", "))
+}
+
 // RemoveKitPartCmd
 type RemoveKitPartCmd struct {
 	kitId  int64
@@ -275,6 +517,143 @@ func (cmd DeleteKitCmd) String() string {
 	return fmt.Sprintf("DeleteKid: %d", cmd.kitId)
 }
 
+// PlanCmd Repl Command to list the parts needed for a batch of builds
+type PlanCmd struct {
+	builds   []core.KitBuild
+	useStock bool
+}
+
+func (cmd PlanCmd) Exec(state *ReplState) error {
+	plan, err := state.Plan(cmd.builds, cmd.useStock)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range plan.Items {
+		fmt.Printf("| %3d | %20s | %25s | %6d | %6d | %6d |\n",
+			v.Part.ID, v.Part.Kind, v.Part.Name, v.Required, v.OnHand, v.Shortfall)
+	}
+
+	return nil
+}
+
+func (cmd PlanCmd) String() string {
+	return fmt.Sprintf("Plan: %v (stock: %t)", cmd.builds, cmd.useStock)
+}
+
+// Inventory Commands
+
+func printStock(stock core.Stock) {
+	fmt.Printf("| %3d | %6d | %20s |\n", stock.PartID, stock.Quantity, stock.Location)
+}
+
+// GetAllStockCmd Repl Command to get the stock of all tracked parts
+type GetAllStockCmd struct{}
+
+func (cmd GetAllStockCmd) Exec(state *ReplState) error {
+	stock, err := state.GetAllStock()
+	if err != nil {
+		return err
+	}
+
+	for _, v := range stock {
+		printStock(v)
+	}
+
+	return nil
+}
+
+func (cmd GetAllStockCmd) String() string {
+	return "GetAllStock"
+}
+
+// GetStockCmd Repl Command to get the stock of a part
+type GetStockCmd struct {
+	partId int64
+}
+
+func (cmd GetStockCmd) Exec(state *ReplState) error {
+	stock, err := state.GetStock(cmd.partId)
+	if err != nil {
+		return err
+	}
+
+	printStock(stock)
+
+	return nil
+}
+
+func (cmd GetStockCmd) String() string {
+	return fmt.Sprintf("GetStock(%d)", cmd.partId)
+}
+
+// GetStockHistoryCmd Repl Command to get the adjustments made to a part's stock
+type GetStockHistoryCmd struct {
+	partId int64
+}
+
+func (cmd GetStockHistoryCmd) Exec(state *ReplState) error {
+	history, err := state.GetStockHistory(cmd.partId)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range history {
+		fmt.Printf("| %3d | %20s | %10s | %6d | %s\n",
+			v.ID, v.CreatedAt.Format("2006-01-02 15:04:05"), v.Kind, v.Quantity, v.Note)
+	}
+
+	return nil
+}
+
+func (cmd GetStockHistoryCmd) String() string {
+	return fmt.Sprintf("GetStockHistory(%d)", cmd.partId)
+}
+
+// AdjustStockCmd Repl Command to record a change to a part's stock
+type AdjustStockCmd struct {
+	partId   int64
+	kind     core.AdjustmentKind
+	quantity uint64
+	note     string
+}
+
+func (cmd AdjustStockCmd) Exec(state *ReplState) error {
+	stock, err := state.AdjustStock(cmd.partId, cmd.kind, cmd.quantity, cmd.note)
+	if err != nil {
+		return err
+	}
+
+	printStock(stock)
+
+	return nil
+}
+
+func (cmd AdjustStockCmd) String() string {
+	return fmt.Sprintf("AdjustStock: %d %s (%d)", cmd.partId, cmd.kind, cmd.quantity)
+}
+
+// SetStockLocationCmd Repl Command to set where a part is stored
+type SetStockLocationCmd struct {
+	partId   int64
+	location string
+}
+
+func (cmd SetStockLocationCmd) Exec(state *ReplState) error {
+	stock, err := state.SetStockLocation(cmd.partId, cmd.location)
+	if err != nil {
+		return err
+	}
+
+	printStock(stock)
+
+	return nil
+}
+
+func (cmd SetStockLocationCmd) String() string {
+	return fmt.Sprintf("SetStockLocation: %d (%s)", cmd.partId, cmd.location)
+}
+
 // Misc Commands
 
 type PrintUsageCmd struct{}
@@ -285,8 +664,22 @@ func (cmd PrintUsageCmd) Exec(_ *ReplState) error {
 	fmt.Println("\tget kit :kitId:")
 	fmt.Println("\tget parts")
 	fmt.Println("\tget part :partId:")
+	fmt.Println("\tfind parts [kind=:kind:] [text=:text:] [min=:value:] [max=:value:] [used=yes|no] [links=yes|no]")
 	fmt.Println("\tnew part :kind: :name:")
 	fmt.Println("\tdelete part :partId:")
+	fmt.Println("\tset part :partId: name|kind :value:")
+	fmt.Println("\tset kit :kitId: name|schematic|diagram :value:")
+	fmt.Println("\tget duplicates")
+	fmt.Println("\tmerge part :partId: :duplicateId:")
+	fmt.Println("\tset designators :kitId: :partId: :designator:[, ...]")
+	fmt.Println("\texport kit :kitId: :file:")
+	fmt.Println("\timport kit :file:")
+	fmt.Println("\timport bom :file: [ref|value|kind|qty=:column:] [kinds=:alias:::kind:,...] [name]")
+	fmt.Println("\tplan [stock] :kitId:[::count:] ...")
+	fmt.Println("\tget stock [:partId:]")
+	fmt.Println("\tget stockhistory :partId:")
+	fmt.Println("\tstock add|use|set|lose :partId: :quantity: [:note:]")
+	fmt.Println("\tstock location :partId: :location:")
 
 	return nil
 }
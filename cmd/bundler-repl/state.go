@@ -1,25 +1,49 @@
 package main
 
 import (
+	"github.com/sombrerosheep/partsbundler/internal/config"
 	"github.com/sombrerosheep/partsbundler/internal/sqlite"
 
+	"github.com/sombrerosheep/partsbundler/pkg/client"
 	"github.com/sombrerosheep/partsbundler/pkg/core"
 	"github.com/sombrerosheep/partsbundler/pkg/service"
+	"github.com/sombrerosheep/partsbundler/pkg/service/memory"
 )
 
 type ReplState struct {
 	kits    []core.Kit
 	parts   []core.Part
 	bundler *service.BundlerService
+
+	// shared is set when other clients may change the bundler's parts
+	// and kits, as they do through a bundler-server. Parts and kits are
+	// then read from the bundler when they are looked up.
+	shared bool
 }
 
-func (s *ReplState) Init() error {
-	svc, err := sqlite.CreateSqliteService(dbPath)
+// Init opens the bundler-server at cfg.Server when one is set, an empty
+// in-memory service when cfg.Memory is set, and the sqlite database at
+// cfg.DBPath otherwise.
+func (s *ReplState) Init(cfg config.Config) error {
+	var (
+		svc *service.BundlerService
+		err error
+	)
+
+	switch {
+	case cfg.Server != "":
+		svc, err = client.CreateClientService(cfg.Server, nil)
+	case cfg.Memory:
+		svc = memory.CreateMemoryService()
+	default:
+		svc, err = sqlite.CreateSqliteService(cfg.DBPath)
+	}
 	if err != nil {
 		return err
 	}
 
 	s.bundler = svc
+	s.shared = cfg.Server != ""
 
 	if err = s.Refresh(); err != nil {
 		return err
@@ -45,11 +69,34 @@ func (s *ReplState) Refresh() error {
 	return nil
 }
 
+// Sync reloads the parts and kits when they may have been changed by
+// other clients.
+func (s *ReplState) Sync() error {
+	if !s.shared {
+		return nil
+	}
+
+	return s.Refresh()
+}
+
 func (s ReplState) GetParts() []core.Part {
 	return s.parts[:]
 }
 
-func (s ReplState) getPartRef(partId int64) (*core.Part, error) {
+func (s ReplState) FindParts(query core.PartQuery) ([]core.Part, error) {
+	return s.bundler.Parts.Find(query)
+}
+
+func (s *ReplState) getPartRef(partId int64) (*core.Part, error) {
+	if s.shared {
+		part, err := s.bundler.Parts.Get(partId)
+		if err != nil {
+			return &core.Part{}, err
+		}
+
+		s.cachePart(part)
+	}
+
 	for i := range s.parts {
 		if s.parts[i].ID == partId {
 			return &s.parts[i], nil
@@ -59,7 +106,19 @@ func (s ReplState) getPartRef(partId int64) (*core.Part, error) {
 	return &core.Part{}, core.PartNotFound{PartID: partId}
 }
 
-func (s ReplState) GetPart(partId int64) (core.Part, error) {
+// cachePart replaces the state's copy of part, or adds it.
+func (s *ReplState) cachePart(part core.Part) {
+	for i := range s.parts {
+		if s.parts[i].ID == part.ID {
+			s.parts[i] = part
+			return
+		}
+	}
+
+	s.parts = append(s.parts, part)
+}
+
+func (s *ReplState) GetPart(partId int64) (core.Part, error) {
 	p, err := s.getPartRef(partId)
 	if err != nil {
 		return core.Part{}, err
@@ -130,19 +189,26 @@ func (s *ReplState) RemoveLinkFromPart(partId, linkId int64) error {
 	return nil
 }
 
-func (s *ReplState) DeletePart(partId int64) error {
-	part, err := s.getPartRef(partId)
+// UpdatePart applies patch to a part. Kits hold copies of their parts
+// so the state is refreshed afterwards.
+func (s *ReplState) UpdatePart(partId int64, patch core.PartPatch) (core.Part, error) {
+	part, err := s.bundler.Parts.Patch(partId, patch)
 	if err != nil {
-		return err
+		return core.Part{}, err
 	}
 
-	kitIds, err := s.bundler.Kits.GetPartUsage(part.ID)
+	err = s.Refresh()
 	if err != nil {
-		return err
+		return core.Part{}, err
 	}
 
-	if len(kitIds) > 0 {
-		return core.PartInUse{PartID: partId}
+	return part, nil
+}
+
+func (s *ReplState) DeletePart(partId int64) error {
+	_, err := s.getPartRef(partId)
+	if err != nil {
+		return err
 	}
 
 	err = s.bundler.Parts.Delete(partId)
@@ -167,18 +233,32 @@ func (s *ReplState) DeletePart(partId int64) error {
 	return nil
 }
 
+func (s ReplState) FindDuplicates() ([][]core.Part, error) {
+	return s.bundler.Parts.FindDuplicates()
+}
+
+// MergeParts merges duplicateId into partId. Kits using the duplicate
+// are updated by the service so the state is refreshed afterwards.
+func (s *ReplState) MergeParts(partId, duplicateId int64) error {
+	err := s.bundler.Parts.Merge(partId, duplicateId)
+	if err != nil {
+		return err
+	}
+
+	return s.Refresh()
+}
+
 func (s ReplState) GetKits() []core.Kit {
 	return s.kits[:]
 }
 
-func (s ReplState) GetKit(kitId int64) (core.Kit, error) {
-	for i := range s.kits {
-		if s.kits[i].ID == kitId {
-			return s.kits[i], nil
-		}
+func (s *ReplState) GetKit(kitId int64) (core.Kit, error) {
+	kit, err := s.getKitRef(kitId)
+	if err != nil {
+		return core.Kit{}, err
 	}
 
-	return core.Kit{}, core.KitNotFound{KitID: kitId}
+	return *kit, nil
 }
 
 func (s *ReplState) CreateKit(name, schematic, diagram string) (core.Kit, error) {
@@ -192,7 +272,53 @@ func (s *ReplState) CreateKit(name, schematic, diagram string) (core.Kit, error)
 	return kit, nil
 }
 
-func (s ReplState) getKitRef(kitId int64) (*core.Kit, error) {
+func (s *ReplState) UpdateKit(kitId int64, patch core.KitPatch) (core.Kit, error) {
+	kit, err := s.getKitRef(kitId)
+	if err != nil {
+		return core.Kit{}, err
+	}
+
+	updated, err := s.bundler.Kits.Patch(kitId, patch)
+	if err != nil {
+		return core.Kit{}, err
+	}
+
+	*kit = updated
+
+	return updated, nil
+}
+
+// ExportKit describes the kit in the portable form read by ImportKit.
+func (s *ReplState) ExportKit(kitId int64) (core.KitSpec, error) {
+	kit, err := s.GetKit(kitId)
+	if err != nil {
+		return core.KitSpec{}, err
+	}
+
+	return core.NewKitSpec(kit), nil
+}
+
+// ImportKit creates a kit from spec. New parts may be created by the
+// import so the state is refreshed afterwards.
+func (s *ReplState) ImportKit(spec core.KitSpec) (core.KitImport, error) {
+	result, err := s.bundler.Kits.Import(spec)
+	if err != nil {
+		return result, err
+	}
+
+	return result, s.Refresh()
+}
+
+func (s *ReplState) getKitRef(kitId int64) (*core.Kit, error) {
+	if s.shared {
+		kit, err := s.bundler.Kits.Get(kitId)
+		if err != nil {
+			return nil, err
+		}
+
+		s.cacheKit(kit)
+	}
+
 	for i := range s.kits {
 		if s.kits[i].ID == kitId {
 			return &s.kits[i], nil
@@ -202,13 +328,25 @@ func (s ReplState) getKitRef(kitId int64) (*core.Kit, error) {
 	return nil, core.KitNotFound{KitID: kitId}
 }
 
+// cacheKit replaces the state's copy of kit, or adds it.
+func (s *ReplState) cacheKit(kit core.Kit) {
+	for i := range s.kits {
+		if s.kits[i].ID == kit.ID {
+			s.kits[i] = kit
+			return
+		}
+	}
+
+	s.kits = append(s.kits, kit)
+}
+
 func (s *ReplState) AddLinkToKit(kitId int64, link string) (core.Link, error) {
-	newLink, err := s.bundler.Kits.AddLink(kitId, link)
+	ref, err := s.getKitRef(kitId)
 	if err != nil {
 		return core.Link{}, err
 	}
 
-	ref, err := s.getKitRef(kitId)
+	newLink, err := s.bundler.Kits.AddLink(kitId, link)
 	if err != nil {
 		return core.Link{}, err
 	}
@@ -236,8 +374,8 @@ func (s *ReplState) RemoveLinkFromKit(kitId, linkId int64) error {
 		}
 	}
 
-	if linkIndex > 0 {
-		return core.LinkNotFound{LinkID: linkId, OwnerID: kitId}
+	if linkIndex < 0 {
+		return core.KitLinkNotFound{LinkID: linkId, KitID: kitId}
 	}
 
 	kit.Links = append(kit.Links[:linkIndex], kit.Links[linkIndex+1:]...)
@@ -297,6 +435,29 @@ func (s *ReplState) UpdatePartQuantity(partId, kitId int64, quantity uint64) err
 	return nil
 }
 
+// SetPartDesignators sets the reference designators of a part in a kit.
+// The service also updates the quantity so the kit is reloaded.
+func (s *ReplState) SetPartDesignators(partId, kitId int64, designators []string) error {
+	kit, err := s.getKitRef(kitId)
+	if err != nil {
+		return err
+	}
+
+	err = s.bundler.Kits.SetPartDesignators(kitId, partId, designators)
+	if err != nil {
+		return err
+	}
+
+	updated, err := s.bundler.Kits.Get(kitId)
+	if err != nil {
+		return err
+	}
+
+	*kit = updated
+
+	return nil
+}
+
 func (s *ReplState) RemovePartFromKit(partId, kitId int64) error {
 	kit, err := s.getKitRef(kitId)
 	if err != nil {
@@ -330,6 +491,11 @@ func (s *ReplState) RemovePartFromKit(partId, kitId int64) error {
 }
 
 func (s *ReplState) DeleteKit(kitId int64) error {
+	_, err := s.getKitRef(kitId)
+	if err != nil {
+		return err
+	}
+
 	kitIndex := int64(-1)
 	for i := range s.kits {
 		if s.kits[i].ID == kitId {
@@ -341,7 +507,7 @@ func (s *ReplState) DeleteKit(kitId int64) error {
 		return core.KitNotFound{KitID: kitId}
 	}
 
-	err := s.bundler.Kits.Delete(kitId)
+	err = s.bundler.Kits.Delete(kitId)
 	if err != nil {
 		return err
 	}
@@ -350,3 +516,43 @@ func (s *ReplState) DeleteKit(kitId int64) error {
 
 	return nil
 }
+
+// Plan returns the parts needed to build the given kits. When useStock
+// is set the parts currently in the inventory are subtracted.
+func (s ReplState) Plan(builds []core.KitBuild, useStock bool) (core.Plan, error) {
+	var onHand map[int64]uint64
+
+	if useStock {
+		stock, err := s.bundler.Inventory.GetAll()
+		if err != nil {
+			return core.Plan{}, err
+		}
+
+		onHand = make(map[int64]uint64, len(stock))
+		for _, v := range stock {
+			onHand[v.PartID] = v.Quantity
+		}
+	}
+
+	return s.bundler.Kits.Plan(builds, onHand)
+}
+
+func (s ReplState) GetAllStock() ([]core.Stock, error) {
+	return s.bundler.Inventory.GetAll()
+}
+
+func (s ReplState) GetStock(partId int64) (core.Stock, error) {
+	return s.bundler.Inventory.Get(partId)
+}
+
+func (s ReplState) GetStockHistory(partId int64) ([]core.StockAdjustment, error) {
+	return s.bundler.Inventory.GetHistory(partId)
+}
+
+func (s ReplState) AdjustStock(partId int64, kind core.AdjustmentKind, quantity uint64, note string) (core.Stock, error) {
+	return s.bundler.Inventory.Adjust(partId, kind, quantity, note)
+}
+
+func (s ReplState) SetStockLocation(partId int64, location string) (core.Stock, error) {
+	return s.bundler.Inventory.SetLocation(partId, location)
+}
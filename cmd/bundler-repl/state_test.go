@@ -1,13 +1,82 @@
 package main
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"testing"
 
+	"github.com/sombrerosheep/partsbundler/internal/config"
+	"github.com/sombrerosheep/partsbundler/pkg/client"
 	"github.com/sombrerosheep/partsbundler/pkg/core"
+	"github.com/sombrerosheep/partsbundler/pkg/service/memory"
 	"github.com/sombrerosheep/partsbundler/pkg/service/mock"
 	"github.com/stretchr/testify/assert"
 )
 
+func Test_Init(t *testing.T) {
+	t.Run("should open the sqlite database", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.DBPath = filepath.Join(t.TempDir(), "partsbundler.db")
+
+		sut := &ReplState{}
+		err := sut.Init(cfg)
+
+		assert.Nil(t, err)
+		assert.Empty(t, sut.GetParts())
+		assert.Nil(t, sut.bundler.Close())
+	})
+
+	t.Run("should keep everything in memory", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.DBPath = filepath.Join(t.TempDir(), "partsbundler.db")
+		cfg.Memory = true
+
+		sut := &ReplState{}
+		err := sut.Init(cfg)
+
+		assert.Nil(t, err)
+		assert.Empty(t, sut.GetParts())
+		assert.NoFileExists(t, cfg.DBPath)
+	})
+
+	t.Run("should connect to the server", func(t *testing.T) {
+		requests := []string{}
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.URL.Path)
+			w.Header().Set("X-Total-Count", "1")
+			if r.URL.Path == "/parts" {
+				w.Write([]byte(`[{"id": 1, "name": "10k", "kind": "Resistor"}]`))
+			} else {
+				w.Write([]byte(`[{"id": 2, "name": "Fuzz", "schematics": "example.com/fuzz", "parts": []}]`))
+			}
+		}))
+		defer srv.Close()
+
+		cfg := config.Default()
+		cfg.Server = srv.URL
+
+		sut := &ReplState{}
+		err := sut.Init(cfg)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"/kits", "/parts"}, requests)
+		assert.True(t, sut.shared)
+		assert.Equal(t, []core.Part{{ID: 1, Name: "10k", Kind: core.Resistor}}, sut.GetParts())
+		assert.Equal(t, []core.Kit{{ID: 2, Name: "Fuzz", Schematic: "example.com/fuzz", Parts: []core.KitPart{}, Links: []core.Link{}}}, sut.GetKits())
+	})
+
+	t.Run("should return the error of an invalid server", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.Server = "http://"
+
+		sut := &ReplState{}
+		err := sut.Init(cfg)
+
+		assert.Equal(t, client.InvalidServerURL{URL: "http://"}, err)
+	})
+}
+
 func Test_GetParts(t *testing.T) {
 	t.Run("should return parts", func(t *testing.T) {
 
@@ -53,6 +122,27 @@ func Test_GetPart(t *testing.T) {
 	})
 }
 
+func Test_FindParts(t *testing.T) {
+	t.Run("should return matching parts", func(t *testing.T) {
+		sut := &ReplState{bundler: mock.StubBundlerService}
+		sut.Refresh()
+
+		parts, err := sut.FindParts(core.PartQuery{Kind: core.Capacitor})
+
+		assert.Nil(t, err)
+		assert.Equal(t, mock.FakeParts[1:2], parts)
+	})
+
+	t.Run("should return an error for an invalid query", func(t *testing.T) {
+		sut := &ReplState{bundler: mock.StubBundlerService}
+		sut.Refresh()
+
+		_, err := sut.FindParts(core.PartQuery{Max: "1k"})
+
+		assert.IsType(t, core.InvalidPartQuery{}, err)
+	})
+}
+
 func Test_CreatePart(t *testing.T) {
 	t.Run("should create part and add it to the state", func(t *testing.T) {
 		sut := &ReplState{bundler: mock.StubBundlerService}
@@ -204,6 +294,34 @@ func Test_DeletePart(t *testing.T) {
 	})
 }
 
+func Test_MergeParts(t *testing.T) {
+	t.Run("should return CannotMergeParts when merging a part into itself", func(t *testing.T) {
+		sut := &ReplState{bundler: mock.StubBundlerService}
+		sut.Refresh()
+
+		partId := mock.FakeParts[0].ID
+
+		err := sut.MergeParts(partId, partId)
+
+		assert.NotNil(t, err)
+		assert.IsType(t, core.CannotMergeParts{}, err)
+	})
+
+	t.Run("should return PartNotFound when duplicate doesn't exist", func(t *testing.T) {
+		sut := &ReplState{bundler: mock.StubBundlerService}
+		sut.Refresh()
+
+		partId := mock.FakeParts[0].ID
+		duplicateId := int64(9999)
+
+		err := sut.MergeParts(partId, duplicateId)
+
+		assert.NotNil(t, err)
+		assert.IsType(t, core.PartNotFound{}, err)
+		assert.Equal(t, duplicateId, err.(core.PartNotFound).PartID)
+	})
+}
+
 func Test_GetKits(t *testing.T) {
 	t.Run("should return kits", func(t *testing.T) {
 		sut := &ReplState{bundler: mock.StubBundlerService}
@@ -272,6 +390,217 @@ func Test_CreateKit(t *testing.T) {
 	})
 }
 
+func Test_UpdatePart(t *testing.T) {
+	t.Run("should rename part", func(t *testing.T) {
+		sut := &ReplState{bundler: mock.StubBundlerService}
+		sut.Refresh()
+
+		partId := mock.FakeParts[1].ID
+		name := "100pf"
+
+		part, err := sut.UpdatePart(partId, core.PartPatch{Name: &name})
+
+		assert.Nil(t, err)
+		assert.Equal(t, partId, part.ID)
+		assert.Equal(t, name, part.Name)
+		assert.Equal(t, mock.FakeParts[1].Kind, part.Kind)
+	})
+
+	t.Run("should return PartNotFound when part does not exist", func(t *testing.T) {
+		sut := &ReplState{bundler: mock.StubBundlerService}
+		sut.Refresh()
+
+		_, err := sut.UpdatePart(9999, core.PartPatch{})
+
+		assert.IsType(t, core.PartNotFound{}, err)
+	})
+}
+
+func Test_UpdateKit(t *testing.T) {
+	t.Run("should update kit in state", func(t *testing.T) {
+		sut := &ReplState{bundler: mock.StubBundlerService}
+		sut.Refresh()
+
+		kitId := sut.GetKits()[0].ID
+		name := "Renamed"
+
+		kit, err := sut.UpdateKit(kitId, core.KitPatch{Name: &name})
+
+		assert.Nil(t, err)
+		assert.Equal(t, name, kit.Name)
+
+		gotKit, err := sut.GetKit(kitId)
+
+		assert.Nil(t, err)
+		assert.Equal(t, kit, gotKit)
+	})
+
+	t.Run("should return KitNotFound when kit does not exist", func(t *testing.T) {
+		sut := &ReplState{bundler: mock.StubBundlerService}
+		sut.Refresh()
+
+		_, err := sut.UpdateKit(9999, core.KitPatch{})
+
+		assert.IsType(t, core.KitNotFound{}, err)
+	})
+}
+
+func Test_SetPartDesignators(t *testing.T) {
+	t.Run("should set designators and quantity", func(t *testing.T) {
+		sut := &ReplState{bundler: mock.StubBundlerService}
+		sut.Refresh()
+
+		kit := sut.GetKits()[0]
+		partId := kit.Parts[0].ID
+
+		err := sut.SetPartDesignators(partId, kit.ID, []string{"R1", "R2", "R3"})
+
+		assert.Nil(t, err)
+
+		updated, err := sut.GetKit(kit.ID)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"R1", "R2", "R3"}, updated.Parts[0].Designators)
+		assert.Equal(t, uint64(3), updated.Parts[0].Quantity)
+	})
+
+	t.Run("should return PartNotInKit when part is not in kit", func(t *testing.T) {
+		sut := &ReplState{bundler: mock.StubBundlerService}
+		sut.Refresh()
+
+		kit := sut.GetKits()[0]
+
+		err := sut.SetPartDesignators(9999, kit.ID, []string{"R1"})
+
+		assert.IsType(t, core.PartNotInKit{}, err)
+	})
+}
+
+func Test_ExportKit(t *testing.T) {
+	t.Run("should export kit", func(t *testing.T) {
+		sut := &ReplState{bundler: mock.StubBundlerService}
+		sut.Refresh()
+
+		kit := sut.GetKits()[0]
+
+		spec, err := sut.ExportKit(kit.ID)
+
+		assert.Nil(t, err)
+		assert.Equal(t, core.NewKitSpec(kit), spec)
+	})
+
+	t.Run("should return KitNotFound when kit does not exist", func(t *testing.T) {
+		sut := &ReplState{bundler: mock.StubBundlerService}
+		sut.Refresh()
+
+		_, err := sut.ExportKit(9999)
+
+		assert.NotNil(t, err)
+		assert.IsType(t, core.KitNotFound{}, err)
+	})
+}
+
+func Test_ImportKit(t *testing.T) {
+	t.Run("should import kit", func(t *testing.T) {
+		sut := &ReplState{bundler: mock.StubBundlerService}
+		sut.Refresh()
+
+		spec := core.KitSpec{
+			Name: "imported",
+			Parts: []core.KitPartSpec{
+				{Kind: core.Capacitor, Name: "47 pF", Quantity: 2},
+			},
+		}
+
+		result, err := sut.ImportKit(spec)
+
+		assert.Nil(t, err)
+		assert.Equal(t, spec.Name, result.Kit.Name)
+		assert.Equal(t, []core.Part{mock.FakeParts[1]}, result.Matched)
+		assert.Len(t, result.Created, 0)
+	})
+
+	t.Run("should return InvalidKitSpec when name is missing", func(t *testing.T) {
+		sut := &ReplState{bundler: mock.StubBundlerService}
+		sut.Refresh()
+
+		_, err := sut.ImportKit(core.KitSpec{})
+
+		assert.NotNil(t, err)
+		assert.IsType(t, core.InvalidKitSpec{}, err)
+	})
+}
+
+func Test_SharedState(t *testing.T) {
+	t.Run("should see parts and kits changed by other clients", func(t *testing.T) {
+		other := memory.CreateMemoryService()
+		sut := &ReplState{bundler: other, shared: true}
+		sut.Refresh()
+
+		part, _ := other.Parts.New("10k", core.Resistor)
+		kit, _ := other.Kits.New("Fuzz", "", "")
+		other.Kits.AddPart(kit.ID, part.ID, 2)
+
+		gotPart, err := sut.GetPart(part.ID)
+
+		assert.Nil(t, err)
+		assert.Equal(t, part, gotPart)
+
+		gotKit, err := sut.GetKit(kit.ID)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []core.KitPart{{Part: part, Quantity: 2}}, gotKit.Parts)
+
+		link, err := sut.AddLinkToKit(kit.ID, "example.com/fuzz")
+
+		assert.Nil(t, err)
+
+		gotKit, _ = sut.GetKit(kit.ID)
+		assert.Equal(t, []core.Link{link}, gotKit.Links)
+
+		err = sut.DeleteKit(kit.ID)
+
+		assert.Nil(t, err)
+
+		err = sut.DeletePart(part.ID)
+
+		assert.Nil(t, err)
+		assert.Empty(t, sut.GetParts())
+	})
+
+	t.Run("should reload lists on Sync", func(t *testing.T) {
+		other := memory.CreateMemoryService()
+		sut := &ReplState{bundler: other, shared: true}
+		sut.Refresh()
+
+		part, _ := other.Parts.New("10k", core.Resistor)
+		kit, _ := other.Kits.New("Fuzz", "", "")
+
+		err := sut.Sync()
+
+		assert.Nil(t, err)
+		assert.Equal(t, []core.Part{part}, sut.GetParts())
+		assert.Equal(t, []core.Kit{kit}, sut.GetKits())
+	})
+
+	t.Run("should keep the state when not shared", func(t *testing.T) {
+		other := memory.CreateMemoryService()
+		sut := &ReplState{bundler: other}
+		sut.Refresh()
+
+		part, _ := other.Parts.New("10k", core.Resistor)
+
+		err := sut.Sync()
+
+		assert.Nil(t, err)
+		assert.Empty(t, sut.GetParts())
+
+		_, err = sut.GetPart(part.ID)
+
+		assert.Equal(t, core.PartNotFound{PartID: part.ID}, err)
+	})
+}
+
 func Test_AddLinkToKit(t *testing.T) {
 	t.Run("should add link and add it to the kit", func(t *testing.T) {
 		sut := &ReplState{bundler: mock.StubBundlerService}
@@ -534,3 +863,97 @@ func Test_DeleteKit(t *testing.T) {
 		assert.Equal(t, kitId, err.(core.KitNotFound).KitID)
 	})
 }
+
+func Test_Plan(t *testing.T) {
+	t.Run("should return plan without stock", func(t *testing.T) {
+		sut := &ReplState{bundler: mock.StubBundlerService}
+		sut.Refresh()
+
+		kit := mock.FakeKits[0]
+		builds := []core.KitBuild{{KitID: kit.ID, Count: 3}}
+
+		plan, err := sut.Plan(builds, false)
+
+		assert.Nil(t, err)
+		assert.Len(t, plan.Items, len(kit.Parts))
+
+		for _, item := range plan.Items {
+			assert.Equal(t, uint64(0), item.OnHand)
+			assert.Equal(t, item.Required, item.Shortfall)
+		}
+	})
+
+	t.Run("should subtract stock on hand", func(t *testing.T) {
+		sut := &ReplState{bundler: mock.StubBundlerService}
+		sut.Refresh()
+
+		kit := mock.FakeKits[0]
+		builds := []core.KitBuild{{KitID: kit.ID, Count: 3}}
+
+		onHand := map[int64]uint64{}
+		for _, v := range mock.FakeStock {
+			onHand[v.PartID] = v.Quantity
+		}
+
+		plan, err := sut.Plan(builds, true)
+
+		assert.Nil(t, err)
+
+		for _, item := range plan.Items {
+			assert.Equal(t, onHand[item.Part.ID], item.OnHand)
+		}
+	})
+}
+
+func Test_GetStock(t *testing.T) {
+	t.Run("should return stock for part", func(t *testing.T) {
+		sut := &ReplState{bundler: mock.StubBundlerService}
+		sut.Refresh()
+
+		expected := mock.FakeStock[0]
+
+		stock, err := sut.GetStock(expected.PartID)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, stock)
+	})
+
+	t.Run("should return PartNotFound when part doesn't exist", func(t *testing.T) {
+		sut := &ReplState{bundler: mock.StubBundlerService}
+		sut.Refresh()
+
+		partId := int64(9999)
+
+		_, err := sut.GetStock(partId)
+
+		assert.NotNil(t, err)
+		assert.IsType(t, core.PartNotFound{}, err)
+		assert.Equal(t, partId, err.(core.PartNotFound).PartID)
+	})
+}
+
+func Test_AdjustStock(t *testing.T) {
+	t.Run("should return adjusted stock", func(t *testing.T) {
+		sut := &ReplState{bundler: mock.StubBundlerService}
+		sut.Refresh()
+
+		stock := mock.FakeStock[0]
+
+		actual, err := sut.AdjustStock(stock.PartID, core.Consumed, 3, "")
+
+		assert.Nil(t, err)
+		assert.Equal(t, stock.Quantity-3, actual.Quantity)
+	})
+
+	t.Run("should return InsufficientStock when consuming more than available", func(t *testing.T) {
+		sut := &ReplState{bundler: mock.StubBundlerService}
+		sut.Refresh()
+
+		stock := mock.FakeStock[0]
+
+		_, err := sut.AdjustStock(stock.PartID, core.Consumed, stock.Quantity+1, "")
+
+		assert.NotNil(t, err)
+		assert.IsType(t, core.InsufficientStock{}, err)
+	})
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/sombrerosheep/partsbundler/internal/config"
+	"github.com/sombrerosheep/partsbundler/internal/sqlite"
+	"github.com/sombrerosheep/partsbundler/pkg/client"
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+	"github.com/sombrerosheep/partsbundler/pkg/service"
+	"github.com/sombrerosheep/partsbundler/pkg/service/servicetest"
+	"github.com/stretchr/testify/assert"
+)
+
+// createClientServer serves a fresh sqlite database and returns a client
+// service talking to it.
+func createClientServer(t *testing.T) *service.BundlerService {
+	svc, err := sqlite.CreateSqliteService(filepath.Join(t.TempDir(), "partsbundler.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { svc.Close() })
+
+	bundlerService = svc
+
+	srv := httptest.NewServer(NewRouter(config.Default(), endpoints))
+	t.Cleanup(srv.Close)
+
+	remote, err := client.CreateClientService(srv.URL, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return remote
+}
+
+func Test_Client(t *testing.T) {
+	t.Run("should create and read parts and kits", func(t *testing.T) {
+		remote := createClientServer(t)
+
+		part, err := remote.Parts.New("10k", core.Resistor)
+		assert.Nil(t, err)
+		assert.Equal(t, "10k", part.Name)
+
+		kit, err := remote.Kits.New("Fuzz", "example.com/fuzz", "")
+		assert.Nil(t, err)
+
+		err = remote.Kits.AddPart(kit.ID, part.ID, 3)
+		assert.Nil(t, err)
+
+		kit, err = remote.Kits.Get(kit.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, []core.KitPart{{Part: part, Quantity: 3}}, kit.Parts)
+
+		usage, err := remote.Kits.GetPartUsage(part.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, []int64{kit.ID}, usage)
+
+		parts, err := remote.Parts.Find(core.PartQuery{Kind: core.Resistor})
+		assert.Nil(t, err)
+		assert.Equal(t, []core.Part{part}, parts)
+
+		page, err := remote.Kits.List(core.PageRequest{Limit: 10})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, page.Total)
+		assert.Equal(t, kit.ID, page.Kits[0].ID)
+	})
+
+	t.Run("should return core errors", func(t *testing.T) {
+		remote := createClientServer(t)
+
+		_, err := remote.Parts.Get(42)
+		assert.Equal(t, core.PartNotFound{PartID: 42}, err)
+
+		_, err = remote.Kits.Get(42)
+		assert.Equal(t, core.KitNotFound{KitID: 42}, err)
+
+		_, err = remote.Parts.New("", core.Resistor)
+		assert.IsType(t, core.ValidationError{}, err)
+
+		part, err := remote.Parts.New("TL072", core.IC)
+		assert.Nil(t, err)
+
+		kit, err := remote.Kits.New("Boost", "example.com/boost", "")
+		assert.Nil(t, err)
+
+		err = remote.Kits.AddPart(kit.ID, part.ID, 1)
+		assert.Nil(t, err)
+
+		err = remote.Parts.Delete(part.ID)
+		assert.Equal(t, core.PartInUse{PartID: part.ID}, err)
+
+		err = remote.Kits.RemovePart(kit.ID, part.ID)
+		assert.Nil(t, err)
+
+		err = remote.Parts.Delete(part.ID)
+		assert.Nil(t, err)
+
+		_, err = remote.Parts.Get(part.ID)
+		assert.Equal(t, core.PartNotFound{PartID: part.ID}, err)
+	})
+
+	t.Run("should ping the server", func(t *testing.T) {
+		remote := createClientServer(t)
+
+		assert.Nil(t, remote.Ping(context.Background()))
+	})
+}
+
+func Test_ClientConformance(t *testing.T) {
+	servicetest.Run(t, createClientServer)
+}
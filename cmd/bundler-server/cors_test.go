@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sombrerosheep/partsbundler/internal/config"
+	"github.com/sombrerosheep/partsbundler/pkg/service/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createCORSServer(origins ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.Default()
+	cfg.CORSOrigins = origins
+
+	return NewRouter(cfg, endpoints)
+}
+
+func Test_CORS(t *testing.T) {
+	t.Run("should allow a configured origin", func(t *testing.T) {
+		router := createCORSServer("https://parts.example.com")
+		bundlerService = mock.StubBundlerService
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/parts", nil)
+		assert.Nil(t, err)
+		req.Header.Set("Origin", "https://parts.example.com")
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "https://parts.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "X-Total-Count", w.Header().Get("Access-Control-Expose-Headers"))
+	})
+
+	t.Run("should not allow other origins", func(t *testing.T) {
+		router := createCORSServer("https://parts.example.com")
+		bundlerService = mock.StubBundlerService
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/parts", nil)
+		assert.Nil(t, err)
+		req.Header.Set("Origin", "https://evil.example.com")
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("should answer preflight requests", func(t *testing.T) {
+		router := createCORSServer("*")
+		bundlerService = mock.StubBundlerService
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodOptions, "/kits/1", nil)
+		assert.Nil(t, err)
+		req.Header.Set("Origin", "http://localhost:8080")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPatch)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "http://localhost:8080", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), http.MethodPatch)
+	})
+
+	t.Run("should not send CORS headers when no origins are configured", func(t *testing.T) {
+		router := createCORSServer()
+		bundlerService = mock.StubBundlerService
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/parts", nil)
+		assert.Nil(t, err)
+		req.Header.Set("Origin", "http://localhost:8080")
+
+		router.ServeHTTP(w, req)
+
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
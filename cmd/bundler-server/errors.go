@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sombrerosheep/partsbundler/internal/config"
+	"github.com/sombrerosheep/partsbundler/pkg/bom"
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+)
+
+// RouteNotFound is reported for requests to a path no route serves.
+type RouteNotFound struct {
+	Path string
+}
+
+func (e RouteNotFound) Error() string {
+	return fmt.Sprintf("No route for '%s'", e.Path)
+}
+
+// MethodNotAllowed is reported for requests to a path served by routes
+// for other methods.
+type MethodNotAllowed struct {
+	Method, Path string
+}
+
+func (e MethodNotAllowed) Error() string {
+	return fmt.Sprintf("Method %s is not allowed for '%s'", e.Method, e.Path)
+}
+
+// int64Param parses the path parameter name as an id.
+func int64Param(c *gin.Context, name string) (int64, error) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil {
+		return 0, core.InvalidField(name, fmt.Sprintf("'%s' is not an integer", c.Param(name)))
+	}
+
+	return id, nil
+}
+
+// bindJSON decodes the request body into obj.
+func bindJSON(c *gin.Context, obj interface{}) error {
+	err := c.ShouldBindJSON(obj)
+	if err == nil {
+		return nil
+	}
+
+	if typeErr, ok := err.(*json.UnmarshalTypeError); ok {
+		return core.InvalidField(typeErr.Field, fmt.Sprintf("expected %s but got %s", typeErr.Type, typeErr.Value))
+	}
+
+	return core.InvalidField("body", err.Error())
+}
+
+// describeError maps an error to the status and APIError it is reported
+// with. Errors it does not know are internal server errors.
+func describeError(err error) (int, core.APIError) {
+	apiErr := core.APIError{Message: err.Error()}
+	status := http.StatusInternalServerError
+
+	switch e := err.(type) {
+	case core.PartNotFound:
+		status, apiErr.Code = http.StatusNotFound, "part_not_found"
+		apiErr.Details = map[string]interface{}{"partId": e.PartID}
+	case core.KitNotFound:
+		status, apiErr.Code = http.StatusNotFound, "kit_not_found"
+		apiErr.Details = map[string]interface{}{"kitId": e.KitID}
+	case core.LinkNotFound:
+		status, apiErr.Code = http.StatusNotFound, "link_not_found"
+		apiErr.Details = map[string]interface{}{"linkId": e.LinkID, "ownerId": e.OwnerID}
+	case core.KitLinkNotFound:
+		status, apiErr.Code = http.StatusNotFound, "kit_link_not_found"
+		apiErr.Details = map[string]interface{}{"linkId": e.LinkID, "kitId": e.KitID}
+	case core.PartNotInKit:
+		status, apiErr.Code = http.StatusNotFound, "part_not_in_kit"
+		apiErr.Details = map[string]interface{}{"kitId": e.KitID, "partId": e.PartID}
+	case core.PartInUse:
+		status, apiErr.Code = http.StatusConflict, "part_in_use"
+		apiErr.Details = map[string]interface{}{"partId": e.PartID}
+	case core.PartAlreadyInKit:
+		status, apiErr.Code = http.StatusConflict, "part_already_in_kit"
+		apiErr.Details = map[string]interface{}{"kitId": e.KitID, "partId": e.PartID}
+	case core.DesignatorInUse:
+		status, apiErr.Code = http.StatusConflict, "designator_in_use"
+		apiErr.Details = map[string]interface{}{"kitId": e.KitID, "designator": e.Designator}
+	case core.DesignatorMismatch:
+		status, apiErr.Code = http.StatusConflict, "designator_mismatch"
+		apiErr.Details = map[string]interface{}{
+			"kitId":       e.KitID,
+			"partId":      e.PartID,
+			"designators": e.Designators,
+			"quantity":    e.Quantity,
+		}
+	case core.InsufficientStock:
+		status, apiErr.Code = http.StatusConflict, "insufficient_stock"
+		apiErr.Details = map[string]interface{}{
+			"partId":    e.PartID,
+			"available": e.Available,
+			"requested": e.Requested,
+		}
+	case core.CannotMergeParts:
+		status, apiErr.Code = http.StatusBadRequest, "cannot_merge_parts"
+		apiErr.Details = map[string]interface{}{"partId": e.PartID, "duplicateId": e.DuplicateID}
+	case core.InvalidPartType:
+		status, apiErr.Code = http.StatusBadRequest, "invalid_part_type"
+		apiErr.Details = map[string]interface{}{"kind": e.InvalidType}
+		apiErr.Fields = []core.FieldError{{Field: "kind", Message: e.Error()}}
+	case core.InvalidAdjustmentKind:
+		status, apiErr.Code = http.StatusBadRequest, "invalid_adjustment_kind"
+		apiErr.Details = map[string]interface{}{"kind": e.InvalidKind}
+		apiErr.Fields = []core.FieldError{{Field: "kind", Message: e.Error()}}
+	case core.InvalidValue:
+		status, apiErr.Code = http.StatusBadRequest, "invalid_value"
+		apiErr.Details = map[string]interface{}{"kind": e.Kind, "value": e.Value}
+	case core.InvalidPartQuery:
+		status, apiErr.Code = http.StatusBadRequest, "invalid_part_query"
+		apiErr.Details = map[string]interface{}{"reason": e.Reason}
+	case core.InvalidPageRequest:
+		status, apiErr.Code = http.StatusBadRequest, "invalid_page_request"
+		apiErr.Details = map[string]interface{}{"reason": e.Reason}
+	case core.InvalidKitSpec:
+		status, apiErr.Code = http.StatusBadRequest, "invalid_kit_spec"
+		apiErr.Details = map[string]interface{}{"reason": e.Reason}
+	case bom.InvalidBOM:
+		status, apiErr.Code = http.StatusBadRequest, "invalid_bom"
+		for _, l := range e.Lines {
+			apiErr.Fields = append(apiErr.Fields, core.FieldError{
+				Field:   fmt.Sprintf("line %d", l.Line),
+				Message: l.Reason,
+			})
+		}
+	case *csv.ParseError:
+		status, apiErr.Code = http.StatusBadRequest, "invalid_bom"
+	case core.ValidationError:
+		status, apiErr.Code = http.StatusBadRequest, "invalid_request"
+		apiErr.Fields = e.Fields
+	case RouteNotFound:
+		status, apiErr.Code = http.StatusNotFound, "route_not_found"
+		apiErr.Details = map[string]interface{}{"path": e.Path}
+	case MethodNotAllowed:
+		status, apiErr.Code = http.StatusMethodNotAllowed, "method_not_allowed"
+		apiErr.Details = map[string]interface{}{"method": e.Method, "path": e.Path}
+	default:
+		apiErr.Code = "internal_error"
+	}
+
+	return status, apiErr
+}
+
+// ErrorHandler responds with the last error a handler recorded with
+// c.Error, so every route reports errors as a core.ErrorResponse with
+// the status chosen by describeError. Internal errors are logged at
+// error level and the others at warn level.
+func ErrorHandler(cfg config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		status, apiErr := describeError(c.Errors.Last().Err)
+		c.JSON(status, core.ErrorResponse{Error: apiErr})
+
+		level := config.LogWarn
+		if status >= http.StatusInternalServerError {
+			level = config.LogError
+		}
+
+		logf(cfg, level, "%d | %-7s %#v | %s", status, c.Request.Method, c.Request.URL.Path, c.Errors.Last().Err)
+	}
+}
+
+// NoRoute reports a request no route matches as RouteNotFound.
+func NoRoute(c *gin.Context) {
+	c.Error(RouteNotFound{Path: c.Request.URL.Path})
+}
+
+// NoMethod reports a request whose path is only served for other
+// methods as MethodNotAllowed.
+func NoMethod(c *gin.Context) {
+	c.Error(MethodNotAllowed{Method: c.Request.Method, Path: c.Request.URL.Path})
+}
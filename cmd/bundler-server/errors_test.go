@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sombrerosheep/partsbundler/pkg/bom"
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_describeError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{core.PartNotFound{PartID: 3}, http.StatusNotFound, "part_not_found"},
+		{core.KitNotFound{KitID: 3}, http.StatusNotFound, "kit_not_found"},
+		{core.LinkNotFound{LinkID: 1, OwnerID: 3}, http.StatusNotFound, "link_not_found"},
+		{core.KitLinkNotFound{LinkID: 1, KitID: 3}, http.StatusNotFound, "kit_link_not_found"},
+		{core.PartNotInKit{KitID: 1, PartID: 3}, http.StatusNotFound, "part_not_in_kit"},
+		{core.PartInUse{PartID: 3}, http.StatusConflict, "part_in_use"},
+		{core.PartAlreadyInKit{KitID: 1, PartID: 3}, http.StatusConflict, "part_already_in_kit"},
+		{core.DesignatorInUse{KitID: 1, Designator: "R1"}, http.StatusConflict, "designator_in_use"},
+		{core.DesignatorMismatch{KitID: 1, PartID: 3, Designators: 2, Quantity: 1}, http.StatusConflict, "designator_mismatch"},
+		{core.InsufficientStock{PartID: 3, Available: 1, Requested: 2}, http.StatusConflict, "insufficient_stock"},
+		{core.CannotMergeParts{PartID: 1, DuplicateID: 3}, http.StatusBadRequest, "cannot_merge_parts"},
+		{core.InvalidPartType{InvalidType: "Flux"}, http.StatusBadRequest, "invalid_part_type"},
+		{core.InvalidAdjustmentKind{InvalidKind: "stolen"}, http.StatusBadRequest, "invalid_adjustment_kind"},
+		{core.InvalidValue{Kind: core.Resistor, Value: "lots"}, http.StatusBadRequest, "invalid_value"},
+		{core.InvalidPartQuery{Reason: "no"}, http.StatusBadRequest, "invalid_part_query"},
+		{core.InvalidPageRequest{Reason: "no"}, http.StatusBadRequest, "invalid_page_request"},
+		{core.InvalidKitSpec{Reason: "no"}, http.StatusBadRequest, "invalid_kit_spec"},
+		{bom.InvalidBOM{Lines: []bom.InvalidLine{{Line: 2, Reason: "no"}}}, http.StatusBadRequest, "invalid_bom"},
+		{core.InvalidField("partId", "no"), http.StatusBadRequest, "invalid_request"},
+		{RouteNotFound{Path: "/nope"}, http.StatusNotFound, "route_not_found"},
+		{MethodNotAllowed{Method: http.MethodPut, Path: "/parts"}, http.StatusMethodNotAllowed, "method_not_allowed"},
+		{context.Canceled, http.StatusInternalServerError, "internal_error"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.code, func(t *testing.T) {
+			status, apiErr := describeError(test.err)
+
+			assert.Equal(t, test.status, status)
+			assert.Equal(t, test.code, apiErr.Code)
+			assert.Equal(t, test.err.Error(), apiErr.Message)
+		})
+	}
+
+	t.Run("should list the invalid lines of a BOM as fields", func(t *testing.T) {
+		err := bom.InvalidBOM{Lines: []bom.InvalidLine{
+			{Line: 2, Reason: "missing value"},
+			{Line: 5, Reason: "unknown kind 'xyz'"},
+		}}
+
+		_, apiErr := describeError(err)
+
+		assert.Equal(t, []core.FieldError{
+			{Field: "line 2", Message: "missing value"},
+			{Field: "line 5", Message: "unknown kind 'xyz'"},
+		}, apiErr.Fields)
+	})
+}
+
+func Test_NoRoute(t *testing.T) {
+	t.Run("should report unknown routes and methods as JSON", func(t *testing.T) {
+		tests := []struct {
+			method string
+			path   string
+			status int
+			err    error
+		}{
+			{http.MethodGet, "/widgets", http.StatusNotFound, RouteNotFound{Path: "/widgets"}},
+			{http.MethodPut, "/parts", http.StatusMethodNotAllowed, MethodNotAllowed{Method: http.MethodPut, Path: "/parts"}},
+		}
+
+		for _, test := range tests {
+			router := CreateStubServer()
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(test.method, test.path, nil)
+
+			assert.Nil(t, err)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.status, w.Code)
+			assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+			assert.Equal(t, test.err.Error(), errorResponse(t, w).Message)
+		}
+	})
+}
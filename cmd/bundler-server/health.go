@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+)
+
+// readyTimeout bounds how long Readyz waits for the storage to answer.
+const readyTimeout = 2 * time.Second
+
+type HealthStatus struct {
+	Status string `json:"status"`
+}
+
+// Healthz reports that the server is up and handling requests.
+func Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthStatus{Status: "ok"})
+}
+
+// Readyz reports whether the server can serve requests by pinging the
+// storage behind the bundler service.
+func Readyz(c *gin.Context) {
+	svc := GetBundlerService()
+	if svc == nil {
+		c.JSON(http.StatusServiceUnavailable, core.ErrorResponse{Error: core.APIError{
+			Code:    "not_ready",
+			Message: "bundler service is not initialized",
+		}})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readyTimeout)
+	defer cancel()
+
+	if err := svc.Ping(ctx); err != nil {
+		c.JSON(http.StatusServiceUnavailable, core.ErrorResponse{Error: core.APIError{
+			Code:    "not_ready",
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	c.JSON(http.StatusOK, HealthStatus{Status: "ready"})
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sombrerosheep/partsbundler/pkg/service"
+	"github.com/sombrerosheep/partsbundler/pkg/service/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeStorage records Close and fails Ping with pingErr.
+type fakeStorage struct {
+	pingErr error
+	closed  bool
+}
+
+func (s *fakeStorage) Ping(ctx context.Context) error {
+	return s.pingErr
+}
+
+func (s *fakeStorage) Close() error {
+	s.closed = true
+
+	return nil
+}
+
+func withStorage(storage service.IStorage) *service.BundlerService {
+	svc := *mock.StubBundlerService
+	svc.Storage = storage
+
+	return &svc
+}
+
+func Test_Healthz(t *testing.T) {
+	router := CreateStubServer()
+	bundlerService = withStorage(&fakeStorage{pingErr: errors.New("disk I/O error")})
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, "/healthz", nil)
+	assert.Nil(t, err)
+
+	router.ServeHTTP(w, req)
+
+	var status HealthStatus
+	err = json.Unmarshal(w.Body.Bytes(), &status)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "ok", status.Status)
+}
+
+func Test_Readyz(t *testing.T) {
+	t.Run("should be ready when storage answers", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = withStorage(&fakeStorage{})
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		var status HealthStatus
+		err = json.Unmarshal(w.Body.Bytes(), &status)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "ready", status.Status)
+	})
+
+	t.Run("should be unavailable when storage fails", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = withStorage(&fakeStorage{pingErr: errors.New("disk I/O error")})
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		apiErr := errorResponse(t, w)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "not_ready", apiErr.Code)
+		assert.Equal(t, "disk I/O error", apiErr.Message)
+	})
+
+	t.Run("should be unavailable without a service", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = nil
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "not_ready", errorResponse(t, w).Code)
+	})
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sombrerosheep/partsbundler/internal/config"
+)
+
+const logTimeFormat = "2006/01/02 - 15:04:05"
+
+// logf writes a line to gin.DefaultWriter when cfg logs at level.
+func logf(cfg config.Config, level string, format string, args ...interface{}) {
+	if !cfg.LogEnabled(level) {
+		return
+	}
+
+	fmt.Fprintf(gin.DefaultWriter, "[%s] %s | %s\n", strings.ToUpper(level), time.Now().Format(logTimeFormat), fmt.Sprintf(format, args...))
+}
+
+// RequestLogger logs a line for every request when cfg logs at info
+// level. At debug level the line also carries the size of the request
+// and response and the client's user agent. Failed requests are logged
+// by ErrorHandler.
+func RequestLogger(cfg config.Config) gin.HandlerFunc {
+	debug := cfg.LogEnabled(config.LogDebug)
+
+	level := config.LogInfo
+	if debug {
+		level = config.LogDebug
+	}
+
+	return gin.LoggerWithFormatter(func(p gin.LogFormatterParams) string {
+		line := fmt.Sprintf("[%s] %s | %3d | %13v | %15s | %-7s %#v",
+			strings.ToUpper(level), p.TimeStamp.Format(logTimeFormat), p.StatusCode, p.Latency, p.ClientIP, p.Method, p.Path)
+
+		if debug {
+			line += fmt.Sprintf(" | in %d bytes | out %d bytes | %#v",
+				p.Request.ContentLength, p.BodySize, p.Request.UserAgent())
+		}
+
+		return line + "\n"
+	})
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sombrerosheep/partsbundler/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// logAtLevel serves a request which succeeds, one which fails with a
+// client error and one which fails with an internal error, returning
+// what was logged at level.
+func logAtLevel(t *testing.T, level string) string {
+	gin.SetMode(gin.TestMode)
+
+	out := &bytes.Buffer{}
+	defaultWriter := gin.DefaultWriter
+	gin.DefaultWriter = out
+	defer func() { gin.DefaultWriter = defaultWriter }()
+
+	cfg := config.Default()
+	cfg.LogLevel = level
+
+	router := NewRouter(cfg, []Endpoint{
+		{path: "/ok", method: http.MethodGet, handler: func(c *gin.Context) { c.Status(http.StatusNoContent) }},
+		{path: "/bad", method: http.MethodGet, handler: func(c *gin.Context) { c.Error(RouteNotFound{Path: "/bad"}) }},
+		{path: "/boom", method: http.MethodGet, handler: func(c *gin.Context) { c.Error(errors.New("boom")) }},
+	})
+
+	for _, path := range []string{"/ok?detail=yes", "/bad", "/boom"} {
+		req, err := http.NewRequest(http.MethodGet, path, nil)
+		assert.Nil(t, err)
+		req.Header.Set("User-Agent", "logtest")
+
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	return out.String()
+}
+
+func Test_Logging(t *testing.T) {
+	t.Run("should log request detail at debug level", func(t *testing.T) {
+		logged := logAtLevel(t, config.LogDebug)
+
+		assert.Contains(t, logged, `[DEBUG]`)
+		assert.Contains(t, logged, `"/ok?detail=yes"`)
+		assert.Contains(t, logged, `"logtest"`)
+		assert.Contains(t, logged, `[WARN]`)
+		assert.Contains(t, logged, `[ERROR]`)
+	})
+
+	t.Run("should log requests and errors at info level", func(t *testing.T) {
+		logged := logAtLevel(t, config.LogInfo)
+
+		assert.Contains(t, logged, `[INFO]`)
+		assert.Contains(t, logged, `"/ok?detail=yes"`)
+		assert.NotContains(t, logged, `"logtest"`)
+		assert.Contains(t, logged, `[WARN]`)
+		assert.Contains(t, logged, `[ERROR]`)
+	})
+
+	t.Run("should log only failures at warn level", func(t *testing.T) {
+		logged := logAtLevel(t, config.LogWarn)
+
+		assert.NotContains(t, logged, `[INFO]`)
+		assert.Contains(t, logged, `No route for '/bad'`)
+		assert.Contains(t, logged, `boom`)
+	})
+
+	t.Run("should log only internal errors at error level", func(t *testing.T) {
+		logged := logAtLevel(t, config.LogError)
+
+		assert.NotContains(t, logged, `[WARN]`)
+		assert.Contains(t, logged, "[ERROR]")
+		assert.Contains(t, logged, `500 | GET     "/boom" | boom`)
+	})
+}
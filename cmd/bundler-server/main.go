@@ -1,20 +1,36 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sombrerosheep/partsbundler/internal/config"
 	"github.com/sombrerosheep/partsbundler/internal/sqlite"
 	"github.com/sombrerosheep/partsbundler/pkg/service"
+	"github.com/sombrerosheep/partsbundler/pkg/service/memory"
 )
 
-const bundlerDBPath string = "../../data/partsbundler.db"
-
 var bundlerService *service.BundlerService = nil
 
-func InitBundlerService(dbPath string) error {
-	svc, err := sqlite.CreateSqliteService(dbPath)
+// InitBundlerService keeps the service in memory when cfg.Memory is set
+// and opens the sqlite database at cfg.DBPath otherwise.
+func InitBundlerService(cfg config.Config) error {
+	if cfg.Memory {
+		bundlerService = memory.CreateMemoryService()
+
+		return nil
+	}
+
+	svc, err := sqlite.CreateSqliteService(cfg.DBPath)
 	if err != nil {
 		return err
 	}
@@ -39,26 +55,116 @@ func RegisterEndpoints(router *gin.Engine, endpoints []Endpoint) {
 			router.DELETE(v.path, v.handler)
 		case http.MethodPut:
 			router.PUT(v.path, v.handler)
+		case http.MethodPatch:
+			router.PATCH(v.path, v.handler)
 		default:
 			fmt.Printf("Unsupported method '%s' for endpoint %#v", v.method, v)
 		}
 	}
 }
 
+// shutdownTimeout bounds how long Serve waits for in-flight requests
+// once asked to stop.
+const shutdownTimeout = 10 * time.Second
+
+// Serve serves srv on ln until ctx is done, then stops accepting
+// connections, waits up to shutdownTimeout for in-flight requests to
+// finish and closes svc.
+func Serve(ctx context.Context, srv *http.Server, ln net.Listener, svc *service.BundlerService) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ln)
+	}()
+
+	var err error
+
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		err = srv.Shutdown(shutdownCtx)
+		if serr := <-serveErr; err == nil && serr != http.ErrServerClosed {
+			err = serr
+		}
+	}
+
+	if cerr := svc.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+// NewRouter returns a router serving endpoints and their OpenAPI
+// document which reports errors, including those for unknown routes
+// and methods, with ErrorHandler. Requests and errors are logged at the
+// level set in cfg.
+func NewRouter(cfg config.Config, endpoints []Endpoint) *gin.Engine {
+	router := gin.New()
+
+	if cfg.LogEnabled(config.LogInfo) {
+		router.Use(RequestLogger(cfg))
+	}
+
+	router.Use(gin.Recovery())
+
+	if len(cfg.CORSOrigins) > 0 {
+		router.Use(CORS(cfg.CORSOrigins))
+	}
+
+	router.Use(ErrorHandler(cfg))
+
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(NoRoute)
+	router.NoMethod(NoMethod)
+
+	RegisterEndpoints(router, endpoints)
+	router.GET("/openapi.json", OpenAPI(endpoints))
+
+	return router
+}
+
 func main() {
+	cfg, err := config.Load("bundler-server", os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error loading config: %s\n", err)
+		os.Exit(2)
+	}
+
+	gin.SetMode(cfg.GinMode)
+
 	fmt.Println("Hello")
 
-	err := InitBundlerService(bundlerDBPath)
+	err = InitBundlerService(cfg)
 	if err != nil {
 		fmt.Printf("Error iniializing service: %s\n", err)
 		return
 	}
 
-	router := gin.Default()
-	RegisterEndpoints(router, endpoints)
+	ln, err := net.Listen("tcp", cfg.Listen)
+	if err != nil {
+		fmt.Printf("Error listening on %s: %s\n", cfg.Listen, err)
+		bundlerService.Close()
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{Handler: NewRouter(cfg, endpoints)}
 
-	err = router.Run(":3000")
+	fmt.Printf("Listening on %s\n", ln.Addr())
+
+	err = Serve(ctx, srv, ln, bundlerService)
 	if err != nil {
-		fmt.Printf("Server exited with error: %s", err)
+		fmt.Printf("Server exited with error: %s\n", err)
+		os.Exit(1)
 	}
+
+	fmt.Println("byebye.")
 }
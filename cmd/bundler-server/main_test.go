@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Serve(t *testing.T) {
+	t.Run("should drain in-flight requests and close storage", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		started := make(chan struct{})
+		mux := http.NewServeMux()
+		mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			time.Sleep(100 * time.Millisecond)
+			fmt.Fprint(w, "done")
+		})
+
+		storage := &fakeStorage{}
+		ctx, cancel := context.WithCancel(context.Background())
+
+		served := make(chan error, 1)
+		go func() {
+			served <- Serve(ctx, &http.Server{Handler: mux}, ln, withStorage(storage))
+		}()
+
+		type result struct {
+			body string
+			err  error
+		}
+		response := make(chan result, 1)
+		go func() {
+			res, err := http.Get(fmt.Sprintf("http://%s/slow", ln.Addr()))
+			if err != nil {
+				response <- result{err: err}
+				return
+			}
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			response <- result{string(body), err}
+		}()
+
+		<-started
+		cancel()
+
+		r := <-response
+		assert.Nil(t, r.err)
+		assert.Equal(t, "done", r.body)
+
+		assert.Nil(t, <-served)
+		assert.True(t, storage.closed)
+
+		_, err = http.Get(fmt.Sprintf("http://%s/slow", ln.Addr()))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should close storage when serving fails", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ln.Close()
+
+		storage := &fakeStorage{}
+
+		err = Serve(context.Background(), &http.Server{}, ln, withStorage(storage))
+
+		assert.NotNil(t, err)
+		assert.True(t, storage.closed)
+	})
+}
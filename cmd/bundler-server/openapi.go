@@ -0,0 +1,301 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+)
+
+const openAPIVersion = "3.0.3"
+
+// schemaRef is the $ref prefix of the schemas in an OpenAPI document.
+const schemaRef = "#/components/schemas/"
+
+// enums lists the values of string types that only accept a few.
+var enums = map[reflect.Type][]string{
+	reflect.TypeOf(core.PartType("")): {
+		string(core.Resistor), core.Capacitor, core.IC, core.Transistor,
+		core.Diode, core.Potentiometer, core.Switch,
+	},
+	reflect.TypeOf(core.AdjustmentKind("")): {
+		string(core.Received), core.Consumed, core.Counted, core.Lost,
+	},
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// OpenAPIDocument describes endpoints as an OpenAPI 3 document. Schemas
+// are built from the JSON encoding of the request and response types.
+// Fields without omitempty are required, and fields named id are read
+// only, so request bodies may leave them out.
+func OpenAPIDocument(endpoints []Endpoint) map[string]interface{} {
+	schemas := map[string]interface{}{}
+	paths := map[string]interface{}{}
+
+	for _, e := range endpoints {
+		path, params := openAPIPath(e.path)
+
+		item, ok := paths[path].(map[string]interface{})
+		if !ok {
+			item = map[string]interface{}{}
+			paths[path] = item
+		}
+
+		query := e.query
+		if e.paged {
+			query = append(append([]QueryParam{}, query...), pageParams...)
+		}
+
+		for _, q := range query {
+			params = append(params, map[string]interface{}{
+				"name":        q.name,
+				"in":          "query",
+				"description": q.description,
+				"schema":      map[string]interface{}{"type": q.kind},
+			})
+		}
+
+		op := map[string]interface{}{
+			"operationId": handlerName(e.handler),
+			"summary":     e.summary,
+			"tags":        []string{strings.SplitN(strings.TrimPrefix(e.path, "/"), "/", 2)[0]},
+			"responses":   openAPIResponses(e, schemas),
+		}
+
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+
+		switch e.request.(type) {
+		case nil:
+		case csvBody:
+			op["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"text/csv": map[string]interface{}{
+						"schema": map[string]interface{}{"type": "string"},
+					},
+				},
+			}
+		default:
+			op["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content":  jsonContent(schemaFor(reflect.TypeOf(e.request), schemas)),
+			}
+		}
+
+		item[strings.ToLower(e.method)] = op
+	}
+
+	return map[string]interface{}{
+		"openapi": openAPIVersion,
+		"info": map[string]interface{}{
+			"title":   "partsbundler",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+		},
+	}
+}
+
+// OpenAPI serves the OpenAPI document of endpoints.
+func OpenAPI(endpoints []Endpoint) gin.HandlerFunc {
+	doc := OpenAPIDocument(endpoints)
+
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// openAPIPath converts a gin path to an OpenAPI path and its path
+// parameters, which are all integers.
+func openAPIPath(path string) (string, []interface{}) {
+	params := []interface{}{}
+	segments := strings.Split(path, "/")
+
+	for i, s := range segments {
+		if !strings.HasPrefix(s, ":") {
+			continue
+		}
+
+		name := s[1:]
+		segments[i] = "{" + name + "}"
+		params = append(params, map[string]interface{}{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "integer", "format": "int64"},
+		})
+	}
+
+	return strings.Join(segments, "/"), params
+}
+
+func openAPIResponses(e Endpoint, schemas map[string]interface{}) map[string]interface{} {
+	responses := map[string]interface{}{
+		"default": map[string]interface{}{
+			"description": "Error",
+			"content":     jsonContent(schemaFor(reflect.TypeOf(core.ErrorResponse{}), schemas)),
+		},
+	}
+
+	if e.response == nil {
+		responses["204"] = map[string]interface{}{"description": "No Content"}
+
+		return responses
+	}
+
+	ok := map[string]interface{}{
+		"description": "OK",
+		"content":     jsonContent(schemaFor(reflect.TypeOf(e.response), schemas)),
+	}
+
+	if e.paged {
+		ok["headers"] = map[string]interface{}{
+			"X-Total-Count": map[string]interface{}{
+				"description": "number of items across every page",
+				"schema":      map[string]interface{}{"type": "integer"},
+			},
+		}
+	}
+
+	responses["200"] = ok
+
+	return responses
+}
+
+func jsonContent(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{
+			"schema": schema,
+		},
+	}
+}
+
+func handlerName(h gin.HandlerFunc) string {
+	name := runtime.FuncForPC(reflect.ValueOf(h).Pointer()).Name()
+
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// schemaFor returns the schema of the JSON encoding of t. Structs are
+// added to schemas and referenced by name.
+func schemaFor(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := schemaFor(t.Elem(), schemas)
+		if _, ok := schema["$ref"]; ok {
+			return map[string]interface{}{"allOf": []interface{}{schema}, "nullable": true}
+		}
+		schema["nullable"] = true
+
+		return schema
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int32:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Int64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		schema := map[string]interface{}{"type": "string"}
+		if values, ok := enums[t]; ok {
+			schema["enum"] = values
+		}
+
+		return schema
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":     "array",
+			"items":    schemaFor(t.Elem(), schemas),
+			"nullable": t.Kind() == reflect.Slice,
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": schemaFor(t.Elem(), schemas),
+		}
+	case reflect.Struct:
+		if _, ok := schemas[t.Name()]; !ok {
+			// Reserve the name first so recursive types terminate.
+			schemas[t.Name()] = nil
+			schemas[t.Name()] = structSchema(t, schemas)
+		}
+
+		return map[string]interface{}{"$ref": schemaRef + t.Name()}
+	}
+
+	return map[string]interface{}{}
+}
+
+func structSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+
+			if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+				addFields(f.Type)
+				continue
+			}
+
+			if f.PkgPath != "" || tag == "-" {
+				continue
+			}
+
+			opts := strings.Split(tag, ",")
+			name := opts[0]
+			if name == "" {
+				name = f.Name
+			}
+
+			schema := schemaFor(f.Type, schemas)
+			if name == "id" {
+				schema["readOnly"] = true
+			}
+
+			properties[name] = schema
+
+			omitempty := false
+			for _, o := range opts[1:] {
+				omitempty = omitempty || o == "omitempty"
+			}
+
+			if !omitempty {
+				required = append(required, name)
+			}
+		}
+	}
+
+	addFields(t)
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
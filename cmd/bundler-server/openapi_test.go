@@ -0,0 +1,388 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sombrerosheep/partsbundler/pkg/service/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+var updateOpenAPI = flag.Bool("update", false, "rewrite testdata/openapi.json from the route table")
+
+const openAPIGolden = "testdata/openapi.json"
+
+// contractCase is a request made to an endpoint by Test_OpenAPIContract.
+type contractCase struct {
+	method      string
+	route       string
+	uri         string
+	contentType string
+	body        string
+	status      int
+}
+
+// contractCases holds at least one request for every endpoint.
+var contractCases = []contractCase{
+	{http.MethodGet, "/healthz", "/healthz", "", "", http.StatusOK},
+	{http.MethodGet, "/readyz", "/readyz", "", "", http.StatusOK},
+	{http.MethodGet, "/parts", "/parts?kind=Resistor&limit=1", "", "", http.StatusOK},
+	{http.MethodGet, "/parts", "/parts?used=maybe", "", "", http.StatusBadRequest},
+	{http.MethodGet, "/parts/duplicates", "/parts/duplicates", "", "", http.StatusOK},
+	{http.MethodGet, "/parts/:partId", "/parts/1", "", "", http.StatusOK},
+	{http.MethodGet, "/parts/:partId", "/parts/999", "", "", http.StatusNotFound},
+	{http.MethodPost, "/parts", "/parts", "", `{"name": "4k7", "kind": "Resistor"}`, http.StatusOK},
+	{http.MethodPost, "/parts", "/parts", "", `{"name": "", "kind": "Resistor"}`, http.StatusBadRequest},
+	{http.MethodPut, "/parts/:partId", "/parts/1", "", `{"name": "1k5", "kind": "Resistor"}`, http.StatusOK},
+	{http.MethodPatch, "/parts/:partId", "/parts/1", "", `{"name": "1k2"}`, http.StatusOK},
+	{http.MethodDelete, "/parts/:partId", "/parts/2", "", "", http.StatusNoContent},
+	{http.MethodDelete, "/parts/:partId", "/parts/1", "", "", http.StatusConflict},
+	{http.MethodPost, "/parts/:partId/links", "/parts/1/links", "", `{"url": "example.com/1k"}`, http.StatusOK},
+	{http.MethodDelete, "/parts/:partId/links/:linkId", "/parts/1/links/1", "", "", http.StatusNoContent},
+	{http.MethodPost, "/parts/:partId/merge", "/parts/1/merge?duplicate=2", "", "", http.StatusBadRequest},
+	{http.MethodGet, "/kits", "/kits?sort=name", "", "", http.StatusOK},
+	{http.MethodGet, "/kits/:kitId", "/kits/1", "", "", http.StatusOK},
+	{http.MethodPost, "/kits", "/kits", "", `{"name": "Fuzz", "parts": [{"id": 2, "quantity": 3}], "links": [{"url": "example.com/fuzz"}]}`, http.StatusOK},
+	{http.MethodPost, "/kits/import", "/kits/import", "", `{"name": "Boost", "parts": [{"kind": "IC", "name": "TL072"}]}`, http.StatusOK},
+	{http.MethodPost, "/kits/import/bom", "/kits/import/bom?name=bom", "text/csv", "Ref,Value,Type,Qty\nR1,1k,Res,1\n", http.StatusOK},
+	{http.MethodPut, "/kits/:kitId", "/kits/1", "", `{"name": "MyKit v2", "schematics": "example.com/v2"}`, http.StatusOK},
+	{http.MethodPatch, "/kits/:kitId", "/kits/1", "", `{"diagram": "example.com/v2-diagram"}`, http.StatusOK},
+	{http.MethodDelete, "/kits/:kitId", "/kits/1", "", "", http.StatusNoContent},
+	{http.MethodGet, "/kits/:kitId/export", "/kits/1/export", "", "", http.StatusOK},
+	{http.MethodPost, "/kits/:kitId/links", "/kits/1/links", "", `{"url": "example.com/build"}`, http.StatusOK},
+	{http.MethodDelete, "/kits/:kitId/links/:linkId", "/kits/1/links/1", "", "", http.StatusNoContent},
+	{http.MethodPost, "/kits/:kitId/parts/:partId", "/kits/1/parts/2?quantity=2", "", "", http.StatusOK},
+	{http.MethodDelete, "/kits/:kitId/parts/:partId", "/kits/1/parts/1", "", "", http.StatusNoContent},
+	{http.MethodPut, "/kits/:kitId/parts/:partId/designators", "/kits/1/parts/1/designators", "", `["R1", "R2"]`, http.StatusOK},
+	{http.MethodPut, "/kits/:kitId/parts/:partId/:quantity", "/kits/1/parts/1/4", "", "", http.StatusOK},
+	{http.MethodPost, "/plans", "/plans", "", `{"builds": [{"kitId": 1, "count": 2}], "onHand": {"1": 1}}`, http.StatusOK},
+	{http.MethodGet, "/inventory", "/inventory", "", "", http.StatusOK},
+	{http.MethodGet, "/inventory/:partId", "/inventory/1", "", "", http.StatusOK},
+	{http.MethodPut, "/inventory/:partId/location", "/inventory/1/location", "", `{"location": "drawer B2"}`, http.StatusOK},
+	{http.MethodGet, "/inventory/:partId/adjustments", "/inventory/1/adjustments", "", "", http.StatusOK},
+	{http.MethodPost, "/inventory/:partId/adjustments", "/inventory/1/adjustments", "", `{"kind": "received", "quantity": 5}`, http.StatusOK},
+	{http.MethodPost, "/inventory/:partId/adjustments", "/inventory/1/adjustments", "", `{"kind": "borrowed", "quantity": 5}`, http.StatusBadRequest},
+}
+
+func Test_OpenAPIDocument(t *testing.T) {
+	t.Run("should match the checked in document", func(t *testing.T) {
+		got, err := json.MarshalIndent(OpenAPIDocument(endpoints), "", "  ")
+		assert.Nil(t, err)
+		got = append(got, '\n')
+
+		if *updateOpenAPI {
+			err = os.MkdirAll(filepath.Dir(openAPIGolden), 0755)
+			assert.Nil(t, err)
+
+			err = os.WriteFile(openAPIGolden, got, 0644)
+			assert.Nil(t, err)
+		}
+
+		expected, err := os.ReadFile(openAPIGolden)
+		assert.Nil(t, err)
+
+		assert.Equal(t, string(expected), string(got),
+			"the API changed, review the diff and run go test ./cmd/bundler-server -run Test_OpenAPIDocument -update")
+	})
+
+	t.Run("should be served at /openapi.json", func(t *testing.T) {
+		router := CreateStubServer()
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/openapi.json", nil)
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		expected, err := json.Marshal(OpenAPIDocument(endpoints))
+		assert.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, string(expected), w.Body.String())
+	})
+
+	t.Run("should describe every endpoint", func(t *testing.T) {
+		doc := loadOpenAPISpec(t)
+
+		for _, e := range endpoints {
+			path, _ := openAPIPath(e.path)
+			op := doc.operation(e.method, path)
+
+			if assert.NotNil(t, op, "%s %s", e.method, e.path) {
+				assert.NotEmpty(t, op["summary"], "%s %s", e.method, e.path)
+			}
+		}
+	})
+}
+
+func Test_OpenAPIContract(t *testing.T) {
+	doc := loadOpenAPISpec(t)
+
+	t.Run("should have a case for every endpoint", func(t *testing.T) {
+		for _, e := range endpoints {
+			found := false
+			for _, c := range contractCases {
+				found = found || (c.method == e.method && c.route == e.path)
+			}
+
+			assert.True(t, found, "no contract case for %s %s", e.method, e.path)
+		}
+	})
+
+	for _, c := range contractCases {
+		c := c
+
+		t.Run(fmt.Sprintf("%s %s %d", c.method, c.uri, c.status), func(t *testing.T) {
+			path, _ := openAPIPath(c.route)
+			op := doc.operation(c.method, path)
+			if op == nil {
+				t.Fatalf("%s %s is not in the document", c.method, path)
+			}
+
+			// Requests the server accepts must match the document too.
+			if c.body != "" && c.contentType == "" && c.status < 400 {
+				schema := doc.requestSchema(op)
+				if assert.NotNil(t, schema, "request body is not documented") {
+					assert.Empty(t, doc.validateJSON(schema, c.body, true), "request does not match the document")
+				}
+			}
+
+			router := CreateStubServer()
+			bundlerService = withStorage(&fakeStorage{})
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(c.method, c.uri, strings.NewReader(c.body))
+			assert.Nil(t, err)
+
+			if c.contentType != "" {
+				req.Header.Set("Content-Type", c.contentType)
+			}
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, c.status, w.Code, w.Body.String())
+
+			response := doc.response(op, w.Code)
+			if response == nil {
+				t.Fatalf("status %d is not documented", w.Code)
+			}
+
+			schema := doc.contentSchema(response)
+			if schema == nil {
+				assert.Empty(t, w.Body.String(), "undocumented response body")
+				return
+			}
+
+			assert.Empty(t, doc.validateJSON(schema, w.Body.String(), false), w.Body.String())
+
+			if headers, ok := response["headers"].(map[string]interface{}); ok {
+				for name := range headers {
+					_, err := strconv.Atoi(w.Header().Get(name))
+					assert.Nil(t, err, "header %s", name)
+				}
+			}
+		})
+	}
+}
+
+// openAPISpec is the served OpenAPI document decoded into generic JSON
+// values, as a client would see it.
+type openAPISpec map[string]interface{}
+
+func loadOpenAPISpec(t *testing.T) openAPISpec {
+	router := CreateStubServer()
+	bundlerService = mock.StubBundlerService
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, "/openapi.json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.ServeHTTP(w, req)
+
+	var doc openAPISpec
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	return doc
+}
+
+func (doc openAPISpec) operation(method string, path string) map[string]interface{} {
+	paths, _ := doc["paths"].(map[string]interface{})
+	item, _ := paths[path].(map[string]interface{})
+	op, _ := item[strings.ToLower(method)].(map[string]interface{})
+
+	return op
+}
+
+func (doc openAPISpec) requestSchema(op map[string]interface{}) map[string]interface{} {
+	body, _ := op["requestBody"].(map[string]interface{})
+
+	return doc.contentSchema(body)
+}
+
+func (doc openAPISpec) response(op map[string]interface{}, status int) map[string]interface{} {
+	responses, _ := op["responses"].(map[string]interface{})
+
+	if r, ok := responses[strconv.Itoa(status)].(map[string]interface{}); ok {
+		return r
+	}
+
+	if status >= 400 {
+		r, _ := responses["default"].(map[string]interface{})
+		return r
+	}
+
+	return nil
+}
+
+func (doc openAPISpec) contentSchema(obj map[string]interface{}) map[string]interface{} {
+	content, _ := obj["content"].(map[string]interface{})
+	media, _ := content["application/json"].(map[string]interface{})
+	schema, _ := media["schema"].(map[string]interface{})
+
+	return schema
+}
+
+func (doc openAPISpec) validateJSON(schema map[string]interface{}, body string, request bool) []string {
+	var value interface{}
+	if err := json.Unmarshal([]byte(body), &value); err != nil {
+		return []string{err.Error()}
+	}
+
+	return doc.validate(schema, value, "$", request)
+}
+
+// validate checks value against the subset of OpenAPI schemas built by
+// OpenAPIDocument. Requests may leave out required fields.
+func (doc openAPISpec) validate(schema map[string]interface{}, value interface{}, at string, request bool) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		components, _ := doc["components"].(map[string]interface{})
+		schemas, _ := components["schemas"].(map[string]interface{})
+		target, ok := schemas[strings.TrimPrefix(ref, schemaRef)].(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: unknown schema %s", at, ref)}
+		}
+
+		return doc.validate(target, value, at, request)
+	}
+
+	if value == nil {
+		if schema["nullable"] == true {
+			return nil
+		}
+
+		return []string{fmt.Sprintf("%s: must not be null", at)}
+	}
+
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		var problems []string
+		for _, s := range all {
+			problems = append(problems, doc.validate(s.(map[string]interface{}), value, at, request)...)
+		}
+
+		return problems
+	}
+
+	var problems []string
+	fail := func(format string, args ...interface{}) []string {
+		return append(problems, fmt.Sprintf("%s: %s", at, fmt.Sprintf(format, args...)))
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return fail("expected an object, got %T", value)
+		}
+
+		properties, _ := schema["properties"].(map[string]interface{})
+
+		if !request {
+			required, _ := schema["required"].([]interface{})
+			for _, name := range required {
+				if _, ok := obj[name.(string)]; !ok {
+					problems = fail("missing required property %s", name)
+				}
+			}
+		}
+
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if s, ok := properties[k].(map[string]interface{}); ok {
+				problems = append(problems, doc.validate(s, obj[k], at+"."+k, request)...)
+				continue
+			}
+
+			switch extra := schema["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					problems = fail("unexpected property %s", k)
+				}
+			case map[string]interface{}:
+				problems = append(problems, doc.validate(extra, obj[k], at+"."+k, request)...)
+			}
+		}
+	case "array":
+		list, ok := value.([]interface{})
+		if !ok {
+			return fail("expected an array, got %T", value)
+		}
+
+		items, _ := schema["items"].(map[string]interface{})
+		for i, item := range list {
+			problems = append(problems, doc.validate(items, item, fmt.Sprintf("%s[%d]", at, i), request)...)
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fail("expected a string, got %T", value)
+		}
+
+		if enum, ok := schema["enum"].([]interface{}); ok {
+			found := false
+			for _, e := range enum {
+				found = found || e == s
+			}
+
+			if !found {
+				problems = fail("'%s' is not one of %v", s, enum)
+			}
+		}
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			return fail("expected a number, got %T", value)
+		}
+
+		if schema["type"] == "integer" && n != float64(int64(n)) {
+			problems = fail("expected an integer, got %v", n)
+		}
+
+		if min, ok := schema["minimum"].(float64); ok && n < min {
+			problems = fail("%v is less than %v", n, min)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fail("expected a boolean, got %T", value)
+		}
+	}
+
+	return problems
+}
@@ -1,125 +1,445 @@
 package main
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sombrerosheep/partsbundler/pkg/bom"
 	"github.com/sombrerosheep/partsbundler/pkg/core"
+	"github.com/sombrerosheep/partsbundler/pkg/service"
 )
 
 type Endpoint struct {
 	path    string
 	method  string
 	handler gin.HandlerFunc
+
+	// The fields below describe the endpoint in the OpenAPI document.
+	// request and response are zero values of the request body and the
+	// 200 response body. A nil request takes no body and a nil response
+	// answers 204 No Content. Paged endpoints read the parameters of
+	// pageRequest and set X-Total-Count.
+	summary  string
+	query    []QueryParam
+	paged    bool
+	request  interface{}
+	response interface{}
+}
+
+// QueryParam documents a query parameter read by a handler.
+type QueryParam struct {
+	name        string
+	kind        string
+	description string
+}
+
+var partQueryParams = []QueryParam{
+	{"kind", "string", "only parts of this kind"},
+	{"q", "string", "text the part name or value contains"},
+	{"min", "string", "smallest value, such as 1k"},
+	{"max", "string", "largest value, such as 100k"},
+	{"used", "boolean", "only parts some kit uses, or none does"},
+	{"links", "boolean", "only parts with links, or without"},
+}
+
+var pageParams = []QueryParam{
+	{"offset", "integer", "number of items to skip"},
+	{"limit", "integer", fmt.Sprintf("items per page, at most %d; %d by default when offset is given, otherwise every item", maxPageLimit, defaultPageLimit)},
+	{"sort", "string", "key to sort by"},
+	{"order", "string", "asc or desc"},
+}
+
+var mergeParams = []QueryParam{
+	{"duplicate", "integer", "id of the part merged into this one"},
+}
+
+var quantityParams = []QueryParam{
+	{"quantity", "integer", "number of the part the kit uses, 1 by default"},
+}
+
+var bomParams = []QueryParam{
+	{"name", "string", "name of the new kit"},
+	{"ref", "string", "designator column name"},
+	{"value", "string", "value column name"},
+	{"kind", "string", "kind column name"},
+	{"qty", "string", "quantity column name"},
+	{"kinds", "string", "extra kind aliases as alias:kind pairs separated by commas"},
 }
 
+// csvBody stands for a CSV request body.
+type csvBody struct{}
+
 var endpoints = []Endpoint{
 	{
-		path:    "/parts",
-		method:  http.MethodGet,
-		handler: GetAllParts,
+		path:     "/healthz",
+		method:   http.MethodGet,
+		handler:  Healthz,
+		summary:  "Report that the server is up",
+		response: HealthStatus{},
 	},
 	{
-		path:    "/parts/:partId",
-		method:  http.MethodGet,
-		handler: GetPart,
+		path:     "/readyz",
+		method:   http.MethodGet,
+		handler:  Readyz,
+		summary:  "Report whether the storage can serve requests",
+		response: HealthStatus{},
+	},
+	{
+		path:     "/parts",
+		method:   http.MethodGet,
+		handler:  GetAllParts,
+		summary:  "List parts matching a query",
+		query:    partQueryParams,
+		paged:    true,
+		response: []core.Part{},
 	},
 	{
-		path:    "/parts",
-		method:  http.MethodPost,
-		handler: CreatePart,
+		path:     "/parts/duplicates",
+		method:   http.MethodGet,
+		handler:  GetDuplicateParts,
+		summary:  "List groups of parts that look like duplicates",
+		response: [][]core.Part{},
+	},
+	{
+		path:     "/parts/:partId",
+		method:   http.MethodGet,
+		handler:  GetPart,
+		summary:  "Get a part",
+		response: core.Part{},
+	},
+	{
+		path:     "/parts",
+		method:   http.MethodPost,
+		handler:  CreatePart,
+		summary:  "Create a part",
+		request:  core.Part{},
+		response: core.Part{},
+	},
+	{
+		path:     "/parts/:partId",
+		method:   http.MethodPut,
+		handler:  UpdatePart,
+		summary:  "Replace the name and kind of a part",
+		request:  core.Part{},
+		response: core.Part{},
+	},
+	{
+		path:     "/parts/:partId",
+		method:   http.MethodPatch,
+		handler:  PatchPart,
+		summary:  "Change some fields of a part",
+		request:  core.PartPatch{},
+		response: core.Part{},
 	},
 	{
 		path:    "/parts/:partId",
 		method:  http.MethodDelete,
 		handler: DeletePart,
+		summary: "Delete a part that no kit uses",
 	},
 	{
-		path:    "/parts/:partId/links",
-		method:  http.MethodPost,
-		handler: AddPartLink,
+		path:     "/parts/:partId/links",
+		method:   http.MethodPost,
+		handler:  AddPartLink,
+		summary:  "Add a link to a part",
+		request:  core.Link{},
+		response: core.Link{},
 	},
 	{
 		path:    "/parts/:partId/links/:linkId",
 		method:  http.MethodDelete,
 		handler: RemovePartLink,
+		summary: "Remove a link from a part",
 	},
 	{
-		path:    "/kits",
-		method:  http.MethodGet,
-		handler: GetAllKits,
+		path:     "/parts/:partId/merge",
+		method:   http.MethodPost,
+		handler:  MergePart,
+		summary:  "Merge a duplicate into a part",
+		query:    mergeParams,
+		response: core.Part{},
 	},
 	{
-		path:    "/kits/:kitId",
-		method:  http.MethodGet,
-		handler: GetKit,
+		path:     "/kits",
+		method:   http.MethodGet,
+		handler:  GetAllKits,
+		summary:  "List kits",
+		paged:    true,
+		response: []core.Kit{},
 	},
 	{
-		path:    "/kits",
-		method:  http.MethodPost,
-		handler: CreateKit,
+		path:     "/kits/:kitId",
+		method:   http.MethodGet,
+		handler:  GetKit,
+		summary:  "Get a kit",
+		response: core.Kit{},
+	},
+	{
+		path:     "/kits",
+		method:   http.MethodPost,
+		handler:  CreateKit,
+		summary:  "Create a kit with its parts and links",
+		request:  core.Kit{},
+		response: core.Kit{},
+	},
+	{
+		path:     "/kits/import",
+		method:   http.MethodPost,
+		handler:  ImportKit,
+		summary:  "Import a kit spec, creating missing parts",
+		request:  core.KitSpec{},
+		response: core.KitImport{},
+	},
+	{
+		path:     "/kits/import/bom",
+		method:   http.MethodPost,
+		handler:  ImportKitBOM,
+		summary:  "Import a kit from a CSV bill of materials",
+		query:    bomParams,
+		request:  csvBody{},
+		response: core.KitImport{},
+	},
+	{
+		path:     "/kits/:kitId",
+		method:   http.MethodPut,
+		handler:  UpdateKit,
+		summary:  "Replace the name, schematic and diagram of a kit",
+		request:  core.Kit{},
+		response: core.Kit{},
+	},
+	{
+		path:     "/kits/:kitId",
+		method:   http.MethodPatch,
+		handler:  PatchKit,
+		summary:  "Change some fields of a kit",
+		request:  core.KitPatch{},
+		response: core.Kit{},
 	},
 	{
 		path:    "/kits/:kitId",
 		method:  http.MethodDelete,
 		handler: DeleteKit,
+		summary: "Delete a kit",
 	},
 	{
-		path:    "/kits/:kitId/links",
-		method:  http.MethodPost,
-		handler: AddKitLink,
+		path:     "/kits/:kitId/export",
+		method:   http.MethodGet,
+		handler:  ExportKit,
+		summary:  "Export a kit as a kit spec",
+		response: core.KitSpec{},
+	},
+	{
+		path:     "/kits/:kitId/links",
+		method:   http.MethodPost,
+		handler:  AddKitLink,
+		summary:  "Add a link to a kit",
+		request:  core.Link{},
+		response: core.Link{},
 	},
 	{
 		path:    "/kits/:kitId/links/:linkId",
 		method:  http.MethodDelete,
 		handler: RemoveKitLink,
+		summary: "Remove a link from a kit",
 	},
 	{
-		path:    "/kits/:kitId/parts/:partId",
-		method:  http.MethodPost,
-		handler: AddKitPart,
+		path:     "/kits/:kitId/parts/:partId",
+		method:   http.MethodPost,
+		handler:  AddKitPart,
+		summary:  "Add a part to a kit",
+		query:    quantityParams,
+		response: core.KitPart{},
 	},
 	{
 		path:    "/kits/:kitId/parts/:partId",
 		method:  http.MethodDelete,
 		handler: RemoveKitPart,
+		summary: "Remove a part from a kit",
+	},
+	{
+		path:     "/kits/:kitId/parts/:partId/designators",
+		method:   http.MethodPut,
+		handler:  SetKitPartDesignators,
+		summary:  "Replace the designators of a part in a kit",
+		request:  []string{},
+		response: core.KitPart{},
+	},
+	{
+		path:     "/kits/:kitId/parts/:partId/:quantity",
+		method:   http.MethodPut,
+		handler:  UpdateKitPartQuantity,
+		summary:  "Set the quantity of a part in a kit",
+		response: core.KitPart{},
+	},
+	{
+		path:     "/plans",
+		method:   http.MethodPost,
+		handler:  CreatePlan,
+		summary:  "Plan the parts needed to build kits",
+		request:  core.PlanRequest{},
+		response: core.Plan{},
+	},
+	{
+		path:     "/inventory",
+		method:   http.MethodGet,
+		handler:  GetAllStock,
+		summary:  "List the stock of every part",
+		response: []core.Stock{},
+	},
+	{
+		path:     "/inventory/:partId",
+		method:   http.MethodGet,
+		handler:  GetStock,
+		summary:  "Get the stock of a part",
+		response: core.Stock{},
+	},
+	{
+		path:     "/inventory/:partId/location",
+		method:   http.MethodPut,
+		handler:  SetStockLocation,
+		summary:  "Set where a part is stored",
+		request:  core.Stock{},
+		response: core.Stock{},
 	},
 	{
-		path:    "/kits/:kitId/parts/:partId/:quantity",
-		method:  http.MethodPut,
-		handler: UpdateKitPartQuantity,
+		path:     "/inventory/:partId/adjustments",
+		method:   http.MethodGet,
+		handler:  GetStockHistory,
+		summary:  "List the stock adjustments of a part",
+		response: []core.StockAdjustment{},
 	},
+	{
+		path:     "/inventory/:partId/adjustments",
+		method:   http.MethodPost,
+		handler:  AdjustStock,
+		summary:  "Adjust the stock of a part",
+		request:  core.StockAdjustment{},
+		response: core.Stock{},
+	},
+}
+
+// partQuery reads a PartQuery from the kind, q, min, max, used and
+// links query parameters.
+func partQuery(c *gin.Context) (core.PartQuery, error) {
+	query := core.PartQuery{
+		Kind: core.PartType(c.Query("kind")),
+		Text: c.Query("q"),
+		Min:  c.Query("min"),
+		Max:  c.Query("max"),
+	}
+
+	flags := map[string]**bool{
+		"used":  &query.InUse,
+		"links": &query.HasLinks,
+	}
+
+	for key, flag := range flags {
+		s, ok := c.GetQuery(key)
+		if !ok {
+			continue
+		}
+
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return core.PartQuery{}, core.InvalidField(key, fmt.Sprintf("'%s' is not a boolean", s))
+		}
+
+		*flag = &b
+	}
+
+	return query, nil
 }
 
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+)
+
+// pageRequest reads the offset, limit, sort and order query parameters.
+// The limit may not exceed maxPageLimit. It defaults to defaultPageLimit
+// when an offset is given; a request with neither gets every item, as
+// lists did before they were paged.
+func pageRequest(c *gin.Context) (core.PageRequest, error) {
+	page := core.PageRequest{
+		Sort: c.Query("sort"),
+	}
+
+	if s, ok := c.GetQuery("offset"); ok {
+		offset, err := strconv.Atoi(s)
+		if err != nil {
+			return page, core.InvalidPageRequest{Reason: fmt.Sprintf("invalid offset '%s'", s)}
+		}
+
+		page.Offset = offset
+		page.Limit = defaultPageLimit
+	}
+
+	if s, ok := c.GetQuery("limit"); ok {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return page, core.InvalidPageRequest{Reason: fmt.Sprintf("limit must be between 1 and %d", maxPageLimit)}
+		}
+
+		page.Limit = limit
+	}
+
+	switch order := c.Query("order"); order {
+	case "", "asc":
+	case "desc":
+		page.Desc = true
+	default:
+		return page, core.InvalidPageRequest{Reason: fmt.Sprintf("invalid order '%s', expected asc or desc", order)}
+	}
+
+	return page, nil
+}
+
+// setTotalCount reports the number of items across every page.
+func setTotalCount(c *gin.Context, total int) {
+	c.Header("X-Total-Count", strconv.Itoa(total))
+}
+
+// GetAllParts returns a page of the parts matching the optional query
+// parameters read by partQuery, paged and sorted by the parameters read
+// by pageRequest.
 func GetAllParts(c *gin.Context) {
 	svc := GetBundlerService()
-	parts, err := svc.Parts.GetAll()
+
+	query, err := partQuery(c)
 	if err != nil {
-		c.String(http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, parts)
+	page, err := pageRequest(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	parts, err := svc.Parts.ListContext(c.Request.Context(), query, page)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setTotalCount(c, parts.Total)
+	c.JSON(http.StatusOK, parts.Parts)
 }
 
 func GetPart(c *gin.Context) {
 	svc := GetBundlerService()
-	sid := c.Param("partId")
-	id, err := strconv.ParseInt(sid, 10, 64)
+	id, err := int64Param(c, "partId")
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		c.Error(err)
 		return
 	}
 
-	part, err := svc.Parts.Get(id)
+	part, err := svc.Parts.GetContext(c.Request.Context(), id)
 	if err != nil {
-		if _, ok := err.(core.PartNotFound); ok {
-			c.String(http.StatusNotFound, err.Error())
-			return
-		}
-
-		c.String(http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
@@ -130,111 +450,228 @@ func CreatePart(c *gin.Context) {
 	svc := GetBundlerService()
 
 	var input core.Part
-	err := c.BindJSON(&input)
+	err := bindJSON(c, &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = input.Validate()
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		c.Error(err)
 		return
 	}
 
-	part, err := svc.Parts.New(input.Name, core.PartType(input.Kind))
+	part, err := svc.Parts.NewContext(c.Request.Context(), input.Name, core.PartType(input.Kind))
 	if err != nil {
-		c.String(http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, part)
 }
 
-func DeletePart(c *gin.Context) {
+func UpdatePart(c *gin.Context) {
+	svc := GetBundlerService()
+	id, err := int64Param(c, "partId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input core.Part
+	err = bindJSON(c, &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = input.Validate()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	part, err := svc.Parts.UpdateContext(c.Request.Context(), id, input.Name, input.Kind)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, part)
+}
+
+func PatchPart(c *gin.Context) {
 	svc := GetBundlerService()
-	partId := c.Param("partId")
+	id, err := int64Param(c, "partId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
 
-	id, err := strconv.ParseInt(partId, 10, 64)
+	var input core.PartPatch
+	err = bindJSON(c, &input)
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		c.Error(err)
 		return
 	}
 
-	err = svc.Parts.Delete(id)
+	part, err := svc.Parts.PatchContext(c.Request.Context(), id, input)
 	if err != nil {
-		if _, ok := err.(core.PartNotFound); ok {
-			c.String(http.StatusNotFound, err.Error())
-			return
-		}
+		c.Error(err)
+		return
+	}
 
-		c.String(http.StatusInternalServerError, err.Error())
+	c.JSON(http.StatusOK, part)
+}
+
+func DeletePart(c *gin.Context) {
+	svc := GetBundlerService()
+	id, err := int64Param(c, "partId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = svc.Parts.DeleteContext(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
 
+func GetDuplicateParts(c *gin.Context) {
+	svc := GetBundlerService()
+	dupes, err := svc.Parts.FindDuplicatesContext(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dupes)
+}
+
+func MergePart(c *gin.Context) {
+	svc := GetBundlerService()
+
+	partId, err := int64Param(c, "partId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	sid := c.Query("duplicate")
+	duplicateId, err := strconv.ParseInt(sid, 10, 64)
+	if err != nil {
+		c.Error(core.InvalidField("duplicate", fmt.Sprintf("'%s' is not an integer", sid)))
+		return
+	}
+
+	err = svc.Parts.MergeContext(c.Request.Context(), partId, duplicateId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	part, err := svc.Parts.GetContext(c.Request.Context(), partId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, part)
+}
+
+// GetAllKits returns a page of kits, paged and sorted by the parameters
+// read by pageRequest.
 func GetAllKits(c *gin.Context) {
 	svc := GetBundlerService()
-	kits, err := svc.Kits.GetAll()
+
+	page, err := pageRequest(c)
 	if err != nil {
-		c.String(http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, kits)
+	kits, err := svc.Kits.ListContext(c.Request.Context(), page)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setTotalCount(c, kits.Total)
+	c.JSON(http.StatusOK, kits.Kits)
 }
 
 func GetKit(c *gin.Context) {
 	svc := GetBundlerService()
 
-	sid := c.Param("kitId")
-	id, err := strconv.ParseInt(sid, 10, 64)
+	id, err := int64Param(c, "kitId")
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		c.Error(err)
 		return
 	}
 
-	kit, err := svc.Kits.Get(id)
+	kit, err := svc.Kits.GetContext(c.Request.Context(), id)
 	if err != nil {
-		if _, ok := err.(core.KitNotFound); ok {
-			c.String(http.StatusNotFound, err.Error())
-			return
-		}
-
-		c.String(http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, kit)
 }
 
-func AddPartLink(c *gin.Context) {
+func ExportKit(c *gin.Context) {
 	svc := GetBundlerService()
 
-	sid := c.Param("partId")
-	id, err := strconv.ParseInt(sid, 10, 64)
+	id, err := int64Param(c, "kitId")
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		c.Error(err)
 		return
 	}
 
-	_, err = svc.Parts.Get(id)
+	kit, err := svc.Kits.GetContext(c.Request.Context(), id)
 	if err != nil {
-		if _, ok := err.(core.PartNotFound); ok {
-			c.String(http.StatusNotFound, err.Error())
-			return
-		}
+		c.Error(err)
+		return
+	}
 
-		c.String(http.StatusInternalServerError, err.Error())
+	c.JSON(http.StatusOK, core.NewKitSpec(kit))
+}
+
+func AddPartLink(c *gin.Context) {
+	svc := GetBundlerService()
+
+	id, err := int64Param(c, "partId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	_, err = svc.Parts.GetContext(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	var link core.Link
-	err = c.BindJSON(&link)
+	err = bindJSON(c, &link)
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		c.Error(err)
 		return
 	}
 
-	newLink, err := svc.Parts.AddLink(id, link.URL)
+	err = link.Validate()
 	if err != nil {
-		c.String(http.StatusInternalServerError, err.Error())
+		c.Error(err)
+		return
+	}
+
+	newLink, err := svc.Parts.AddLinkContext(c.Request.Context(), id, link.URL)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -244,28 +681,26 @@ func AddPartLink(c *gin.Context) {
 func RemovePartLink(c *gin.Context) {
 	svc := GetBundlerService()
 
-	sid := c.Param("partId")
-	partId, err := strconv.ParseInt(sid, 10, 64)
+	partId, err := int64Param(c, "partId")
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		c.Error(err)
 		return
 	}
 
-	sid = c.Param("linkId")
-	linkId, err := strconv.ParseInt(sid, 10, 64)
+	linkId, err := int64Param(c, "linkId")
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		c.Error(err)
 		return
 	}
 
 	if linkId < 1 {
-		c.String(http.StatusBadRequest, "linkId must not be valide")
+		c.Error(core.InvalidField("linkId", "must be greater than zero"))
 		return
 	}
 
-	err = svc.Parts.RemoveLink(partId, linkId)
+	err = svc.Parts.RemoveLinkContext(c.Request.Context(), partId, linkId)
 	if err != nil {
-		c.String(http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
@@ -277,70 +712,220 @@ func CreateKit(c *gin.Context) {
 	svc := GetBundlerService()
 
 	var input core.Kit
-	c.BindJSON(&input)
+	err := bindJSON(c, &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
 
-	kit, err := svc.Kits.New(input.Name, input.Schematic, input.Diagram)
+	err = input.Validate()
 	if err != nil {
-		c.String(http.StatusInternalServerError, err.Error())
+		c.Error(err)
+		return
+	}
+
+	var kit core.Kit
+	err = svc.Atomic(func(tx *service.BundlerService) error {
+		var err error
+		kit, err = tx.Kits.NewContext(c.Request.Context(), input.Name, input.Schematic, input.Diagram)
+		if err != nil {
+			return err
+		}
+
+		for _, kp := range input.Parts {
+			part, err := tx.Parts.GetContext(c.Request.Context(), kp.ID)
+			if err != nil {
+				return err
+			}
+
+			err = tx.Kits.AddPartContext(c.Request.Context(), kit.ID, kp.ID, kp.Quantity)
+			if err != nil {
+				return err
+			}
+
+			if len(kp.Designators) > 0 {
+				err = tx.Kits.SetPartDesignatorsContext(c.Request.Context(), kit.ID, kp.ID, kp.Designators)
+				if err != nil {
+					return err
+				}
+
+				kp.Designators = core.NormalizeDesignators(kp.Designators)
+				kp.Quantity = uint64(len(kp.Designators))
+			}
+
+			kit.Parts = append(kit.Parts, core.KitPart{
+				Part:        part,
+				Quantity:    kp.Quantity,
+				Designators: kp.Designators,
+			})
+		}
+
+		for _, l := range input.Links {
+			link, err := tx.Kits.AddLinkContext(c.Request.Context(), kit.ID, l.URL)
+			if err != nil {
+				return err
+			}
+
+			kit.Links = append(kit.Links, link)
+		}
+
+		return nil
+	})
+	if err != nil {
+		c.Error(err)
+		return
 	}
 
 	c.JSON(http.StatusOK, kit)
 }
 
-func DeleteKit(c *gin.Context) {
+func ImportKit(c *gin.Context) {
 	svc := GetBundlerService()
-	kitId := c.Param("kitId")
 
-	id, err := strconv.ParseInt(kitId, 10, 64)
+	var input core.KitSpec
+	err := bindJSON(c, &input)
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		c.Error(err)
 		return
 	}
 
-	err = svc.Kits.Delete(id)
+	result, err := svc.Kits.ImportContext(c.Request.Context(), input)
 	if err != nil {
-		if _, ok := err.(core.KitNotFound); ok {
-			c.String(http.StatusNotFound, err.Error())
-			return
-		}
-		c.String(http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
-	c.Status(http.StatusNoContent)
+	c.JSON(http.StatusOK, result)
 }
 
-func AddKitLink(c *gin.Context) {
+// ImportKitBOM creates a kit from a CSV BOM in the request body. The
+// ref, value, kind and qty query parameters override the default
+// column names and kinds adds to the default kind aliases.
+func ImportKitBOM(c *gin.Context) {
 	svc := GetBundlerService()
-	kitId := c.Param("kitId")
 
-	id, err := strconv.ParseInt(kitId, 10, 64)
+	m := bom.DefaultMapping()
+	m.Ref = c.DefaultQuery("ref", m.Ref)
+	m.Value = c.DefaultQuery("value", m.Value)
+	m.Kind = c.DefaultQuery("kind", m.Kind)
+	m.Quantity = c.DefaultQuery("qty", m.Quantity)
+
+	err := m.AddKinds(c.Query("kinds"))
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		c.Error(err)
 		return
 	}
 
-	_, err = svc.Kits.Get(id)
+	result, err := bom.ImportContext(c.Request.Context(), svc.Kits, c.Request.Body, c.Query("name"), m)
 	if err != nil {
-		if _, ok := err.(core.KitNotFound); ok {
-			c.String(http.StatusNotFound, err.Error())
-			return
-		}
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func UpdateKit(c *gin.Context) {
+	svc := GetBundlerService()
+	id, err := int64Param(c, "kitId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input core.Kit
+	err = bindJSON(c, &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = input.Validate()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	kit, err := svc.Kits.UpdateContext(c.Request.Context(), id, input.Name, input.Schematic, input.Diagram)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, kit)
+}
+
+func PatchKit(c *gin.Context) {
+	svc := GetBundlerService()
+	id, err := int64Param(c, "kitId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input core.KitPatch
+	err = bindJSON(c, &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	kit, err := svc.Kits.PatchContext(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, kit)
+}
+
+func DeleteKit(c *gin.Context) {
+	svc := GetBundlerService()
+	id, err := int64Param(c, "kitId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = svc.Kits.DeleteContext(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
 
-		c.String(http.StatusInternalServerError, err.Error())
+func AddKitLink(c *gin.Context) {
+	svc := GetBundlerService()
+	id, err := int64Param(c, "kitId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	_, err = svc.Kits.GetContext(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	var input core.Link
-	err = c.BindJSON(&input)
+	err = bindJSON(c, &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = input.Validate()
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		c.Error(err)
 		return
 	}
 
-	link, err := svc.Kits.AddLink(id, input.URL)
+	link, err := svc.Kits.AddLinkContext(c.Request.Context(), id, input.URL)
 	if err != nil {
-		c.String(http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
@@ -350,23 +935,26 @@ func AddKitLink(c *gin.Context) {
 func RemoveKitLink(c *gin.Context) {
 	svc := GetBundlerService()
 
-	sid := c.Param("kitId")
-	kitId, err := strconv.ParseInt(sid, 10, 64)
+	kitId, err := int64Param(c, "kitId")
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		c.Error(err)
 		return
 	}
 
-	sid = c.Param("linkId")
-	linkId, err := strconv.ParseInt(sid, 10, 64)
+	linkId, err := int64Param(c, "linkId")
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		c.Error(err)
 		return
 	}
 
-	err = svc.Kits.RemoveLink(kitId, linkId)
+	if linkId < 1 {
+		c.Error(core.InvalidField("linkId", "must be greater than zero"))
+		return
+	}
+
+	err = svc.Kits.RemoveLinkContext(c.Request.Context(), kitId, linkId)
 	if err != nil {
-		c.String(http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
@@ -376,37 +964,42 @@ func RemoveKitLink(c *gin.Context) {
 func AddKitPart(c *gin.Context) {
 	svc := GetBundlerService()
 
-	defaultQuantity := uint64(1)
-
-	sid := c.Param("kitId")
-	kitId, err := strconv.ParseInt(sid, 10, 64)
+	kitId, err := int64Param(c, "kitId")
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		c.Error(err)
 		return
 	}
 
-	sid = c.Param("partId")
-	partId, err := strconv.ParseInt(sid, 10, 64)
+	partId, err := int64Param(c, "partId")
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		c.Error(err)
 		return
 	}
 
-	sqty := c.Query("quantity")
-	qty, err := strconv.ParseUint(sqty, 10, 64)
+	qty := uint64(1)
+	if sqty, ok := c.GetQuery("quantity"); ok {
+		qty, err = strconv.ParseUint(sqty, 10, 64)
+		if err != nil {
+			c.Error(core.InvalidField("quantity", fmt.Sprintf("'%s' is not a positive integer", sqty)))
+			return
+		}
+	}
+
+	err = core.ValidateQuantity(qty)
 	if err != nil {
-		qty = defaultQuantity
+		c.Error(err)
+		return
 	}
 
-	err = svc.Kits.AddPart(kitId, partId, qty)
+	err = svc.Kits.AddPartContext(c.Request.Context(), kitId, partId, qty)
 	if err != nil {
-		c.String(http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
-	part, err := svc.Parts.Get(partId)
+	part, err := svc.Parts.GetContext(c.Request.Context(), partId)
 	if err != nil {
-		c.String(http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
@@ -418,67 +1011,73 @@ func AddKitPart(c *gin.Context) {
 	c.JSON(http.StatusOK, kitPart)
 }
 
-// todo: Remove Kit Part
 func RemoveKitPart(c *gin.Context) {
 	svc := GetBundlerService()
 
-	sid := c.Param("kitId")
-	kitId, err := strconv.ParseInt(sid, 10, 64)
+	kitId, err := int64Param(c, "kitId")
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		c.Error(err)
 		return
 	}
 
-	sid = c.Param("partId")
-	partId, err := strconv.ParseInt(sid, 10, 64)
+	partId, err := int64Param(c, "partId")
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		c.Error(err)
 		return
 	}
 
-	err = svc.Kits.RemovePart(kitId, partId)
+	err = svc.Kits.RemovePartContext(c.Request.Context(), kitId, partId)
 	if err != nil {
-		c.String(http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
 
-// todo: Update Kit Part Quantity
 func UpdateKitPartQuantity(c *gin.Context) {
 	svc := GetBundlerService()
 
-	sid := c.Param("kitId")
-	kitId, err := strconv.ParseInt(sid, 10, 64)
+	kitId, err := int64Param(c, "kitId")
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		c.Error(err)
 		return
 	}
 
-	sid = c.Param("partId")
-	partId, err := strconv.ParseInt(sid, 10, 64)
+	partId, err := int64Param(c, "partId")
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		c.Error(err)
 		return
 	}
 
-	sid = c.Param("quantity")
+	sid := c.Param("quantity")
 	quantity, err := strconv.ParseUint(sid, 10, 64)
 	if err != nil {
-		c.String(http.StatusBadRequest, err.Error())
+		c.Error(core.InvalidField("quantity", fmt.Sprintf("'%s' is not a positive integer", sid)))
 		return
 	}
 
-	err = svc.Kits.SetPartQuantity(kitId, partId, quantity)
+	err = core.ValidateQuantity(quantity)
 	if err != nil {
-		c.String(http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
-	part, err := svc.Parts.Get(partId)
+	err = svc.Kits.SetPartQuantityContext(c.Request.Context(), kitId, partId, quantity)
 	if err != nil {
-		c.String(http.StatusInternalServerError, err.Error())
+		c.Error(err)
+		return
+	}
+
+	part, err := svc.Parts.GetContext(c.Request.Context(), partId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	kit, err := svc.Kits.GetContext(c.Request.Context(), kitId)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -487,6 +1086,178 @@ func UpdateKitPartQuantity(c *gin.Context) {
 		Quantity: quantity,
 	}
 
+	for _, kp := range kit.Parts {
+		if kp.ID == partId {
+			kitPart.Designators = kp.Designators
+		}
+	}
+
 	c.JSON(http.StatusOK, kitPart)
+}
+
+// SetKitPartDesignators replaces the reference designators of a part in
+// a kit with the JSON list of designators in the request body.
+func SetKitPartDesignators(c *gin.Context) {
+	svc := GetBundlerService()
+
+	kitId, err := int64Param(c, "kitId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	partId, err := int64Param(c, "partId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var designators []string
+	err = bindJSON(c, &designators)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = svc.Kits.SetPartDesignatorsContext(c.Request.Context(), kitId, partId, designators)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	kit, err := svc.Kits.GetContext(c.Request.Context(), kitId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	for _, kp := range kit.Parts {
+		if kp.ID == partId {
+			c.JSON(http.StatusOK, kp)
+			return
+		}
+	}
+
+	c.Error(core.PartNotInKit{KitID: kitId, PartID: partId})
+}
+
+func CreatePlan(c *gin.Context) {
+	svc := GetBundlerService()
+
+	var input core.PlanRequest
+	err := bindJSON(c, &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if len(input.Builds) == 0 {
+		c.Error(core.InvalidField("builds", "must not be empty"))
+		return
+	}
+
+	plan, err := svc.Kits.PlanContext(c.Request.Context(), input.Builds, input.OnHand)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+func GetAllStock(c *gin.Context) {
+	svc := GetBundlerService()
+	stock, err := svc.Inventory.GetAll()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stock)
+}
+
+func GetStock(c *gin.Context) {
+	svc := GetBundlerService()
+
+	partId, err := int64Param(c, "partId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	stock, err := svc.Inventory.Get(partId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stock)
+}
+
+func SetStockLocation(c *gin.Context) {
+	svc := GetBundlerService()
+
+	partId, err := int64Param(c, "partId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input core.Stock
+	err = bindJSON(c, &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	stock, err := svc.Inventory.SetLocation(partId, input.Location)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stock)
+}
+
+func GetStockHistory(c *gin.Context) {
+	svc := GetBundlerService()
+
+	partId, err := int64Param(c, "partId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	history, err := svc.Inventory.GetHistory(partId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+func AdjustStock(c *gin.Context) {
+	svc := GetBundlerService()
+
+	partId, err := int64Param(c, "partId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input core.StockAdjustment
+	err = bindJSON(c, &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	stock, err := svc.Inventory.Adjust(partId, input.Kind, input.Quantity, input.Note)
+	if err != nil {
+		c.Error(err)
+		return
+	}
 
+	c.JSON(http.StatusOK, stock)
 }
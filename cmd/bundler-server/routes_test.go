@@ -2,25 +2,39 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sombrerosheep/partsbundler/internal/config"
+	"github.com/sombrerosheep/partsbundler/pkg/bom"
 	"github.com/sombrerosheep/partsbundler/pkg/core"
+	"github.com/sombrerosheep/partsbundler/pkg/service/memory"
 	"github.com/sombrerosheep/partsbundler/pkg/service/mock"
 	"github.com/stretchr/testify/assert"
 )
 
 func CreateStubServer() *gin.Engine {
-	router := gin.Default()
 	gin.SetMode(gin.TestMode)
 
-	RegisterEndpoints(router, endpoints)
+	return NewRouter(config.Default(), endpoints)
+}
+
+// errorResponse decodes the error envelope of a failed request.
+func errorResponse(t *testing.T, w *httptest.ResponseRecorder) core.APIError {
+	var body core.ErrorResponse
 
-	return router
+	err := json.Unmarshal(w.Body.Bytes(), &body)
+	assert.Nil(t, err)
+
+	return body.Error
 }
 
 func Test_GetAllParts(t *testing.T) {
@@ -41,6 +55,182 @@ func Test_GetAllParts(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Code)
 		assert.Equal(t, mock.FakeParts[:], actualParts)
 	})
+
+	t.Run("should pass the request context to the service", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/parts", nil)
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, context.Canceled.Error(), errorResponse(t, w).Message)
+	})
+}
+
+func Test_FindParts(t *testing.T) {
+	t.Run("should filter parts by query parameters", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/parts?kind=Capacitor&min=10pf&max=100pf&used=false&links=true", nil)
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		var actualParts []core.Part
+
+		err = json.Unmarshal(w.Body.Bytes(), &actualParts)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, mock.FakeParts[1:2], actualParts)
+	})
+
+	t.Run("should return parts used by kits", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/parts?used=true&q=1K", nil)
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		var actualParts []core.Part
+
+		err = json.Unmarshal(w.Body.Bytes(), &actualParts)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, mock.FakeParts[:1], actualParts)
+	})
+
+	badRequests := []string{
+		"/parts?used=sometimes",
+		"/parts?min=10nf",
+		"/parts?kind=IC&max=10",
+		"/parts?kind=Capacitor&min=lots",
+		"/parts?kind=Flux",
+	}
+
+	for _, url := range badRequests {
+		t.Run(fmt.Sprintf("should return bad request for %s", url), func(t *testing.T) {
+			router := CreateStubServer()
+			bundlerService = mock.StubBundlerService
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			assert.Nil(t, err)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func Test_GetAllPartsPaging(t *testing.T) {
+	t.Run("should return a sorted page with the total count", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/parts?sort=kind&limit=1", nil)
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		var actualParts []core.Part
+
+		err = json.Unmarshal(w.Body.Bytes(), &actualParts)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, mock.FakeParts[1:2], actualParts)
+		assert.Equal(t, fmt.Sprint(len(mock.FakeParts)), w.Header().Get("X-Total-Count"))
+	})
+
+	t.Run("should return later pages", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/parts?sort=id&order=desc&offset=1&limit=5", nil)
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		var actualParts []core.Part
+
+		err = json.Unmarshal(w.Body.Bytes(), &actualParts)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, mock.FakeParts[:1], actualParts)
+	})
+
+	t.Run("should return every part unless a page is asked for", func(t *testing.T) {
+		router := CreateStubServer()
+		svc := memory.CreateMemoryService()
+		bundlerService = svc
+
+		for i := 0; i <= defaultPageLimit; i++ {
+			svc.Parts.New(fmt.Sprintf("%dk", i+1), core.Resistor)
+		}
+
+		pages := map[string]int{
+			"/parts":          defaultPageLimit + 1,
+			"/parts?offset=0": defaultPageLimit,
+		}
+
+		for uri, expected := range pages {
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, uri, nil)
+			assert.Nil(t, err)
+
+			router.ServeHTTP(w, req)
+
+			var actualParts []core.Part
+
+			err = json.Unmarshal(w.Body.Bytes(), &actualParts)
+
+			assert.Nil(t, err)
+			assert.Len(t, actualParts, expected, uri)
+			assert.Equal(t, fmt.Sprint(defaultPageLimit+1), w.Header().Get("X-Total-Count"))
+		}
+	})
+
+	badRequests := []string{
+		"/parts?offset=-1",
+		"/parts?offset=first",
+		"/parts?limit=0",
+		"/parts?limit=1001",
+		"/parts?sort=parts",
+		"/parts?order=sideways",
+	}
+
+	for _, url := range badRequests {
+		t.Run(fmt.Sprintf("should return bad request for %s", url), func(t *testing.T) {
+			router := CreateStubServer()
+			bundlerService = mock.StubBundlerService
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			assert.Nil(t, err)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
 }
 
 func Test_GetPart(t *testing.T) {
@@ -94,7 +284,7 @@ func Test_GetPart(t *testing.T) {
 		router.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusNotFound, w.Code)
-		assert.Equal(t, core.PartNotFound{PartID: partId}.Error(), w.Body.String())
+		assert.Equal(t, core.PartNotFound{PartID: partId}.Error(), errorResponse(t, w).Message)
 	})
 }
 
@@ -130,6 +320,145 @@ func Test_CreatePart(t *testing.T) {
 		assert.Equal(t, partName, part.Name)
 		assert.Equal(t, partKind, string(part.Kind))
 	})
+
+	t.Run("should return bad request for an invalid kind", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, "/parts", strings.NewReader(`{"name": "10k", "kind": "Flux Capacitor"}`))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		actual := errorResponse(t, w)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "invalid_part_type", actual.Code)
+		assert.Equal(t, core.InvalidPartType{InvalidType: "Flux Capacitor"}.Error(), actual.Message)
+		assert.Equal(t, "kind", actual.Fields[0].Field)
+	})
+
+	t.Run("should return bad request for a malformed body", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, "/parts", strings.NewReader(`{"name": 10}`))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		actual := errorResponse(t, w)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "invalid_request", actual.Code)
+		assert.Equal(t, "name", actual.Fields[0].Field)
+	})
+}
+
+func Test_UpdatePart(t *testing.T) {
+	t.Run("should replace part", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		existing := mock.FakeParts[0]
+		body := `{"name": "4700", "kind": "Resistor"}`
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/parts/%d", existing.ID), strings.NewReader(body))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		var part core.Part
+		err = json.Unmarshal(w.Body.Bytes(), &part)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, existing.ID, part.ID)
+		assert.Equal(t, "4700", part.Name)
+		assert.Equal(t, core.NormalizeValue(core.Resistor, "4700"), part.Value)
+	})
+
+	t.Run("should return bad request if kind is invalid", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		body := `{"name": "4700", "kind": "Flux Capacitor"}`
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/parts/%d", mock.FakeParts[0].ID), strings.NewReader(body))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, core.InvalidPartType{InvalidType: "Flux Capacitor"}.Error(), errorResponse(t, w).Message)
+	})
+
+	t.Run("should return not found if part does not exist", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		var partId int64 = 9999
+		body := `{"name": "4700", "kind": "Resistor"}`
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/parts/%d", partId), strings.NewReader(body))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, core.PartNotFound{PartID: partId}.Error(), errorResponse(t, w).Message)
+	})
+}
+
+func Test_PatchPart(t *testing.T) {
+	t.Run("should change only the given fields", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		existing := mock.FakeParts[1]
+		body := `{"name": "100pf"}`
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/parts/%d", existing.ID), strings.NewReader(body))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		var part core.Part
+		err = json.Unmarshal(w.Body.Bytes(), &part)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "100pf", part.Name)
+		assert.Equal(t, existing.Kind, part.Kind)
+	})
+
+	t.Run("should return not found if part does not exist", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		var partId int64 = 9999
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/parts/%d", partId), strings.NewReader(`{}`))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
 }
 
 func Test_DeletePart(t *testing.T) {
@@ -217,7 +546,27 @@ func Test_AddPartLink(t *testing.T) {
 		router.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusNotFound, w.Code)
-		assert.Equal(t, fmt.Sprintf("Part %d not found", partId), w.Body.String())
+		assert.Equal(t, fmt.Sprintf("Part %d not found", partId), errorResponse(t, w).Message)
+	})
+	t.Run("should return bad request if url is invalid", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		reader := bytes.NewReader([]byte(`{"url": "not a link"}`))
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, "/parts/1/links", reader)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		apiErr := errorResponse(t, w)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "invalid_request", apiErr.Code)
+		assert.Len(t, apiErr.Fields, 1)
+		assert.Equal(t, "url", apiErr.Fields[0].Field)
 	})
 }
 
@@ -244,18 +593,19 @@ func Test_RemovePartLink(t *testing.T) {
 		router := CreateStubServer()
 		bundlerService = mock.StubBundlerService
 
-		part := mock.FakeParts[0]
-		link := part.Links[0]
+		partId := int64(999)
+		link := mock.FakeParts[0].Links[0]
 
 		w := httptest.NewRecorder()
-		uri := fmt.Sprintf("/parts/%d/links/%d", part.ID, link.ID)
+		uri := fmt.Sprintf("/parts/%d/links/%d", partId, link.ID)
 		req, err := http.NewRequest(http.MethodDelete, uri, nil)
 
 		assert.Nil(t, err)
 
 		router.ServeHTTP(w, req)
 
-		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "part_not_found", errorResponse(t, w).Code)
 	})
 
 	t.Run("should return LinkNotFound when link does not exist", func(t *testing.T) {
@@ -263,17 +613,40 @@ func Test_RemovePartLink(t *testing.T) {
 		bundlerService = mock.StubBundlerService
 
 		part := mock.FakeParts[0]
-		link := part.Links[0]
+		linkId := int64(999)
 
 		w := httptest.NewRecorder()
-		uri := fmt.Sprintf("/parts/%d/links/%d", part.ID, link.ID)
+		uri := fmt.Sprintf("/parts/%d/links/%d", part.ID, linkId)
 		req, err := http.NewRequest(http.MethodDelete, uri, nil)
 
 		assert.Nil(t, err)
 
 		router.ServeHTTP(w, req)
 
-		assert.Equal(t, http.StatusNoContent, w.Code)
+		actual := errorResponse(t, w)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "link_not_found", actual.Code)
+		assert.Equal(t, core.LinkNotFound{LinkID: linkId, OwnerID: part.ID}.Error(), actual.Message)
+		assert.Equal(t, map[string]interface{}{"linkId": float64(linkId), "ownerId": float64(part.ID)}, actual.Details)
+	})
+
+	t.Run("should return bad request with the invalid parameter", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodDelete, "/parts/1/links/first", nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		actual := errorResponse(t, w)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "invalid_request", actual.Code)
+		assert.Equal(t, []core.FieldError{{Field: "linkId", Message: "'first' is not an integer"}}, actual.Fields)
 	})
 }
 
@@ -282,7 +655,7 @@ func Test_RemovePart(t *testing.T) {
 		router := CreateStubServer()
 		bundlerService = mock.StubBundlerService
 
-		part := mock.FakeParts[0]
+		part := mock.FakeParts[1]
 
 		w := httptest.NewRecorder()
 		uri := fmt.Sprintf("/parts/%d", part.ID)
@@ -294,10 +667,106 @@ func Test_RemovePart(t *testing.T) {
 
 		assert.Equal(t, http.StatusNoContent, w.Code)
 	})
-}
-
-/////////////////////////
-// Kit Tests
+
+	t.Run("should return conflict if part is used by a kit", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		part := mock.FakeKits[0].Parts[0]
+
+		w := httptest.NewRecorder()
+		uri := fmt.Sprintf("/parts/%d", part.ID)
+		req, err := http.NewRequest(http.MethodDelete, uri, nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, core.PartInUse{PartID: part.ID}.Error(), errorResponse(t, w).Message)
+	})
+}
+
+func Test_GetDuplicateParts(t *testing.T) {
+	t.Run("should return duplicate parts", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/parts/duplicates", nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		var dupes [][]core.Part
+		err = json.Unmarshal(w.Body.Bytes(), &dupes)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Len(t, dupes, 0)
+	})
+}
+
+func Test_MergePart(t *testing.T) {
+	t.Run("should return bad request if duplicate is missing", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		part := mock.FakeParts[0]
+
+		w := httptest.NewRecorder()
+		uri := fmt.Sprintf("/parts/%d/merge", part.ID)
+		req, err := http.NewRequest(http.MethodPost, uri, nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should return bad request if parts cannot be merged", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		part := mock.FakeParts[0]
+		other := mock.FakeParts[1]
+
+		w := httptest.NewRecorder()
+		uri := fmt.Sprintf("/parts/%d/merge?duplicate=%d", part.ID, other.ID)
+		req, err := http.NewRequest(http.MethodPost, uri, nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, core.CannotMergeParts{PartID: part.ID, DuplicateID: other.ID}.Error(), errorResponse(t, w).Message)
+	})
+
+	t.Run("should return PartNotFound when duplicate does not exist", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		part := mock.FakeParts[0]
+		duplicateId := int64(9999)
+
+		w := httptest.NewRecorder()
+		uri := fmt.Sprintf("/parts/%d/merge?duplicate=%d", part.ID, duplicateId)
+		req, err := http.NewRequest(http.MethodPost, uri, nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, core.PartNotFound{PartID: duplicateId}.Error(), errorResponse(t, w).Message)
+	})
+}
+
+/////////////////////////
+// Kit Tests
 
 func Test_GetAllKits(t *testing.T) {
 	t.Run("should return kits", func(t *testing.T) {
@@ -318,6 +787,38 @@ func Test_GetAllKits(t *testing.T) {
 		assert.Nil(t, err)
 		assert.Equal(t, http.StatusOK, w.Code)
 		assert.Equal(t, mock.FakeKits[:], actualKits)
+		assert.Equal(t, fmt.Sprint(len(mock.FakeKits)), w.Header().Get("X-Total-Count"))
+	})
+
+	t.Run("should return an empty page past the last kit", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/kits?sort=parts&offset=10", nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "[]", w.Body.String())
+		assert.Equal(t, fmt.Sprint(len(mock.FakeKits)), w.Header().Get("X-Total-Count"))
+	})
+
+	t.Run("should return bad request for an unknown sort key", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/kits?sort=value", nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, core.InvalidPageRequest{Reason: "cannot sort by 'value', expected one of id, name, parts"}.Error(), errorResponse(t, w).Message)
 	})
 }
 
@@ -373,8 +874,61 @@ func Test_GetKit(t *testing.T) {
 		router.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusNotFound, w.Code)
-		assert.Equal(t, core.KitNotFound{KitID: kitId}.Error(), w.Body.String())
+		assert.Equal(t, core.KitNotFound{KitID: kitId}.Error(), errorResponse(t, w).Message)
+
+	})
+}
+
+func Test_ExportKit(t *testing.T) {
+	t.Run("should export each kit", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		for _, v := range mock.FakeKits {
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/kits/%d/export", v.ID), nil)
+
+			assert.Nil(t, err)
+
+			router.ServeHTTP(w, req)
+
+			spec, err := core.ReadKitSpec(w.Body)
+
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, core.NewKitSpec(v), spec)
+		}
+	})
+
+	t.Run("should return bad request if kitId is invalid", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/kits/onetwothree/export", nil)
 
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should return KitNotFound when kit does not exist", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		kitId := int64(9999)
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/kits/%d/export", kitId), nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, core.KitNotFound{KitID: kitId}.Error(), errorResponse(t, w).Message)
 	})
 }
 
@@ -414,209 +968,1042 @@ func Test_CreateKit(t *testing.T) {
 		assert.Equal(t, kitSchem, kit.Schematic)
 		assert.Equal(t, kitDiag, kit.Diagram)
 	})
-}
 
-func Test_DeleteKit(t *testing.T) {
-	t.Run("should delete kit", func(t *testing.T) {
+	t.Run("should return created kit with its parts and links", func(t *testing.T) {
 		router := CreateStubServer()
 		bundlerService = mock.StubBundlerService
 
-		kitId := int64(7777)
+		input := core.Kit{
+			Name: "my kit",
+			Parts: []core.KitPart{
+				{Part: mock.FakeParts[1], Quantity: 3},
+			},
+			Links: []core.Link{
+				{URL: "example.com/my-kit"},
+			},
+		}
+		inBytes, err := json.Marshal(input)
+
+		assert.Nil(t, err)
+
+		reqBody := bytes.NewReader(inBytes)
 
 		w := httptest.NewRecorder()
-		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/kits/%d", kitId), nil)
+		req, err := http.NewRequest(http.MethodPost, "/kits", reqBody)
 
 		assert.Nil(t, err)
 
 		router.ServeHTTP(w, req)
 
-		assert.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+		var kit core.Kit
+		err = json.Unmarshal(w.Body.Bytes(), &kit)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "my kit", kit.Name)
+		assert.Equal(t, []core.KitPart{{Part: mock.FakeParts[1], Quantity: 3}}, kit.Parts)
+		assert.Len(t, kit.Links, 1)
+		assert.Equal(t, "example.com/my-kit", kit.Links[0].URL)
 	})
-}
 
-func Test_AddKitLink(t *testing.T) {
-	t.Run("should add link", func(t *testing.T) {
+	t.Run("should return bad request if name is empty", func(t *testing.T) {
 		router := CreateStubServer()
 		bundlerService = mock.StubBundlerService
 
-		kitId := mock.FakeKits[0].ID
-		newLink := core.Link{
-			URL: "example.com/newlink",
-		}
+		reqBody := bytes.NewReader([]byte(`{"name": "", "links": [{"url": "ts808"}]}`))
 
-		buf, err := json.Marshal(newLink)
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, "/kits", reqBody)
 
 		assert.Nil(t, err)
 
-		reader := bytes.NewReader(buf)
-
-		w := httptest.NewRecorder()
-		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/kits/%d/links", kitId), reader)
-
 		router.ServeHTTP(w, req)
 
-		assert.Nil(t, err)
-
-		var link core.Link
-		err = json.Unmarshal(w.Body.Bytes(), &link)
+		apiErr := errorResponse(t, w)
 
-		assert.Nil(t, err)
-		assert.Equal(t, http.StatusOK, w.Code)
-		assert.Equal(t, newLink.URL, link.URL)
-		assert.Greater(t, link.ID, int64(0))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "invalid_request", apiErr.Code)
+		assert.Equal(t, []core.FieldError{
+			{Field: "name", Message: "must not be empty"},
+			{Field: "links[0].url", Message: "must include a host such as example.com"},
+		}, apiErr.Fields)
 	})
 
-	t.Run("should return BadRequest if body is invalid", func(t *testing.T) {
+	t.Run("should return 404 when a part does not exist", func(t *testing.T) {
 		router := CreateStubServer()
 		bundlerService = mock.StubBundlerService
 
-		kitId := mock.FakeKits[0].ID
+		var partId int64 = 9999
+		input := core.Kit{
+			Name: "my kit",
+			Parts: []core.KitPart{
+				{Part: core.Part{ID: partId}, Quantity: 1},
+			},
+		}
+		inBytes, err := json.Marshal(input)
 
-		reader := bytes.NewReader([]byte("{"))
+		assert.Nil(t, err)
+
+		reqBody := bytes.NewReader(inBytes)
 
 		w := httptest.NewRecorder()
-		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/kits/%d/links", kitId), reader)
+		req, err := http.NewRequest(http.MethodPost, "/kits", reqBody)
+
+		assert.Nil(t, err)
 
 		router.ServeHTTP(w, req)
 
-		assert.Nil(t, err)
-		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, core.PartNotFound{PartID: partId}.Error(), errorResponse(t, w).Message)
 	})
+}
 
-	t.Run("should return KitNotFound if kit does not exist", func(t *testing.T) {
+func Test_ImportKit(t *testing.T) {
+	t.Run("should import kit", func(t *testing.T) {
 		router := CreateStubServer()
 		bundlerService = mock.StubBundlerService
 
-		kitId := int64(9999)
+		input := core.KitSpec{
+			Name: "imported",
+			Parts: []core.KitPartSpec{
+				{Kind: core.Resistor, Name: "1000r", Quantity: 2},
+				{Kind: core.IC, Name: "TL072"},
+			},
+		}
 
-		reader := bytes.NewReader([]byte("{}"))
+		buf, err := json.Marshal(input)
+
+		assert.Nil(t, err)
 
 		w := httptest.NewRecorder()
-		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/kits/%d/links", kitId), reader)
+		req, err := http.NewRequest(http.MethodPost, "/kits/import", bytes.NewReader(buf))
 
 		assert.Nil(t, err)
 
 		router.ServeHTTP(w, req)
 
-		assert.Equal(t, http.StatusNotFound, w.Code)
-		assert.Equal(t, fmt.Sprintf("Kit %d not found", kitId), w.Body.String())
+		var result core.KitImport
+		err = json.Unmarshal(w.Body.Bytes(), &result)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, input.Name, result.Kit.Name)
+		assert.Len(t, result.Kit.Parts, 2)
+		assert.Equal(t, []core.Part{mock.FakeParts[0]}, result.Matched)
+		assert.Len(t, result.Created, 1)
+		assert.Equal(t, "TL072", result.Created[0].Name)
 	})
-}
 
-func Test_RemoveKitLink(t *testing.T) {
-	t.Run("should remove kit link", func(t *testing.T) {
+	t.Run("should return bad request if a part kind is invalid", func(t *testing.T) {
 		router := CreateStubServer()
 		bundlerService = mock.StubBundlerService
 
-		kit := mock.FakeKits[0]
-		link := kit.Links[0]
+		input := `{"name": "kit", "parts": [{"kind": "Flux Capacitor", "name": "1.21GW"}]}`
 
 		w := httptest.NewRecorder()
-		uri := fmt.Sprintf("/kits/%d/links/%d", kit.ID, link.ID)
-		req, err := http.NewRequest(http.MethodDelete, uri, nil)
+		req, err := http.NewRequest(http.MethodPost, "/kits/import", bytes.NewReader([]byte(input)))
 
 		assert.Nil(t, err)
 
 		router.ServeHTTP(w, req)
 
-		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, core.InvalidPartType{InvalidType: "Flux Capacitor"}.Error(), errorResponse(t, w).Message)
 	})
 }
 
-func Test_AddKitPart(t *testing.T) {
-	t.Run("should add part to kit", func(t *testing.T) {
+func Test_ImportKitBOM(t *testing.T) {
+	t.Run("should import kit", func(t *testing.T) {
 		router := CreateStubServer()
 		bundlerService = mock.StubBundlerService
 
-		kit := mock.FakeKits[0]
-		part := mock.FakeParts[0]
+		input := "Ref,Value,Type,Qty\nR1,1k,Res,1\nR2,1000r,Res,1\n"
 
 		w := httptest.NewRecorder()
-		uri := fmt.Sprintf("/kits/%d/parts/%d", kit.ID, part.ID)
-		req, err := http.NewRequest(http.MethodPost, uri, nil)
+		req, err := http.NewRequest(http.MethodPost, "/kits/import/bom?name=bom", strings.NewReader(input))
 
 		assert.Nil(t, err)
 
 		router.ServeHTTP(w, req)
 
-		var kitPart core.KitPart
-		err = json.Unmarshal(w.Body.Bytes(), &kitPart)
+		var result core.KitImport
+		err = json.Unmarshal(w.Body.Bytes(), &result)
 
 		assert.Nil(t, err)
 		assert.Equal(t, http.StatusOK, w.Code)
-		assert.Equal(t, part.ID, kitPart.ID)
-		assert.Equal(t, uint64(1), kitPart.Quantity)
+		assert.Equal(t, "bom", result.Kit.Name)
+		assert.Equal(t, []core.Part{mock.FakeParts[0]}, result.Matched)
 	})
 
-	t.Run("should use quantity value from query", func(t *testing.T) {
+	t.Run("should use column names from the query", func(t *testing.T) {
 		router := CreateStubServer()
 		bundlerService = mock.StubBundlerService
 
-		kit := mock.FakeKits[0]
-		part := mock.FakeParts[0]
-		quantity := uint64(7)
+		input := "Part,Category\n47pF,Cap\n"
 
 		w := httptest.NewRecorder()
-		uri := fmt.Sprintf("/kits/%d/parts/%d?quantity=%d", kit.ID, part.ID, quantity)
-		req, err := http.NewRequest(http.MethodPost, uri, nil)
+		req, err := http.NewRequest(http.MethodPost, "/kits/import/bom?name=bom&value=Part&kind=Category", strings.NewReader(input))
 
 		assert.Nil(t, err)
 
 		router.ServeHTTP(w, req)
 
-		var kitPart core.KitPart
-		err = json.Unmarshal(w.Body.Bytes(), &kitPart)
+		var result core.KitImport
+		err = json.Unmarshal(w.Body.Bytes(), &result)
 
 		assert.Nil(t, err)
 		assert.Equal(t, http.StatusOK, w.Code)
-		assert.Equal(t, part.ID, kitPart.ID)
-		assert.Equal(t, quantity, kitPart.Quantity)
+		assert.Equal(t, []core.Part{mock.FakeParts[1]}, result.Matched)
 	})
-}
 
-func Test_RemoveKitPart(t *testing.T) {
-	t.Run("should remove part from kit", func(t *testing.T) {
+	t.Run("should use kind aliases from the query", func(t *testing.T) {
 		router := CreateStubServer()
 		bundlerService = mock.StubBundlerService
 
-		kit := mock.FakeKits[0]
-		part := mock.FakeParts[0]
+		input := "Value,Type\n1k,Carbon Film\n"
 
 		w := httptest.NewRecorder()
-		uri := fmt.Sprintf("/kits/%d/parts/%d", kit.ID, part.ID)
-		req, err := http.NewRequest(http.MethodDelete, uri, nil)
+		uri := "/kits/import/bom?name=bom&kinds=" + url.QueryEscape("Carbon Film:Resistor")
+		req, err := http.NewRequest(http.MethodPost, uri, strings.NewReader(input))
 
 		assert.Nil(t, err)
 
 		router.ServeHTTP(w, req)
 
-		assert.Equal(t, http.StatusNoContent, w.Code)
+		var result core.KitImport
+		err = json.Unmarshal(w.Body.Bytes(), &result)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []core.Part{mock.FakeParts[0]}, result.Matched)
+	})
+
+	t.Run("should return bad request with invalid kind aliases", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, "/kits/import/bom?name=bom&kinds=Tube", strings.NewReader("Value,Type\n"))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, []core.FieldError{{Field: "kinds", Message: "'Tube' is not an alias:kind pair"}}, errorResponse(t, w).Fields)
+	})
+
+	t.Run("should return bad request with invalid lines", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		input := "Ref,Value,Type,Qty\nX1,1.21GW,Flux Capacitor,1\n"
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, "/kits/import/bom?name=bom", strings.NewReader(input))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		expected := bom.InvalidBOM{Lines: []bom.InvalidLine{{Line: 2, Reason: "unknown kind 'Flux Capacitor'"}}}
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, expected.Error(), errorResponse(t, w).Message)
 	})
 }
 
-func Test_UpdatePartQuantity(t *testing.T) {
-	t.Run("should update part kit quantity", func(t *testing.T) {
+func Test_UpdateKit(t *testing.T) {
+	t.Run("should replace kit", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		existing := mock.FakeKits[0]
+		body := `{"name": "Renamed", "schematics": "example.com/new-schematic"}`
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/kits/%d", existing.ID), strings.NewReader(body))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		var kit core.Kit
+		err = json.Unmarshal(w.Body.Bytes(), &kit)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "Renamed", kit.Name)
+		assert.Equal(t, "example.com/new-schematic", kit.Schematic)
+		assert.Equal(t, "", kit.Diagram)
+	})
+
+	t.Run("should return not found if kit does not exist", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		var kitId int64 = 9999
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/kits/%d", kitId), strings.NewReader(`{"name": "Renamed"}`))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, core.KitNotFound{KitID: kitId}.Error(), errorResponse(t, w).Message)
+	})
+}
+
+func Test_PatchKit(t *testing.T) {
+	t.Run("should change only the given fields", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		existing := mock.FakeKits[0]
+		body := `{"diagram": "example.com/new-diagram"}`
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/kits/%d", existing.ID), strings.NewReader(body))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		var kit core.Kit
+		err = json.Unmarshal(w.Body.Bytes(), &kit)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, existing.Name, kit.Name)
+		assert.Equal(t, existing.Schematic, kit.Schematic)
+		assert.Equal(t, "example.com/new-diagram", kit.Diagram)
+	})
+}
+
+func Test_DeleteKit(t *testing.T) {
+	t.Run("should delete kit", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		kitId := int64(7777)
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/kits/%d", kitId), nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+	})
+}
+
+func Test_AddKitLink(t *testing.T) {
+	t.Run("should add link", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		kitId := mock.FakeKits[0].ID
+		newLink := core.Link{
+			URL: "example.com/newlink",
+		}
+
+		buf, err := json.Marshal(newLink)
+
+		assert.Nil(t, err)
+
+		reader := bytes.NewReader(buf)
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/kits/%d/links", kitId), reader)
+
+		router.ServeHTTP(w, req)
+
+		assert.Nil(t, err)
+
+		var link core.Link
+		err = json.Unmarshal(w.Body.Bytes(), &link)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, newLink.URL, link.URL)
+		assert.Greater(t, link.ID, int64(0))
+	})
+
+	t.Run("should return BadRequest if body is invalid", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		kitId := mock.FakeKits[0].ID
+
+		reader := bytes.NewReader([]byte("{"))
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/kits/%d/links", kitId), reader)
+
+		router.ServeHTTP(w, req)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should return KitNotFound if kit does not exist", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		kitId := int64(9999)
+
+		reader := bytes.NewReader([]byte("{}"))
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/kits/%d/links", kitId), reader)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, fmt.Sprintf("Kit %d not found", kitId), errorResponse(t, w).Message)
+	})
+}
+
+func Test_RemoveKitLink(t *testing.T) {
+	t.Run("should remove kit link", func(t *testing.T) {
 		router := CreateStubServer()
 		bundlerService = mock.StubBundlerService
 
 		kit := mock.FakeKits[0]
-		part := kit.Parts[0]
-		newQty := part.Quantity * 2
+		link := kit.Links[0]
 
 		w := httptest.NewRecorder()
-		uri := fmt.Sprintf("/kits/%d/parts/%d/%d", kit.ID, part.ID, newQty)
-		req, err := http.NewRequest(http.MethodPut, uri, nil)
+		uri := fmt.Sprintf("/kits/%d/links/%d", kit.ID, link.ID)
+		req, err := http.NewRequest(http.MethodDelete, uri, nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("should return bad request when link id is not positive", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		kit := mock.FakeKits[0]
+
+		w := httptest.NewRecorder()
+		uri := fmt.Sprintf("/kits/%d/links/%d", kit.ID, 0)
+		req, err := http.NewRequest(http.MethodDelete, uri, nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, []core.FieldError{{Field: "linkId", Message: "must be greater than zero"}}, errorResponse(t, w).Fields)
+	})
+
+	t.Run("should return KitLinkNotFound when link does not exist", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		kit := mock.FakeKits[0]
+		linkId := int64(999)
+
+		w := httptest.NewRecorder()
+		uri := fmt.Sprintf("/kits/%d/links/%d", kit.ID, linkId)
+		req, err := http.NewRequest(http.MethodDelete, uri, nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		actual := errorResponse(t, w)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "kit_link_not_found", actual.Code)
+		assert.Equal(t, fmt.Sprintf("Link %d not found on Kit %d", linkId, kit.ID), actual.Message)
+		assert.Equal(t, map[string]interface{}{"linkId": float64(linkId), "kitId": float64(kit.ID)}, actual.Details)
+	})
+}
+
+func Test_AddKitPart(t *testing.T) {
+	t.Run("should add part to kit", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		kit := mock.FakeKits[0]
+		part := mock.FakeParts[1]
+
+		w := httptest.NewRecorder()
+		uri := fmt.Sprintf("/kits/%d/parts/%d", kit.ID, part.ID)
+		req, err := http.NewRequest(http.MethodPost, uri, nil)
 
 		assert.Nil(t, err)
 
 		router.ServeHTTP(w, req)
 
+		var kitPart core.KitPart
+		err = json.Unmarshal(w.Body.Bytes(), &kitPart)
+
+		assert.Nil(t, err)
 		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, part.ID, kitPart.ID)
+		assert.Equal(t, uint64(1), kitPart.Quantity)
+	})
 
-		kitPart := core.KitPart{}
+	t.Run("should use quantity value from query", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		kit := mock.FakeKits[0]
+		part := mock.FakeParts[1]
+		quantity := uint64(7)
+
+		w := httptest.NewRecorder()
+		uri := fmt.Sprintf("/kits/%d/parts/%d?quantity=%d", kit.ID, part.ID, quantity)
+		req, err := http.NewRequest(http.MethodPost, uri, nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		var kitPart core.KitPart
 		err = json.Unmarshal(w.Body.Bytes(), &kitPart)
 
 		assert.Nil(t, err)
-		assert.Equal(t, newQty, kitPart.Quantity)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, part.ID, kitPart.ID)
+		assert.Equal(t, quantity, kitPart.Quantity)
+	})
+
+	t.Run("should return conflict if part is already in kit", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		kit := mock.FakeKits[0]
+		part := kit.Parts[0]
+
+		w := httptest.NewRecorder()
+		uri := fmt.Sprintf("/kits/%d/parts/%d", kit.ID, part.ID)
+		req, err := http.NewRequest(http.MethodPost, uri, nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, core.PartAlreadyInKit{KitID: kit.ID, PartID: part.ID}.Error(), errorResponse(t, w).Message)
+	})
+	t.Run("should return bad request if quantity is malformed", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		kit := mock.FakeKits[0]
+		part := mock.FakeParts[1]
+
+		for _, quantity := range []string{"abc", "-2", "0"} {
+			w := httptest.NewRecorder()
+			uri := fmt.Sprintf("/kits/%d/parts/%d?quantity=%s", kit.ID, part.ID, quantity)
+			req, err := http.NewRequest(http.MethodPost, uri, nil)
+
+			assert.Nil(t, err)
+
+			router.ServeHTTP(w, req)
+
+			apiErr := errorResponse(t, w)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, quantity)
+			assert.Equal(t, "invalid_request", apiErr.Code, quantity)
+			assert.Equal(t, "quantity", apiErr.Fields[0].Field, quantity)
+		}
+	})
+}
+
+func Test_RemoveKitPart(t *testing.T) {
+	t.Run("should remove part from kit", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		kit := mock.FakeKits[0]
+		part := mock.FakeParts[0]
+
+		w := httptest.NewRecorder()
+		uri := fmt.Sprintf("/kits/%d/parts/%d", kit.ID, part.ID)
+		req, err := http.NewRequest(http.MethodDelete, uri, nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("should return not found when the kit does not exist", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		kitId := int64(999)
+		part := mock.FakeParts[0]
+
+		w := httptest.NewRecorder()
+		uri := fmt.Sprintf("/kits/%d/parts/%d", kitId, part.ID)
+		req, err := http.NewRequest(http.MethodDelete, uri, nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		actual := errorResponse(t, w)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "kit_not_found", actual.Code)
+		assert.Equal(t, map[string]interface{}{"kitId": float64(kitId)}, actual.Details)
+	})
+
+	t.Run("should return not found when the part is not in the kit", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		kit := mock.FakeKits[0]
+		part := mock.FakeParts[1]
+
+		w := httptest.NewRecorder()
+		uri := fmt.Sprintf("/kits/%d/parts/%d", kit.ID, part.ID)
+		req, err := http.NewRequest(http.MethodDelete, uri, nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "part_not_in_kit", errorResponse(t, w).Code)
+	})
+}
+
+func Test_UpdatePartQuantity(t *testing.T) {
+	t.Run("should update part kit quantity", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		kit := mock.FakeKits[0]
+		part := kit.Parts[0]
+		newQty := part.Quantity * 2
+
+		w := httptest.NewRecorder()
+		uri := fmt.Sprintf("/kits/%d/parts/%d/%d", kit.ID, part.ID, newQty)
+		req, err := http.NewRequest(http.MethodPut, uri, nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		kitPart := core.KitPart{}
+		err = json.Unmarshal(w.Body.Bytes(), &kitPart)
+
+		assert.Nil(t, err)
+		assert.Equal(t, newQty, kitPart.Quantity)
+	})
+
+	t.Run("should return the designators of the kit part", func(t *testing.T) {
+		router := CreateStubServer()
+		svc := memory.CreateMemoryService()
+		bundlerService = svc
+
+		part, _ := svc.Parts.New("10k", core.Resistor)
+		kit, _ := svc.Kits.New("Fuzz", "", "")
+		svc.Kits.AddPart(kit.ID, part.ID, 1)
+		svc.Kits.SetPartDesignators(kit.ID, part.ID, []string{"R1", "R2"})
+
+		w := httptest.NewRecorder()
+		uri := fmt.Sprintf("/kits/%d/parts/%d/%d", kit.ID, part.ID, 2)
+		req, err := http.NewRequest(http.MethodPut, uri, nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		kitPart := core.KitPart{}
+		err = json.Unmarshal(w.Body.Bytes(), &kitPart)
+
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(2), kitPart.Quantity)
+		assert.Equal(t, []string{"R1", "R2"}, kitPart.Designators)
+	})
+}
+
+/////////////////////////
+// Plan Tests
+
+func Test_SetKitPartDesignators(t *testing.T) {
+	t.Run("should set designators", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		kit := mock.FakeKits[0]
+		partId := kit.Parts[0].ID
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPut,
+			fmt.Sprintf("/kits/%d/parts/%d/designators", kit.ID, partId),
+			strings.NewReader(`["r1", "R7"]`))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		var kitPart core.KitPart
+		err = json.Unmarshal(w.Body.Bytes(), &kitPart)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"R1", "R7"}, kitPart.Designators)
+		assert.Equal(t, uint64(2), kitPart.Quantity)
+	})
+
+	t.Run("should return not found if part is not in kit", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		kitId := mock.FakeKits[0].ID
+		partId := int64(9999)
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPut,
+			fmt.Sprintf("/kits/%d/parts/%d/designators", kitId, partId),
+			strings.NewReader(`["R1"]`))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, core.PartNotInKit{KitID: kitId, PartID: partId}.Error(), errorResponse(t, w).Message)
+	})
+
+	t.Run("should return bad request if body is not a list", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPut, "/kits/1/parts/1/designators", strings.NewReader(`"R1"`))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func Test_CreatePlan(t *testing.T) {
+	t.Run("should return plan", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		kit := mock.FakeKits[0]
+		kitPart := kit.Parts[0]
+		input := core.PlanRequest{
+			Builds: []core.KitBuild{{KitID: kit.ID, Count: 4}},
+			OnHand: map[int64]uint64{kitPart.ID: 1},
+		}
+
+		buf, err := json.Marshal(input)
+
+		assert.Nil(t, err)
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, "/plans", bytes.NewReader(buf))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		var plan core.Plan
+		err = json.Unmarshal(w.Body.Bytes(), &plan)
+
+		expected := []core.PlanItem{
+			{
+				Part:      kitPart.Part,
+				Required:  kitPart.Quantity * 4,
+				OnHand:    1,
+				Shortfall: kitPart.Quantity*4 - 1,
+			},
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, input.Builds, plan.Builds)
+		assert.Equal(t, expected, plan.Items)
+	})
+
+	t.Run("should return bad request if there are no builds", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, "/plans", bytes.NewReader([]byte("{}")))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, []core.FieldError{{Field: "builds", Message: "must not be empty"}}, errorResponse(t, w).Fields)
+	})
+
+	t.Run("should return bad request if a build has no count", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		body := fmt.Sprintf(`{"builds": [{"kitId": %d}]}`, mock.FakeKits[0].ID)
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, "/plans", bytes.NewReader([]byte(body)))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, []core.FieldError{{Field: "builds[0].count", Message: "must be greater than zero"}}, errorResponse(t, w).Fields)
+	})
+
+	t.Run("should return bad request if a build count overflows", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		body := fmt.Sprintf(`{"builds": [{"kitId": %d, "count": %d}]}`, mock.FakeKits[0].ID, uint64(math.MaxUint64))
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, "/plans", bytes.NewReader([]byte(body)))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, []core.FieldError{{Field: "builds[0].count", Message: "requires more parts than can be counted"}}, errorResponse(t, w).Fields)
+	})
+
+	t.Run("should return KitNotFound when kit does not exist", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		kitId := int64(9999)
+		input := core.PlanRequest{
+			Builds: []core.KitBuild{{KitID: kitId, Count: 1}},
+		}
+
+		buf, err := json.Marshal(input)
+
+		assert.Nil(t, err)
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, "/plans", bytes.NewReader(buf))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, core.KitNotFound{KitID: kitId}.Error(), errorResponse(t, w).Message)
+	})
+}
+
+/////////////////////////
+// Inventory Tests
+
+func Test_GetAllStock(t *testing.T) {
+	t.Run("should return stock", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, "/inventory", nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		var stock []core.Stock
+		err = json.Unmarshal(w.Body.Bytes(), &stock)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, mock.FakeStock[:], stock)
+	})
+}
+
+func Test_GetStock(t *testing.T) {
+	t.Run("should return stock for part", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		expected := mock.FakeStock[0]
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/inventory/%d", expected.PartID), nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		var stock core.Stock
+		err = json.Unmarshal(w.Body.Bytes(), &stock)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, expected, stock)
+	})
+
+	t.Run("should return PartNotFound when part does not exist", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		partId := int64(9999)
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/inventory/%d", partId), nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, core.PartNotFound{PartID: partId}.Error(), errorResponse(t, w).Message)
+	})
+}
+
+func Test_SetStockLocation(t *testing.T) {
+	t.Run("should set location", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		partId := mock.FakeStock[0].PartID
+		location := "bin 7"
+
+		buf, err := json.Marshal(core.Stock{Location: location})
+
+		assert.Nil(t, err)
+
+		w := httptest.NewRecorder()
+		uri := fmt.Sprintf("/inventory/%d/location", partId)
+		req, err := http.NewRequest(http.MethodPut, uri, bytes.NewReader(buf))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		var stock core.Stock
+		err = json.Unmarshal(w.Body.Bytes(), &stock)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, location, stock.Location)
+	})
+}
+
+func Test_GetStockHistory(t *testing.T) {
+	t.Run("should return history", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		partId := mock.FakeStock[0].PartID
+
+		w := httptest.NewRecorder()
+		uri := fmt.Sprintf("/inventory/%d/adjustments", partId)
+		req, err := http.NewRequest(http.MethodGet, uri, nil)
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		var history []core.StockAdjustment
+		err = json.Unmarshal(w.Body.Bytes(), &history)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Len(t, history, 0)
+	})
+}
+
+func Test_AdjustStock(t *testing.T) {
+	t.Run("should adjust stock", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		stock := mock.FakeStock[0]
+		input := core.StockAdjustment{Kind: core.Received, Quantity: 5}
+
+		buf, err := json.Marshal(input)
+
+		assert.Nil(t, err)
+
+		w := httptest.NewRecorder()
+		uri := fmt.Sprintf("/inventory/%d/adjustments", stock.PartID)
+		req, err := http.NewRequest(http.MethodPost, uri, bytes.NewReader(buf))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		var actual core.Stock
+		err = json.Unmarshal(w.Body.Bytes(), &actual)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, stock.Quantity+5, actual.Quantity)
+	})
+
+	t.Run("should return bad request if kind is invalid", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		stock := mock.FakeStock[0]
+		input := core.StockAdjustment{Kind: "borrowed", Quantity: 5}
+
+		buf, err := json.Marshal(input)
+
+		assert.Nil(t, err)
+
+		w := httptest.NewRecorder()
+		uri := fmt.Sprintf("/inventory/%d/adjustments", stock.PartID)
+		req, err := http.NewRequest(http.MethodPost, uri, bytes.NewReader(buf))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should return conflict when stock is insufficient", func(t *testing.T) {
+		router := CreateStubServer()
+		bundlerService = mock.StubBundlerService
+
+		stock := mock.FakeStock[0]
+		input := core.StockAdjustment{Kind: core.Consumed, Quantity: stock.Quantity + 1}
+
+		buf, err := json.Marshal(input)
+
+		assert.Nil(t, err)
+
+		w := httptest.NewRecorder()
+		uri := fmt.Sprintf("/inventory/%d/adjustments", stock.PartID)
+		req, err := http.NewRequest(http.MethodPost, uri, bytes.NewReader(buf))
+
+		assert.Nil(t, err)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
 	})
 }
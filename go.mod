@@ -1,6 +1,6 @@
 module github.com/sombrerosheep/partsbundler
 
-go 1.15
+go 1.16
 
 require (
 	github.com/gin-gonic/gin v1.8.1
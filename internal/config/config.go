@@ -0,0 +1,252 @@
+// Package config loads the settings shared by bundler-server and
+// bundler-repl from defaults, an optional JSON config file, environment
+// variables and command-line flags, in increasing order of precedence.
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix prefixes every environment variable read by Load.
+const EnvPrefix = "PARTSBUNDLER_"
+
+// Log levels accepted by Config.LogLevel.
+const (
+	LogDebug = "debug"
+	LogInfo  = "info"
+	LogWarn  = "warn"
+	LogError = "error"
+)
+
+var logLevels = []string{LogDebug, LogInfo, LogWarn, LogError}
+
+// Gin modes accepted by Config.GinMode. They match gin.DebugMode,
+// gin.ReleaseMode and gin.TestMode.
+var ginModes = []string{"debug", "release", "test"}
+
+type InvalidConfig struct {
+	Setting string
+	Reason  string
+}
+
+func (c InvalidConfig) Error() string {
+	return fmt.Sprintf("Invalid config %s: %s", c.Setting, c.Reason)
+}
+
+type Config struct {
+	DBPath      string   `json:"db_path"`
+	Server      string   `json:"server"`
+	Memory      bool     `json:"memory"`
+	Listen      string   `json:"listen"`
+	LogLevel    string   `json:"log_level"`
+	GinMode     string   `json:"gin_mode"`
+	CORSOrigins []string `json:"cors_origins"`
+}
+
+// Default returns the settings used when nothing else is configured.
+func Default() Config {
+	return Config{
+		DBPath:      "data/partsbundler.db",
+		Listen:      ":3000",
+		LogLevel:    LogInfo,
+		GinMode:     "release",
+		CORSOrigins: []string{},
+	}
+}
+
+// LogEnabled reports whether messages at level should be logged.
+func (c Config) LogEnabled(level string) bool {
+	return indexOf(logLevels, level) >= indexOf(logLevels, c.LogLevel)
+}
+
+// Validate reports the first setting that cannot be used.
+func (c Config) Validate() error {
+	if strings.TrimSpace(c.DBPath) == "" {
+		return InvalidConfig{"db_path", "must not be empty"}
+	}
+
+	if c.Server != "" && !strings.HasPrefix(c.Server, "http://") && !strings.HasPrefix(c.Server, "https://") {
+		return InvalidConfig{"server", fmt.Sprintf("'%s' is not an http(s) URL", c.Server)}
+	}
+
+	if c.Memory && c.Server != "" {
+		return InvalidConfig{"memory", "cannot be used with server"}
+	}
+
+	if strings.TrimSpace(c.Listen) == "" {
+		return InvalidConfig{"listen", "must not be empty"}
+	}
+
+	if indexOf(logLevels, c.LogLevel) < 0 {
+		return InvalidConfig{"log_level", fmt.Sprintf("'%s' is not one of %s", c.LogLevel, strings.Join(logLevels, ", "))}
+	}
+
+	if indexOf(ginModes, c.GinMode) < 0 {
+		return InvalidConfig{"gin_mode", fmt.Sprintf("'%s' is not one of %s", c.GinMode, strings.Join(ginModes, ", "))}
+	}
+
+	for _, origin := range c.CORSOrigins {
+		if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			return InvalidConfig{"cors_origins", fmt.Sprintf("'%s' is not * or an http(s) origin", origin)}
+		}
+	}
+
+	return nil
+}
+
+// Load builds the config for the program name from args, usually
+// os.Args[1:], and getenv, usually os.Getenv. A config file is read
+// when named by -config or PARTSBUNDLER_CONFIG.
+func Load(name string, args []string, getenv func(string) string) (Config, error) {
+	var (
+		configPath  string
+		flagConfig  Config
+		corsOrigins string
+		memory      bool
+	)
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&configPath, "config", "", "path to a JSON config file (env "+EnvPrefix+"CONFIG)")
+	fs.StringVar(&flagConfig.DBPath, "db", "", "path to the sqlite database (env "+EnvPrefix+"DB_PATH)")
+	fs.StringVar(&flagConfig.Server, "server", "", "URL of a bundler-server the REPL uses instead of the database (env "+EnvPrefix+"SERVER)")
+	fs.BoolVar(&memory, "memory", false, "keep everything in memory instead of the database, losing it on exit (env "+EnvPrefix+"MEMORY)")
+	fs.StringVar(&flagConfig.Listen, "listen", "", "address the server listens on (env "+EnvPrefix+"LISTEN)")
+	fs.StringVar(&flagConfig.LogLevel, "log-level", "", "one of debug, info, warn, error (env "+EnvPrefix+"LOG_LEVEL)")
+	fs.StringVar(&flagConfig.GinMode, "gin-mode", "", "one of debug, release, test (env "+EnvPrefix+"GIN_MODE)")
+	fs.StringVar(&corsOrigins, "cors-origins", "", "comma separated origins allowed by CORS (env "+EnvPrefix+"CORS_ORIGINS)")
+
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	if fs.NArg() > 0 {
+		return Config{}, InvalidConfig{"arguments", fmt.Sprintf("unexpected '%s'", strings.Join(fs.Args(), " "))}
+	}
+
+	cfg := Default()
+
+	if configPath == "" {
+		configPath = getenv(EnvPrefix + "CONFIG")
+	}
+
+	if configPath != "" {
+		if err := cfg.readFile(configPath); err != nil {
+			return Config{}, err
+		}
+	}
+
+	envConfig := Config{
+		DBPath:   getenv(EnvPrefix + "DB_PATH"),
+		Server:   getenv(EnvPrefix + "SERVER"),
+		Listen:   getenv(EnvPrefix + "LISTEN"),
+		LogLevel: getenv(EnvPrefix + "LOG_LEVEL"),
+		GinMode:  getenv(EnvPrefix + "GIN_MODE"),
+	}
+	if origins := getenv(EnvPrefix + "CORS_ORIGINS"); origins != "" {
+		envConfig.CORSOrigins = splitList(origins)
+	}
+
+	cfg.merge(envConfig)
+
+	// Memory is a bool, so env and flags set it directly rather than
+	// through merge, letting them turn it off as well as on.
+	if env := getenv(EnvPrefix + "MEMORY"); env != "" {
+		m, err := strconv.ParseBool(env)
+		if err != nil {
+			return Config{}, InvalidConfig{"memory", fmt.Sprintf("'%s' is not a boolean", env)}
+		}
+
+		cfg.Memory = m
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "cors-origins":
+			flagConfig.CORSOrigins = splitList(corsOrigins)
+		case "memory":
+			cfg.Memory = memory
+		}
+	})
+
+	cfg.merge(flagConfig)
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var file Config
+	if err := json.Unmarshal(data, &file); err != nil {
+		return InvalidConfig{path, err.Error()}
+	}
+
+	c.merge(file)
+
+	return nil
+}
+
+// merge overwrites the settings of c that are set in o.
+func (c *Config) merge(o Config) {
+	if o.DBPath != "" {
+		c.DBPath = o.DBPath
+	}
+
+	if o.Server != "" {
+		c.Server = o.Server
+	}
+
+	if o.Memory {
+		c.Memory = true
+	}
+
+	if o.Listen != "" {
+		c.Listen = o.Listen
+	}
+
+	if o.LogLevel != "" {
+		c.LogLevel = strings.ToLower(o.LogLevel)
+	}
+
+	if o.GinMode != "" {
+		c.GinMode = strings.ToLower(o.GinMode)
+	}
+
+	if o.CORSOrigins != nil {
+		c.CORSOrigins = o.CORSOrigins
+	}
+}
+
+func splitList(s string) []string {
+	list := []string{}
+
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+
+	return -1
+}
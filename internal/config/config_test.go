@@ -0,0 +1,155 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(key string) string {
+		return vars[key]
+	}
+}
+
+func writeConfigFile(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "partsbundler.json")
+
+	err := os.WriteFile(path, []byte(contents), 0600)
+	assert.Nil(t, err)
+
+	return path
+}
+
+func Test_Load(t *testing.T) {
+	t.Run("should return defaults when nothing is configured", func(t *testing.T) {
+		cfg, err := Load("test", []string{}, env(nil))
+
+		assert.Nil(t, err)
+		assert.Equal(t, Default(), cfg)
+	})
+
+	t.Run("should prefer flags over env over the config file", func(t *testing.T) {
+		path := writeConfigFile(t, `{
+			"db_path": "file.db",
+			"listen": ":4000",
+			"log_level": "warn",
+			"gin_mode": "debug",
+			"cors_origins": ["https://file.example.com"]
+		}`)
+
+		vars := map[string]string{
+			"PARTSBUNDLER_CONFIG":       path,
+			"PARTSBUNDLER_LISTEN":       ":5000",
+			"PARTSBUNDLER_SERVER":       "http://env.example.com:3000",
+			"PARTSBUNDLER_LOG_LEVEL":    "ERROR",
+			"PARTSBUNDLER_CORS_ORIGINS": "https://env.example.com, http://localhost:8080",
+		}
+		args := []string{"-listen", "127.0.0.1:6000"}
+
+		cfg, err := Load("test", args, env(vars))
+
+		assert.Nil(t, err)
+		assert.Equal(t, Config{
+			DBPath:      "file.db",
+			Server:      "http://env.example.com:3000",
+			Listen:      "127.0.0.1:6000",
+			LogLevel:    LogError,
+			GinMode:     "debug",
+			CORSOrigins: []string{"https://env.example.com", "http://localhost:8080"},
+		}, cfg)
+	})
+
+	t.Run("should read the config file named by the flag", func(t *testing.T) {
+		path := writeConfigFile(t, `{"db_path": "flag.db"}`)
+		vars := map[string]string{"PARTSBUNDLER_CONFIG": "missing.json"}
+
+		cfg, err := Load("test", []string{"-config", path}, env(vars))
+
+		assert.Nil(t, err)
+		assert.Equal(t, "flag.db", cfg.DBPath)
+	})
+
+	t.Run("should clear cors origins with an empty flag", func(t *testing.T) {
+		vars := map[string]string{"PARTSBUNDLER_CORS_ORIGINS": "*"}
+
+		cfg, err := Load("test", []string{"-cors-origins", ""}, env(vars))
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{}, cfg.CORSOrigins)
+	})
+
+	t.Run("should let the memory flag override env and the config file", func(t *testing.T) {
+		path := writeConfigFile(t, `{"memory": true}`)
+
+		cfg, err := Load("test", []string{"-config", path}, env(nil))
+		assert.Nil(t, err)
+		assert.True(t, cfg.Memory)
+
+		cfg, err = Load("test", []string{"-config", path}, env(map[string]string{"PARTSBUNDLER_MEMORY": "false"}))
+		assert.Nil(t, err)
+		assert.False(t, cfg.Memory)
+
+		cfg, err = Load("test", []string{"-memory"}, env(map[string]string{"PARTSBUNDLER_MEMORY": "0"}))
+		assert.Nil(t, err)
+		assert.True(t, cfg.Memory)
+
+		cfg, err = Load("test", []string{"-memory=false"}, env(map[string]string{"PARTSBUNDLER_MEMORY": "true"}))
+		assert.Nil(t, err)
+		assert.False(t, cfg.Memory)
+	})
+
+	t.Run("should return an error when the config file is missing", func(t *testing.T) {
+		_, err := Load("test", []string{"-config", filepath.Join(t.TempDir(), "missing.json")}, env(nil))
+
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("should return an error when the config file is malformed", func(t *testing.T) {
+		path := writeConfigFile(t, `{"listen": 3000}`)
+
+		_, err := Load("test", []string{"-config", path}, env(nil))
+
+		assert.IsType(t, InvalidConfig{}, err)
+	})
+
+	t.Run("should return an error for invalid settings", func(t *testing.T) {
+		tests := []struct {
+			args    []string
+			setting string
+		}{
+			{[]string{"-db", " "}, "db_path"},
+			{[]string{"-server", "localhost:3000"}, "server"},
+			{[]string{"-memory", "-server", "http://localhost:3000"}, "memory"},
+			{[]string{"-log-level", "loud"}, "log_level"},
+			{[]string{"-gin-mode", "prod"}, "gin_mode"},
+			{[]string{"-cors-origins", "example.com"}, "cors_origins"},
+			{[]string{"extra"}, "arguments"},
+		}
+
+		for _, test := range tests {
+			_, err := Load("test", test.args, env(nil))
+
+			if assert.IsType(t, InvalidConfig{}, err, test.args) {
+				assert.Equal(t, test.setting, err.(InvalidConfig).Setting)
+			}
+		}
+	})
+
+	t.Run("should return an error for an invalid memory env", func(t *testing.T) {
+		_, err := Load("test", []string{}, env(map[string]string{"PARTSBUNDLER_MEMORY": "maybe"}))
+
+		assert.Equal(t, InvalidConfig{"memory", "'maybe' is not a boolean"}, err)
+	})
+}
+
+func Test_Config_LogEnabled(t *testing.T) {
+	cfg := Config{LogLevel: LogWarn}
+
+	assert.False(t, cfg.LogEnabled(LogDebug))
+	assert.False(t, cfg.LogEnabled(LogInfo))
+	assert.True(t, cfg.LogEnabled(LogWarn))
+	assert.True(t, cfg.LogEnabled(LogError))
+}
@@ -0,0 +1,235 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingdbtx counts the statements run against the wrapped dbtx.
+type countingdbtx struct {
+	dbtx
+	count int
+}
+
+func (c *countingdbtx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	c.count++
+	return c.dbtx.ExecContext(ctx, query, args...)
+}
+
+func (c *countingdbtx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	c.count++
+	return c.dbtx.QueryContext(ctx, query, args...)
+}
+
+func (c *countingdbtx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	c.count++
+	return c.dbtx.QueryRowContext(ctx, query, args...)
+}
+
+// seedCatalog fills db with a catalog of kits, each using partsPerKit
+// parts from a shared pool, with links on every kit and part.
+func seedCatalog(tb testing.TB, db *sqlitedb, kits, partsPerKit int) {
+	tb.Helper()
+
+	err := db.withTx(func(tx sqlitedb) error {
+		partIds := make([]int64, partsPerKit*2)
+
+		for i := range partIds {
+			name := fmt.Sprintf("%dk", i+1)
+
+			id, err := tx.CreatePart(name, name, core.Resistor)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.AddLinkToPart(fmt.Sprintf("example.com/parts/%d", id), id)
+			if err != nil {
+				return err
+			}
+
+			partIds[i] = id
+		}
+
+		for k := 0; k < kits; k++ {
+			kitId, err := tx.CreateKit(fmt.Sprintf("kit %d", k), "", "")
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.AddLinkToKit(fmt.Sprintf("example.com/kits/%d", kitId), kitId)
+			if err != nil {
+				return err
+			}
+
+			for p := 0; p < partsPerKit; p++ {
+				partId := partIds[(k+p)%len(partIds)]
+
+				err = tx.AddPartToKit(partId, kitId, uint64(p+1))
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		tb.Fatalf("Error seeding catalog: %s", err)
+	}
+}
+
+func countQueries(db *sqlitedb, fn func(svc SqliteKitService) error) (int, error) {
+	counter := &countingdbtx{dbtx: db.db}
+
+	counted := *db
+	counted.db = counter
+
+	svc := SqliteKitService{
+		db:          &counted,
+		partservice: SqlitePartService{db: &counted},
+	}
+
+	err := fn(svc)
+
+	return counter.count, err
+}
+
+func Test_SqliteCatalogQueryCount(t *testing.T) {
+	const dbPath = "./import/dbcatalogtest.db"
+	testdb, err := getTestDbConnection(t, dbPath)
+	if err != nil {
+		t.Fatalf("Error connecting to test db (%s): %s", dbPath, err)
+	}
+	defer testDbDeferredCleanup(t, testdb, dbPath)
+
+	getAll := func(svc SqliteKitService) error {
+		kits, err := svc.GetAll()
+		if err != nil {
+			return err
+		}
+
+		for _, kit := range kits {
+			if len(kit.Parts) != 5 || len(kit.Links) != 1 {
+				return fmt.Errorf("kit %d loaded %d parts and %d links", kit.ID, len(kit.Parts), len(kit.Links))
+			}
+
+			for _, kp := range kit.Parts {
+				if len(kp.Links) != 1 {
+					return fmt.Errorf("part %d loaded %d links", kp.ID, len(kp.Links))
+				}
+			}
+		}
+
+		return nil
+	}
+
+	seedCatalog(t, testdb, 2, 5)
+
+	small, err := countQueries(testdb, getAll)
+
+	assert.Nil(t, err)
+
+	seedCatalog(t, testdb, 50, 5)
+
+	large, err := countQueries(testdb, getAll)
+
+	assert.Nil(t, err)
+	assert.Equal(t, small, large, "GetAll should not run more queries as the catalog grows")
+
+	t.Run("Get should not run a query per part", func(t *testing.T) {
+		get := func(kitId int64) func(svc SqliteKitService) error {
+			return func(svc SqliteKitService) error {
+				_, err := svc.Get(kitId)
+				return err
+			}
+		}
+
+		small, err := countQueries(testdb, get(1))
+
+		assert.Nil(t, err)
+
+		seedCatalog(t, testdb, 1, 100)
+
+		kits, err := testdb.GetAllKits()
+
+		assert.Nil(t, err)
+
+		large, err := countQueries(testdb, get(kits[len(kits)-1].ID))
+
+		assert.Nil(t, err)
+		assert.Equal(t, small, large)
+	})
+}
+
+func benchmarkCatalog(b *testing.B, name string, kits, partsPerKit int) *sqlitedb {
+	dbPath := fmt.Sprintf("./import/dbbench%s.db", name)
+	testdb, err := getTestDbConnection(b, dbPath)
+	if err != nil {
+		b.Fatalf("Error connecting to bench db (%s): %s", dbPath, err)
+	}
+	b.Cleanup(func() { testDbDeferredCleanup(b, testdb, dbPath) })
+
+	seedCatalog(b, testdb, kits, partsPerKit)
+
+	return testdb
+}
+
+func Benchmark_SqliteKitServiceGetAll(b *testing.B) {
+	testdb := benchmarkCatalog(b, "kitgetall", 200, 25)
+	svc := SqliteKitService{
+		db:          testdb,
+		partservice: SqlitePartService{db: testdb},
+	}
+
+	queries, err := countQueries(testdb, func(svc SqliteKitService) error {
+		_, err := svc.GetAll()
+		return err
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := svc.GetAll()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ReportMetric(float64(queries), "queries/op")
+}
+
+func Benchmark_SqliteKitServiceGet(b *testing.B) {
+	testdb := benchmarkCatalog(b, "kitget", 1, 100)
+	svc := SqliteKitService{
+		db:          testdb,
+		partservice: SqlitePartService{db: testdb},
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := svc.Get(1)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_SqlitePartServiceGetAll(b *testing.B) {
+	testdb := benchmarkCatalog(b, "partgetall", 1, 2500)
+	svc := SqlitePartService{db: testdb}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := svc.GetAll()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/sombrerosheep/partsbundler/pkg/service"
+	"github.com/sombrerosheep/partsbundler/pkg/service/servicetest"
+)
+
+func Test_SqliteConformance(t *testing.T) {
+	servicetest.Run(t, func(t *testing.T) *service.BundlerService {
+		svc, err := CreateSqliteService(filepath.Join(t.TempDir(), "partsbundler.db"))
+		if err != nil {
+			t.Fatalf("Error creating test service: %s", err)
+		}
+		t.Cleanup(func() { svc.Close() })
+
+		return svc
+	})
+}
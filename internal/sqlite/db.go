@@ -1,36 +1,106 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"strings"
+	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 	"github.com/sombrerosheep/partsbundler/pkg/core"
 )
 
+// driverName is the sqlite3 driver which enforces foreign keys and has
+// the functions used by the catalog queries registered on every
+// connection.
+const driverName = "sqlite3_partsbundler"
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			_, err := conn.Exec("pragma foreign_keys = on", nil)
+			if err != nil {
+				return err
+			}
+
+			err = conn.RegisterFunc("part_magnitude", partMagnitude, true)
+			if err != nil {
+				return err
+			}
+
+			return conn.RegisterFunc("part_in_range", partInRange, true)
+		},
+	})
+}
+
+// partMagnitude implements part_magnitude(kind, name), the parsed value
+// of a part or null for parts without one.
+func partMagnitude(kind, name string) interface{} {
+	v, err := core.ParseValue(core.PartType(kind), name)
+	if err != nil {
+		return nil
+	}
+
+	return v.Magnitude
+}
+
+// partInRange implements part_in_range(kind, name, min, max), whether a
+// part's value is within the range of a core.PartQuery.
+func partInRange(kind, name, min, max string) bool {
+	query := core.PartQuery{Kind: core.PartType(kind), Min: min, Max: max}
+
+	return query.InRange(core.Part{Kind: core.PartType(kind), Name: name})
+}
+
 type isqlitedb interface {
 	Connect() error
 	Close() error
+	Ping() error
+	WithTx(fn func(tx isqlitedb) error) error
+	WithContext(ctx context.Context) isqlitedb
 
 	GetPart(partId int64) (core.Part, error)
+	GetParts(partIds []int64) ([]core.Part, error)
 	GetAllParts() ([]core.Part, error)
+	FindParts(query core.PartQuery, page core.PageRequest) ([]core.Part, int, error)
 	GetPartLinks(partId int64) ([]core.Link, error)
+	GetLinksForParts(partIds []int64) (map[int64][]core.Link, error)
+	GetAllPartLinks() (map[int64][]core.Link, error)
 	AddLinkToPart(link string, partId int64) (int64, error)
 	RemoveLinkFromPart(linkId, partId int64) error
-	CreatePart(name string, kind core.PartType) (int64, error)
+	CreatePart(name, value string, kind core.PartType) (int64, error)
+	UpdatePart(partId int64, name, value string, kind core.PartType) error
 	RemovePart(partId int64) error
+	MergeParts(partId, duplicateId int64) error
 
 	GetKit(kitId int64) (core.Kit, error)
+	GetKits(kitIds []int64) ([]core.Kit, error)
 	GetKitPartUsage(partId int64) ([]int64, error)
 	GetKitPartsForKit(kitId int64) ([]kitPartRef, error)
+	GetAllKitParts() (map[int64][]kitPartRef, error)
+	GetKitPartsForKits(kitIds []int64) (map[int64][]kitPartRef, error)
 	GetAllKits() ([]core.Kit, error)
+	ListKits(page core.PageRequest) ([]core.Kit, int, error)
 	AddPartToKit(partId, kitId int64, quantity uint64) error
 	UpdatePartQuantity(partId, kitId int64, quantity uint64) error
 	RemovePartFromKit(partId, kitId int64) error
+	SetKitPartDesignators(partId, kitId int64, designators []string) error
 	GetKitLinks(kitId int64) ([]core.Link, error)
+	GetAllKitLinks() (map[int64][]core.Link, error)
+	GetLinksForKits(kitIds []int64) (map[int64][]core.Link, error)
 	AddLinkToKit(link string, kitId int64) (int64, error)
 	RemoveLinkFromKit(linkId, kitId int64) error
 	CreateKit(name, schematic, diagram string) (int64, error)
+	UpdateKit(kitId int64, name, schematic, diagram string) error
 	RemoveKit(kitId int64) error
+	ImportKit(spec core.KitSpec) (kitImportRef, error)
+
+	GetStock(partId int64) (core.Stock, error)
+	GetAllStock() ([]core.Stock, error)
+	AdjustStock(partId int64, kind core.AdjustmentKind, quantity uint64, note string) (core.Stock, error)
+	SetStockLocation(partId int64, location string) (core.Stock, error)
+	GetStockAdjustments(partId int64) ([]core.StockAdjustment, error)
 }
 
 func CreateSqliteDB(dbPath string) (isqlitedb, error) {
@@ -43,38 +113,166 @@ func CreateSqliteDB(dbPath string) (isqlitedb, error) {
 		return nil, err
 	}
 
+	err = sq.Migrate()
+	if err != nil {
+		sq.Close()
+		return nil, err
+	}
+
 	return sq, nil
 }
 
+// dbtx is satisfied by both *sql.DB and *sql.Tx so queries can run
+// inside or outside of a transaction.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
 type sqlitedb struct {
-	db         *sql.DB
+	conn       *sql.DB
+	db         dbtx
+	ctx        context.Context
+	inTx       bool
 	DBFilePath string
 }
 
 func (db *sqlitedb) Connect() error {
 	var err error
 
-	db.db, err = sql.Open("sqlite3", db.DBFilePath)
+	db.conn, err = sql.Open(driverName, db.DBFilePath)
 	if err != nil {
 		return err
 	}
 
+	db.db = db.conn
+
 	return nil
 }
 
 func (db sqlitedb) Close() error {
-	return db.db.Close()
+	return db.conn.Close()
+}
+
+// Ping opens a connection to the database file if none is open and
+// checks that it can be queried.
+func (db sqlitedb) Ping() error {
+	if err := db.conn.PingContext(db.context()); err != nil {
+		return err
+	}
+
+	var version string
+
+	return db.conn.QueryRowContext(db.context(), "select sqlite_version()").Scan(&version)
+}
+
+// WithContext returns a copy of db whose queries run with ctx, so they
+// are cancelled when ctx is done.
+func (db sqlitedb) WithContext(ctx context.Context) isqlitedb {
+	db.ctx = ctx
+
+	return &db
+}
+
+func (db sqlitedb) context() context.Context {
+	if db.ctx == nil {
+		return context.Background()
+	}
+
+	return db.ctx
+}
+
+func (db sqlitedb) exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.db.ExecContext(db.context(), query, args...)
+}
+
+func (db sqlitedb) query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.db.QueryContext(db.context(), query, args...)
+}
+
+func (db sqlitedb) queryRow(query string, args ...interface{}) *sql.Row {
+	return db.db.QueryRowContext(db.context(), query, args...)
+}
+
+// withTx runs fn with a copy of db whose queries run in a transaction.
+// The transaction is committed when fn returns nil and rolled back
+// otherwise. When db is already in a transaction fn joins it.
+func (db sqlitedb) withTx(fn func(tx sqlitedb) error) (err error) {
+	if db.inTx {
+		return fn(db)
+	}
+
+	sqlTx, err := db.conn.BeginTx(db.context(), nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			sqlTx.Rollback()
+			panic(p)
+		}
+	}()
+
+	tx := db
+	tx.db = sqlTx
+	tx.inTx = true
+
+	err = fn(tx)
+	if err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+
+	return sqlTx.Commit()
+}
+
+func (db sqlitedb) WithTx(fn func(tx isqlitedb) error) error {
+	return db.withTx(func(tx sqlitedb) error {
+		return fn(&tx)
+	})
+}
+
+// maxBatch bounds the number of ids bound to a single "in" query so
+// large lookups stay under sqlite's host parameter limit.
+const maxBatch = 500
+
+// batches splits ids into slices of at most maxBatch ids.
+func batches(ids []int64) [][]int64 {
+	var out [][]int64
+
+	for len(ids) > maxBatch {
+		out = append(out, ids[:maxBatch])
+		ids = ids[maxBatch:]
+	}
+
+	if len(ids) > 0 {
+		out = append(out, ids)
+	}
+
+	return out
+}
+
+// inClause returns a query argument list and its placeholders for ids.
+func inClause(ids []int64) (string, []interface{}) {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	return strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", "), args
 }
 
 func (db sqlitedb) GetPart(partId int64) (core.Part, error) {
 	const query string = `
-		select id, name, kind from parts
+		select id, name, kind, value from parts
 			where id = ?
 	`
 	part := core.Part{}
 
-	row := db.db.QueryRow(query, partId)
-	err := row.Scan(&part.ID, &part.Name, &part.Kind)
+	row := db.queryRow(query, partId)
+	err := row.Scan(&part.ID, &part.Name, &part.Kind, &part.Value)
 
 	if err != nil && err == sql.ErrNoRows {
 		return part, core.PartNotFound{PartID: partId}
@@ -83,13 +281,62 @@ func (db sqlitedb) GetPart(partId int64) (core.Part, error) {
 	return part, err
 }
 
+// GetParts returns the parts with the given ids in the order they were
+// requested. A PartNotFound is returned for the first missing id.
+func (db sqlitedb) GetParts(partIds []int64) ([]core.Part, error) {
+	const query string = `
+		select id, name, kind, value from parts
+			where id in (%s)
+	`
+
+	found := make(map[int64]core.Part, len(partIds))
+
+	for _, batch := range batches(partIds) {
+		in, args := inClause(batch)
+
+		rows, err := db.query(fmt.Sprintf(query, in), args...)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			part := core.Part{}
+
+			err := rows.Scan(&part.ID, &part.Name, &part.Kind, &part.Value)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+
+			found[part.ID] = part
+		}
+
+		err = rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	parts := make([]core.Part, len(partIds))
+	for i, id := range partIds {
+		part, ok := found[id]
+		if !ok {
+			return nil, core.PartNotFound{PartID: id}
+		}
+
+		parts[i] = part
+	}
+
+	return parts, nil
+}
+
 func (db sqlitedb) GetAllParts() ([]core.Part, error) {
 	const query string = `
-		select id, name, kind from parts
+		select id, name, kind, value from parts
 	`
 	parts := []core.Part{}
 
-	rows, err := db.db.Query(query)
+	rows, err := db.query(query)
 	if err != nil {
 		return nil, err
 	}
@@ -98,7 +345,7 @@ func (db sqlitedb) GetAllParts() ([]core.Part, error) {
 		part := core.Part{}
 
 		// handle no rows err
-		err := rows.Scan(&part.ID, &part.Name, &part.Kind)
+		err := rows.Scan(&part.ID, &part.Name, &part.Kind, &part.Value)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				return parts, nil
@@ -113,6 +360,140 @@ func (db sqlitedb) GetAllParts() ([]core.Part, error) {
 	return parts, nil
 }
 
+// requireRow returns notFound when the statement behind res changed no
+// rows.
+func requireRow(res sql.Result, notFound error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return notFound
+	}
+
+	return nil
+}
+
+// orderBy returns an order by clause for terms, each descending when
+// desc is set, with id as the final tie breaker.
+func orderBy(desc bool, terms ...string) string {
+	terms = append(terms, "id")
+
+	if desc {
+		for i := range terms {
+			terms[i] += " desc"
+		}
+	}
+
+	return "order by " + strings.Join(terms, ", ")
+}
+
+// limitOffset returns the limit clause and arguments for page. Sqlite
+// treats a negative limit as no limit.
+func limitOffset(page core.PageRequest) (string, []interface{}) {
+	limit := page.Limit
+	if limit == 0 {
+		limit = -1
+	}
+
+	return "limit ? offset ?", []interface{}{limit, page.Offset}
+}
+
+// FindParts returns a page of the parts matching query, without their
+// links, along with the number of parts matching query.
+func (db sqlitedb) FindParts(query core.PartQuery, page core.PageRequest) ([]core.Part, int, error) {
+	const selectParts string = `
+		select id, name, kind, value from parts
+	`
+	const countParts string = `
+		select count(*) from parts
+	`
+
+	where := []string{}
+	args := []interface{}{}
+
+	if query.Kind != "" {
+		where = append(where, "kind = ?")
+		args = append(args, query.Kind)
+	}
+
+	if query.Text != "" {
+		text := strings.ToLower(query.Text)
+
+		where = append(where, "(instr(lower(name), ?) > 0 or instr(lower(value), ?) > 0)")
+		args = append(args, text, text)
+	}
+
+	if query.Min != "" || query.Max != "" {
+		where = append(where, "part_in_range(kind, name, ?, ?)")
+		args = append(args, query.Min, query.Max)
+	}
+
+	if query.InUse != nil {
+		exists := "exists (select 1 from kitparts kp where kp.partId = parts.id)"
+		if !*query.InUse {
+			exists = "not " + exists
+		}
+
+		where = append(where, exists)
+	}
+
+	if query.HasLinks != nil {
+		exists := "exists (select 1 from partlinks pl where pl.partId = parts.id)"
+		if !*query.HasLinks {
+			exists = "not " + exists
+		}
+
+		where = append(where, exists)
+	}
+
+	filter := ""
+	if len(where) > 0 {
+		filter = "where " + strings.Join(where, " and ")
+	}
+
+	var total int
+	err := db.queryRow(countParts+filter, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var order string
+	switch page.Sort {
+	case core.SortByName:
+		order = orderBy(page.Desc, "name")
+	case core.SortByKind:
+		order = orderBy(page.Desc, "kind", "name")
+	case core.SortByValue:
+		order = orderBy(page.Desc, "kind", "part_magnitude(kind, name)", "value")
+	default:
+		order = orderBy(page.Desc)
+	}
+
+	limit, limitArgs := limitOffset(page)
+
+	rows, err := db.query(strings.Join([]string{selectParts, filter, order, limit}, " "), append(args, limitArgs...)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	parts := []core.Part{}
+	for rows.Next() {
+		part := core.Part{}
+
+		err := rows.Scan(&part.ID, &part.Name, &part.Kind, &part.Value)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		parts = append(parts, part)
+	}
+
+	return parts, total, rows.Err()
+}
+
 func (db sqlitedb) GetPartLinks(partId int64) ([]core.Link, error) {
 	const query string = `
 		select id, link from partlinks
@@ -125,7 +506,7 @@ func (db sqlitedb) GetPartLinks(partId int64) ([]core.Link, error) {
 		return nil, err
 	}
 
-	rows, err := db.db.Query(query, partId)
+	rows, err := db.query(query, partId)
 	if err != nil {
 		return nil, err
 	}
@@ -148,6 +529,70 @@ func (db sqlitedb) GetPartLinks(partId int64) ([]core.Link, error) {
 	return links, nil
 }
 
+// GetLinksForParts returns the links of each of the given parts keyed by
+// part id. Parts without links are not included.
+func (db sqlitedb) GetLinksForParts(partIds []int64) (map[int64][]core.Link, error) {
+	const query string = `
+		select partId, id, link from partlinks
+			where partId in (%s)
+			order by id
+	`
+
+	links := map[int64][]core.Link{}
+
+	for _, batch := range batches(partIds) {
+		in, args := inClause(batch)
+
+		err := db.scanOwnedLinks(links, fmt.Sprintf(query, in), args...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return links, nil
+}
+
+// GetAllPartLinks returns the links of every part keyed by part id.
+func (db sqlitedb) GetAllPartLinks() (map[int64][]core.Link, error) {
+	const query string = `
+		select partId, id, link from partlinks
+			order by id
+	`
+
+	links := map[int64][]core.Link{}
+
+	err := db.scanOwnedLinks(links, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// scanOwnedLinks runs a query selecting (ownerId, id, link) rows and
+// appends each link to links under its owner.
+func (db sqlitedb) scanOwnedLinks(links map[int64][]core.Link, query string, args ...interface{}) error {
+	rows, err := db.query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ownerId int64
+		link := core.Link{}
+
+		err := rows.Scan(&ownerId, &link.ID, &link.URL)
+		if err != nil {
+			return err
+		}
+
+		links[ownerId] = append(links[ownerId], link)
+	}
+
+	return rows.Err()
+}
+
 func (db sqlitedb) AddLinkToPart(link string, partId int64) (int64, error) {
 	const stmt string = `
 		insert into partlinks(partId, link)
@@ -159,7 +604,7 @@ func (db sqlitedb) AddLinkToPart(link string, partId int64) (int64, error) {
 		return -1, err
 	}
 
-	res, err := db.db.Exec(stmt, partId, link)
+	res, err := db.exec(stmt, partId, link)
 	if err != nil {
 		return -1, err
 	}
@@ -183,22 +628,25 @@ func (db sqlitedb) RemoveLinkFromPart(linkId, partId int64) error {
 		return err
 	}
 
-	_, err = db.db.Exec(stmt, linkId, partId)
+	res, err := db.exec(stmt, linkId, partId)
+	if err != nil {
+		return err
+	}
 
-	return err
+	return requireRow(res, core.LinkNotFound{LinkID: linkId, OwnerID: partId})
 }
 
-func (db sqlitedb) CreatePart(name string, kind core.PartType) (int64, error) {
+func (db sqlitedb) CreatePart(name, value string, kind core.PartType) (int64, error) {
 	const stmt string = `
-		insert into parts(name, kind)
-			values(?, ?)
+		insert into parts(name, kind, value)
+			values(?, ?, ?)
 	`
 
 	if err := kind.IsValid(); err != nil {
 		return -1, err
 	}
 
-	res, err := db.db.Exec(stmt, name, kind)
+	res, err := db.exec(stmt, name, kind, value)
 	if err != nil {
 		return -1, err
 	}
@@ -211,60 +659,267 @@ func (db sqlitedb) CreatePart(name string, kind core.PartType) (int64, error) {
 	return id, nil
 }
 
-func (db sqlitedb) RemovePart(partId int64) error {
+func (db sqlitedb) UpdatePart(partId int64, name, value string, kind core.PartType) error {
 	const stmt string = `
-		delete from parts where id = ?
+		update parts
+			set name = ?, kind = ?, value = ?
+			where id = ?
 	`
 
+	if err := kind.IsValid(); err != nil {
+		return err
+	}
+
 	_, err := db.GetPart(partId)
 	if err != nil {
 		return err
 	}
 
-	_, err = db.db.Exec(stmt, partId)
+	_, err = db.exec(stmt, name, kind, value, partId)
 
 	return err
 }
 
-func (db sqlitedb) GetKit(kitId int64) (core.Kit, error) {
-	const query string = `
-		select id, name, schematic, diagram from kits
-			where id = ?
+func (db sqlitedb) RemovePart(partId int64) error {
+	const stmt string = `
+		delete from parts where id = ?
 	`
-	kit := core.Kit{}
 
-	row := db.db.QueryRow(query, kitId)
+	return db.withTx(func(tx sqlitedb) error {
+		kitIds, err := tx.GetKitPartUsage(partId)
+		if err != nil {
+			return err
+		}
 
-	err := row.Scan(&kit.ID, &kit.Name, &kit.Schematic, &kit.Diagram)
+		if len(kitIds) > 0 {
+			return core.PartInUse{PartID: partId}
+		}
 
-	if err != nil && err == sql.ErrNoRows {
-		return kit, core.KitNotFound{KitID: kitId}
-	}
+		_, err = tx.exec(stmt, partId)
 
-	return kit, err
+		return err
+	})
 }
 
-func (db sqlitedb) GetKitPartUsage(partId int64) ([]int64, error) {
-	const query string = `
-		select kitId from kitparts
+// MergeParts folds the duplicate into the part. It refuses the merge
+// with DesignatorMismatch when a kit using both parts would be left
+// with a quantity other than its number of designators.
+func (db sqlitedb) MergeParts(partId, duplicateId int64) error {
+	const checkDesignators string = `
+		select kp.kitId, kp.quantity + dup.quantity, (
+			select count(*) from kitpartdesignators
+				where kitPartId in (kp.id, dup.id)
+		) designators
+			from kitparts kp, kitparts dup
+			where kp.partId = ? and dup.partId = ? and dup.kitId = kp.kitId
+				and designators > 0 and designators != kp.quantity + dup.quantity
+			order by kp.kitId
+			limit 1
+	`
+	const sumQuantities string = `
+		update kitparts
+			set quantity = quantity + (
+				select sum(dup.quantity) from kitparts dup
+					where dup.kitId = kitparts.kitId and dup.partId = ?
+			)
+			where partId = ? and kitId in (
+				select kitId from kitparts where partId = ?
+			)
+	`
+	const moveSharedDesignators string = `
+		update kitpartdesignators
+			set kitPartId = (
+				select kp.id from kitparts kp, kitparts dup
+					where dup.id = kitpartdesignators.kitPartId
+						and kp.kitId = dup.kitId and kp.partId = ?
+			)
+			where kitPartId in (
+				select id from kitparts
+					where partId = ? and kitId in (
+						select kitId from kitparts where partId = ?
+					)
+			)
+	`
+	const removeSharedKitParts string = `
+		delete from kitparts
+			where partId = ? and kitId in (
+				select kitId from kitparts where partId = ?
+			)
+	`
+	const moveKitParts string = `
+		update kitparts
+			set partId = ?
 			where partId = ?
 	`
+	const removeSharedLinks string = `
+		delete from partlinks
+			where partId = ? and link in (
+				select link from partlinks where partId = ?
+			)
+	`
+	const moveLinks string = `
+		update partlinks
+			set partId = ?
+			where partId = ?
+	`
+	const mergeStock string = `
+		insert into stock(partId, quantity, location)
+			select ?, quantity, location from stock where partId = ?
+			on conflict(partId) do update
+				set quantity = quantity + excluded.quantity
+	`
+	const moveStockAdjustments string = `
+		update stockadjustments
+			set partId = ?
+			where partId = ?
+	`
+	const removePart string = `
+		delete from parts where id = ?
+	`
 
-	_, err := db.GetPart(partId)
-	if err != nil {
-		return nil, err
-	}
-
-	rows, err := db.db.Query(query, partId)
-	if err != nil {
-		return nil, err
+	if partId == duplicateId {
+		return core.CannotMergeParts{PartID: partId, DuplicateID: duplicateId}
 	}
 
-	ids := []int64{}
-	for rows.Next() {
-		var id int64
-		err = rows.Scan(&id)
-
+	return db.withTx(func(tx sqlitedb) error {
+		part, err := tx.GetPart(partId)
+		if err != nil {
+			return err
+		}
+
+		duplicate, err := tx.GetPart(duplicateId)
+		if err != nil {
+			return err
+		}
+
+		if !part.IsDuplicateOf(duplicate) {
+			return core.CannotMergeParts{PartID: partId, DuplicateID: duplicateId}
+		}
+
+		mismatch := core.DesignatorMismatch{PartID: partId}
+		err = tx.queryRow(checkDesignators, partId, duplicateId).
+			Scan(&mismatch.KitID, &mismatch.Quantity, &mismatch.Designators)
+		if err == nil {
+			return mismatch
+		}
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		stmts := []struct {
+			stmt string
+			args []interface{}
+		}{
+			{sumQuantities, []interface{}{duplicateId, partId, duplicateId}},
+			{moveSharedDesignators, []interface{}{partId, duplicateId, partId}},
+			{removeSharedKitParts, []interface{}{duplicateId, partId}},
+			{moveKitParts, []interface{}{partId, duplicateId}},
+			{removeSharedLinks, []interface{}{duplicateId, partId}},
+			{moveLinks, []interface{}{partId, duplicateId}},
+			{mergeStock, []interface{}{partId, duplicateId}},
+			{moveStockAdjustments, []interface{}{partId, duplicateId}},
+			{removePart, []interface{}{duplicateId}},
+		}
+
+		for _, s := range stmts {
+			_, err = tx.exec(s.stmt, s.args...)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (db sqlitedb) GetKit(kitId int64) (core.Kit, error) {
+	const query string = `
+		select id, name, schematic, diagram from kits
+			where id = ?
+	`
+	kit := core.Kit{}
+
+	row := db.queryRow(query, kitId)
+
+	err := row.Scan(&kit.ID, &kit.Name, &kit.Schematic, &kit.Diagram)
+
+	if err != nil && err == sql.ErrNoRows {
+		return kit, core.KitNotFound{KitID: kitId}
+	}
+
+	return kit, err
+}
+
+// GetKits returns the given kits, without their parts and links, in the
+// order of kitIds. KitNotFound is returned for the first missing kit.
+func (db sqlitedb) GetKits(kitIds []int64) ([]core.Kit, error) {
+	const query string = `
+		select id, name, schematic, diagram from kits
+			where id in (%s)
+	`
+
+	found := make(map[int64]core.Kit, len(kitIds))
+
+	for _, batch := range batches(kitIds) {
+		in, args := inClause(batch)
+
+		rows, err := db.query(fmt.Sprintf(query, in), args...)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			kit := core.Kit{}
+
+			err := rows.Scan(&kit.ID, &kit.Name, &kit.Schematic, &kit.Diagram)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+
+			found[kit.ID] = kit
+		}
+
+		err = rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	kits := make([]core.Kit, len(kitIds))
+	for i, id := range kitIds {
+		kit, ok := found[id]
+		if !ok {
+			return nil, core.KitNotFound{KitID: id}
+		}
+
+		kits[i] = kit
+	}
+
+	return kits, nil
+}
+
+func (db sqlitedb) GetKitPartUsage(partId int64) ([]int64, error) {
+	const query string = `
+		select kitId from kitparts
+			where partId = ?
+	`
+
+	_, err := db.GetPart(partId)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.query(query, partId)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		err = rows.Scan(&id)
+
 		if err != nil {
 			if err == sql.ErrNoRows {
 				return []int64{}, nil
@@ -280,16 +935,23 @@ func (db sqlitedb) GetKitPartUsage(partId int64) ([]int64, error) {
 }
 
 type kitPartRef struct {
-	kitId    int64
-	partId   int64
-	quantity uint64
+	kitId       int64
+	partId      int64
+	quantity    uint64
+	designators []string
 }
 
 func (db sqlitedb) GetKitPartsForKit(kitId int64) ([]kitPartRef, error) {
 	const query string = `
-		select kitId, partId, quantity from kitparts
+		select id, kitId, partId, quantity from kitparts
 			where kitId = ?
 	`
+	const designatorQuery string = `
+		select d.kitPartId, d.designator from kitpartdesignators d
+			inner join kitparts kp on kp.id = d.kitPartId
+			where kp.kitId = ?
+			order by d.id
+	`
 
 	_, err := db.GetKit(kitId)
 	if err != nil {
@@ -297,35 +959,165 @@ func (db sqlitedb) GetKitPartsForKit(kitId int64) ([]kitPartRef, error) {
 	}
 
 	parts := []kitPartRef{}
+	indexes := map[int64]int{}
 
-	rows, err := db.db.Query(query, kitId)
+	rows, err := db.query(query, kitId)
 	if err != nil {
 		return nil, err
 	}
 
 	for rows.Next() {
+		var kitPartId int64
 		part := kitPartRef{}
-		err = rows.Scan(&part.kitId, &part.partId, &part.quantity)
-		if err != nil {
-			if err == sql.ErrNoRows {
-				return []kitPartRef{}, nil
-			}
 
+		err = rows.Scan(&kitPartId, &part.kitId, &part.partId, &part.quantity)
+		if err != nil {
+			rows.Close()
 			return nil, err
 		}
 
+		indexes[kitPartId] = len(parts)
 		parts = append(parts, part)
 	}
 
+	err = rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = db.query(designatorQuery, kitId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var kitPartId int64
+		var designator string
+
+		err = rows.Scan(&kitPartId, &designator)
+		if err != nil {
+			return nil, err
+		}
+
+		i, ok := indexes[kitPartId]
+		if !ok {
+			continue
+		}
+
+		parts[i].designators = append(parts[i].designators, designator)
+	}
+
+	return parts, rows.Err()
+}
+
+// GetAllKitParts returns the parts of every kit keyed by kit id.
+func (db sqlitedb) GetAllKitParts() (map[int64][]kitPartRef, error) {
+	parts := map[int64][]kitPartRef{}
+
+	err := db.scanKitParts(parts, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return parts, nil
+}
+
+// GetKitPartsForKits returns the parts of the given kits keyed by kit id.
+// Kits without parts are not included.
+func (db sqlitedb) GetKitPartsForKits(kitIds []int64) (map[int64][]kitPartRef, error) {
+	parts := map[int64][]kitPartRef{}
+
+	for _, batch := range batches(kitIds) {
+		in, args := inClause(batch)
+
+		err := db.scanKitParts(parts, fmt.Sprintf("where kp.kitId in (%s)", in), args...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return parts, nil
 }
 
+// scanKitParts appends the kit parts, and their designators, selected by
+// filter to parts under their kit. filter may refer to kitparts as kp.
+func (db sqlitedb) scanKitParts(parts map[int64][]kitPartRef, filter string, args ...interface{}) error {
+	const query string = `
+		select kp.id, kp.kitId, kp.partId, kp.quantity from kitparts kp
+			%s
+			order by kp.id
+	`
+	const designatorQuery string = `
+		select d.kitPartId, d.designator from kitpartdesignators d
+			inner join kitparts kp on kp.id = d.kitPartId
+			%s
+			order by d.id
+	`
+
+	rows, err := db.query(fmt.Sprintf(query, filter), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type position struct {
+		kitId int64
+		index int
+	}
+
+	positions := map[int64]position{}
+
+	for rows.Next() {
+		var kitPartId int64
+		part := kitPartRef{}
+
+		err = rows.Scan(&kitPartId, &part.kitId, &part.partId, &part.quantity)
+		if err != nil {
+			return err
+		}
+
+		positions[kitPartId] = position{kitId: part.kitId, index: len(parts[part.kitId])}
+		parts[part.kitId] = append(parts[part.kitId], part)
+	}
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	designators, err := db.query(fmt.Sprintf(designatorQuery, filter), args...)
+	if err != nil {
+		return err
+	}
+	defer designators.Close()
+
+	for designators.Next() {
+		var kitPartId int64
+		var designator string
+
+		err = designators.Scan(&kitPartId, &designator)
+		if err != nil {
+			return err
+		}
+
+		pos, ok := positions[kitPartId]
+		if !ok {
+			continue
+		}
+
+		ref := &parts[pos.kitId][pos.index]
+		ref.designators = append(ref.designators, designator)
+	}
+
+	return designators.Err()
+}
+
 func (db sqlitedb) GetAllKits() ([]core.Kit, error) {
 	const query string = `
 		select id, name, schematic, diagram from kits
 	`
 
-	rows, err := db.db.Query(query)
+	rows, err := db.query(query)
 	if err != nil {
 		return nil, err
 	}
@@ -349,25 +1141,82 @@ func (db sqlitedb) GetAllKits() ([]core.Kit, error) {
 	return kits, nil
 }
 
-func (db sqlitedb) AddPartToKit(partId, kitId int64, quantity uint64) error {
-	const stmt string = `
-		insert into kitparts(partId, kitId, quantity)
-			values(?, ?, ?)
+// ListKits returns a page of kits, without their parts or links, along
+// with the number of kits.
+func (db sqlitedb) ListKits(page core.PageRequest) ([]core.Kit, int, error) {
+	const selectKits string = `
+		select id, name, schematic, diagram from kits
+	`
+	const countKits string = `
+		select count(*) from kits
 	`
 
-	_, err := db.GetKit(kitId)
+	var total int
+	err := db.queryRow(countKits).Scan(&total)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
 
-	_, err = db.GetPart(partId)
+	var order string
+	switch page.Sort {
+	case core.SortByName:
+		order = orderBy(page.Desc, "name")
+	case core.SortByPartCount:
+		order = orderBy(page.Desc, "(select count(*) from kitparts kp where kp.kitId = kits.id)")
+	default:
+		order = orderBy(page.Desc)
+	}
+
+	limit, args := limitOffset(page)
+
+	rows, err := db.query(strings.Join([]string{selectKits, order, limit}, " "), args...)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
+	defer rows.Close()
 
-	_, err = db.db.Exec(stmt, partId, kitId, quantity)
+	kits := []core.Kit{}
+	for rows.Next() {
+		kit := core.Kit{}
 
-	return err
+		err := rows.Scan(&kit.ID, &kit.Name, &kit.Schematic, &kit.Diagram)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		kits = append(kits, kit)
+	}
+
+	return kits, total, rows.Err()
+}
+
+func (db sqlitedb) AddPartToKit(partId, kitId int64, quantity uint64) error {
+	const stmt string = `
+		insert into kitparts(partId, kitId, quantity)
+			values(?, ?, ?)
+	`
+
+	return db.withTx(func(tx sqlitedb) error {
+		_, err := tx.GetKit(kitId)
+		if err != nil {
+			return err
+		}
+
+		kitIds, err := tx.GetKitPartUsage(partId)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range kitIds {
+			if id == kitId {
+				return core.PartAlreadyInKit{KitID: kitId, PartID: partId}
+			}
+		}
+
+		_, err = tx.exec(stmt, partId, kitId, quantity)
+
+		return err
+	})
 }
 
 func (db sqlitedb) UpdatePartQuantity(partId, kitId int64, quantity uint64) error {
@@ -376,31 +1225,144 @@ func (db sqlitedb) UpdatePartQuantity(partId, kitId int64, quantity uint64) erro
 			set quantity = ?
 			where partId = ? and kitId = ?
 	`
+	const countDesignators string = `
+		select count(*) from kitpartdesignators d
+			inner join kitparts kp on kp.id = d.kitPartId
+			where kp.partId = ? and kp.kitId = ?
+	`
 
-	_, err := db.GetPart(partId)
+	return db.withTx(func(tx sqlitedb) error {
+		_, err := tx.GetPart(partId)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.GetKit(kitId)
+		if err != nil {
+			return err
+		}
+
+		var designators int
+		err = tx.queryRow(countDesignators, partId, kitId).Scan(&designators)
+		if err != nil {
+			return err
+		}
+
+		if designators > 0 && uint64(designators) != quantity {
+			return core.DesignatorMismatch{
+				KitID:       kitId,
+				PartID:      partId,
+				Designators: designators,
+				Quantity:    quantity,
+			}
+		}
+
+		_, err = tx.exec(stmt, quantity, partId, kitId)
+
+		return err
+	})
+}
+
+func (db sqlitedb) RemovePartFromKit(partId, kitId int64) error {
+	const stmt string = `
+		delete from kitparts
+			where partId = ? and kitId = ?
+	`
+
+	_, err := db.GetKit(kitId)
 	if err != nil {
 		return err
 	}
 
-	_, err = db.GetKit(kitId)
+	res, err := db.exec(stmt, partId, kitId)
 	if err != nil {
 		return err
 	}
 
-	_, err = db.db.Exec(stmt, quantity, partId, kitId)
-
-	return err
+	return requireRow(res, core.PartNotInKit{KitID: kitId, PartID: partId})
 }
 
-func (db sqlitedb) RemovePartFromKit(partId, kitId int64) error {
-	const stmt string = `
-		delete from kitparts
+// SetKitPartDesignators replaces the designators of a part in a kit
+// and sets its quantity to match. An empty list removes the designators
+// and leaves the quantity as it was.
+func (db sqlitedb) SetKitPartDesignators(partId, kitId int64, designators []string) error {
+	const findKitPart string = `
+		select id from kitparts
 			where partId = ? and kitId = ?
 	`
+	const findInUse string = `
+		select d.designator from kitpartdesignators d
+			inner join kitparts kp on kp.id = d.kitPartId
+			where kp.kitId = ? and kp.id != ? and d.designator = ?
+	`
+	const removeDesignators string = `
+		delete from kitpartdesignators
+			where kitPartId = ?
+	`
+	const addDesignator string = `
+		insert into kitpartdesignators(kitPartId, designator)
+			values(?, ?)
+	`
+	const setQuantity string = `
+		update kitparts
+			set quantity = ?
+			where id = ?
+	`
 
-	_, err := db.db.Exec(stmt, partId, kitId)
+	designators = core.NormalizeDesignators(designators)
 
-	return err
+	return db.withTx(func(tx sqlitedb) error {
+		_, err := tx.GetKit(kitId)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.GetPart(partId)
+		if err != nil {
+			return err
+		}
+
+		var kitPartId int64
+		err = tx.queryRow(findKitPart, partId, kitId).Scan(&kitPartId)
+		if err == sql.ErrNoRows {
+			return core.PartNotInKit{KitID: kitId, PartID: partId}
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, d := range designators {
+			var inUse string
+			err = tx.queryRow(findInUse, kitId, kitPartId, d).Scan(&inUse)
+			if err == nil {
+				return core.DesignatorInUse{KitID: kitId, Designator: d}
+			}
+			if err != sql.ErrNoRows {
+				return err
+			}
+		}
+
+		_, err = tx.exec(removeDesignators, kitPartId)
+		if err != nil {
+			return err
+		}
+
+		for _, d := range designators {
+			_, err = tx.exec(addDesignator, kitPartId, d)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(designators) > 0 {
+			_, err = tx.exec(setQuantity, len(designators), kitPartId)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
 
 func (db sqlitedb) GetKitLinks(kitId int64) ([]core.Link, error) {
@@ -416,7 +1378,7 @@ func (db sqlitedb) GetKitLinks(kitId int64) ([]core.Link, error) {
 
 	links := []core.Link{}
 
-	rows, err := db.db.Query(query, kitId)
+	rows, err := db.query(query, kitId)
 	if err != nil {
 		return nil, err
 	}
@@ -439,6 +1401,46 @@ func (db sqlitedb) GetKitLinks(kitId int64) ([]core.Link, error) {
 	return links, nil
 }
 
+// GetAllKitLinks returns the links of every kit keyed by kit id.
+func (db sqlitedb) GetAllKitLinks() (map[int64][]core.Link, error) {
+	const query string = `
+		select kitId, id, link from kitlinks
+			order by id
+	`
+
+	links := map[int64][]core.Link{}
+
+	err := db.scanOwnedLinks(links, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// GetLinksForKits returns the links of each of the given kits keyed by
+// kit id. Kits without links are not included.
+func (db sqlitedb) GetLinksForKits(kitIds []int64) (map[int64][]core.Link, error) {
+	const query string = `
+		select kitId, id, link from kitlinks
+			where kitId in (%s)
+			order by id
+	`
+
+	links := map[int64][]core.Link{}
+
+	for _, batch := range batches(kitIds) {
+		in, args := inClause(batch)
+
+		err := db.scanOwnedLinks(links, fmt.Sprintf(query, in), args...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return links, nil
+}
+
 func (db sqlitedb) AddLinkToKit(link string, kitId int64) (int64, error) {
 	const stmt string = `
 		insert into kitlinks(kitId, link)
@@ -450,7 +1452,7 @@ func (db sqlitedb) AddLinkToKit(link string, kitId int64) (int64, error) {
 		return -1, err
 	}
 
-	res, err := db.db.Exec(stmt, kitId, link)
+	res, err := db.exec(stmt, kitId, link)
 	if err != nil {
 		return -1, err
 	}
@@ -474,9 +1476,12 @@ func (db sqlitedb) RemoveLinkFromKit(linkId, kitId int64) error {
 		return err
 	}
 
-	_, err = db.db.Exec(stmt, kitId, linkId)
+	res, err := db.exec(stmt, kitId, linkId)
+	if err != nil {
+		return err
+	}
 
-	return err
+	return requireRow(res, core.KitLinkNotFound{LinkID: linkId, KitID: kitId})
 }
 
 func (db sqlitedb) CreateKit(name, schematic, diagram string) (int64, error) {
@@ -485,7 +1490,7 @@ func (db sqlitedb) CreateKit(name, schematic, diagram string) (int64, error) {
 			values(?, ?, ?)
 	`
 
-	res, err := db.db.Exec(stmt, name, schematic, diagram)
+	res, err := db.exec(stmt, name, schematic, diagram)
 	if err != nil {
 		return -1, err
 	}
@@ -498,13 +1503,303 @@ func (db sqlitedb) CreateKit(name, schematic, diagram string) (int64, error) {
 	return id, nil
 }
 
+func (db sqlitedb) UpdateKit(kitId int64, name, schematic, diagram string) error {
+	const stmt string = `
+		update kits
+			set name = ?, schematic = ?, diagram = ?
+			where id = ?
+	`
+
+	_, err := db.GetKit(kitId)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.exec(stmt, name, schematic, diagram, kitId)
+
+	return err
+}
+
 func (db sqlitedb) RemoveKit(kitId int64) error {
 	const stmt string = `
 		delete from kits
 			where id = ?
 	`
 
-	_, err := db.db.Exec(stmt, kitId)
+	_, err := db.exec(stmt, kitId)
 
 	return err
 }
+
+type kitImportRef struct {
+	kitId   int64
+	matched []int64
+	created []int64
+}
+
+// ImportKit creates the kit described by spec along with any parts that
+// do not already exist. Parts are matched on kind and normalized value.
+func (db sqlitedb) ImportKit(spec core.KitSpec) (kitImportRef, error) {
+	const findPart string = `
+		select id from parts
+			where kind = ? and value = ?
+			order by id
+			limit 1
+	`
+	const createPart string = `
+		insert into parts(name, kind, value)
+			values(?, ?, ?)
+	`
+	const addPartLink string = `
+		insert into partlinks(partId, link)
+			select ?, ? where not exists (
+				select 1 from partlinks where partId = ? and link = ?
+			)
+	`
+	const createKit string = `
+		insert into kits(name, schematic, diagram)
+			values(?, ?, ?)
+	`
+	const addKitPart string = `
+		insert into kitparts(partId, kitId, quantity)
+			values(?, ?, ?)
+	`
+	const addKitLink string = `
+		insert into kitlinks(kitId, link)
+			values(?, ?)
+	`
+	const addDesignator string = `
+		insert into kitpartdesignators(kitPartId, designator)
+			values(?, ?)
+	`
+
+	ref := kitImportRef{
+		matched: []int64{},
+		created: []int64{},
+	}
+
+	if err := spec.Validate(); err != nil {
+		return ref, err
+	}
+
+	err := db.withTx(func(tx sqlitedb) error {
+		res, err := tx.exec(createKit, spec.Name, spec.Schematic, spec.Diagram)
+		if err != nil {
+			return err
+		}
+
+		ref.kitId, err = res.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		for _, link := range spec.Links {
+			_, err = tx.exec(addKitLink, ref.kitId, link)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, p := range spec.Merged() {
+			var partId int64
+			value := p.Value()
+
+			err = tx.queryRow(findPart, p.Kind, value).Scan(&partId)
+			switch {
+			case err == nil:
+				ref.matched = append(ref.matched, partId)
+			case err == sql.ErrNoRows:
+				res, err := tx.exec(createPart, p.Name, p.Kind, value)
+				if err != nil {
+					return err
+				}
+
+				partId, err = res.LastInsertId()
+				if err != nil {
+					return err
+				}
+
+				ref.created = append(ref.created, partId)
+			default:
+				return err
+			}
+
+			for _, link := range p.Links {
+				_, err = tx.exec(addPartLink, partId, link, partId, link)
+				if err != nil {
+					return err
+				}
+			}
+
+			res, err = tx.exec(addKitPart, partId, ref.kitId, p.Quantity)
+			if err != nil {
+				return err
+			}
+
+			kitPartId, err := res.LastInsertId()
+			if err != nil {
+				return err
+			}
+
+			for _, d := range p.Designators {
+				_, err = tx.exec(addDesignator, kitPartId, d)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return kitImportRef{}, err
+	}
+
+	return ref, nil
+}
+
+func (db sqlitedb) GetStock(partId int64) (core.Stock, error) {
+	const query string = `
+		select partId, quantity, location from stock
+			where partId = ?
+	`
+	stock := core.Stock{PartID: partId}
+
+	_, err := db.GetPart(partId)
+	if err != nil {
+		return stock, err
+	}
+
+	row := db.queryRow(query, partId)
+	err = row.Scan(&stock.PartID, &stock.Quantity, &stock.Location)
+
+	if err != nil && err == sql.ErrNoRows {
+		return stock, nil
+	}
+
+	return stock, err
+}
+
+func (db sqlitedb) GetAllStock() ([]core.Stock, error) {
+	const query string = `
+		select partId, quantity, location from stock
+	`
+
+	rows, err := db.query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	stock := []core.Stock{}
+	for rows.Next() {
+		s := core.Stock{}
+
+		err := rows.Scan(&s.PartID, &s.Quantity, &s.Location)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return []core.Stock{}, nil
+			}
+
+			return nil, err
+		}
+
+		stock = append(stock, s)
+	}
+
+	return stock, nil
+}
+
+func (db sqlitedb) AdjustStock(partId int64, kind core.AdjustmentKind, quantity uint64, note string) (core.Stock, error) {
+	const setQuantity string = `
+		insert into stock(partId, quantity)
+			values(?, ?)
+			on conflict(partId) do update set quantity = excluded.quantity
+	`
+	const recordAdjustment string = `
+		insert into stockadjustments(partId, kind, quantity, note, created)
+			values(?, ?, ?, ?, ?)
+	`
+
+	var stock core.Stock
+
+	err := db.withTx(func(tx sqlitedb) error {
+		current, err := tx.GetStock(partId)
+		if err != nil {
+			return err
+		}
+
+		stock, err = current.Apply(kind, quantity)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.exec(setQuantity, partId, stock.Quantity)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.exec(recordAdjustment, partId, kind, quantity, note, time.Now().UTC())
+
+		return err
+	})
+
+	return stock, err
+}
+
+func (db sqlitedb) SetStockLocation(partId int64, location string) (core.Stock, error) {
+	const stmt string = `
+		insert into stock(partId, location)
+			values(?, ?)
+			on conflict(partId) do update set location = excluded.location
+	`
+
+	stock, err := db.GetStock(partId)
+	if err != nil {
+		return stock, err
+	}
+
+	_, err = db.exec(stmt, partId, location)
+	if err != nil {
+		return stock, err
+	}
+
+	stock.Location = location
+
+	return stock, nil
+}
+
+func (db sqlitedb) GetStockAdjustments(partId int64) ([]core.StockAdjustment, error) {
+	const query string = `
+		select id, partId, kind, quantity, note, created from stockadjustments
+			where partId = ?
+			order by id
+	`
+
+	_, err := db.GetPart(partId)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.query(query, partId)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustments := []core.StockAdjustment{}
+	for rows.Next() {
+		a := core.StockAdjustment{}
+
+		err := rows.Scan(&a.ID, &a.PartID, &a.Kind, &a.Quantity, &a.Note, &a.CreatedAt)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return []core.StockAdjustment{}, nil
+			}
+
+			return nil, err
+		}
+
+		adjustments = append(adjustments, a)
+	}
+
+	return adjustments, nil
+}
@@ -1,54 +1,42 @@
 package sqlite
 
 import (
-	"database/sql"
+	"bytes"
+	"context"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"testing"
 
 	"github.com/sombrerosheep/partsbundler/pkg/core"
+	"github.com/sombrerosheep/partsbundler/pkg/service"
 
 	"github.com/stretchr/testify/assert"
 )
 
 const (
-	test_db_setup = "./import/setup.sql"
-	testLink      = "example.com"
+	testLink = "example.com"
 )
 
-func getTestDbConnection(t *testing.T, dbPath string) (*sqlitedb, error) {
-	// setup test database
-	db, err := sql.Open("sqlite3", dbPath)
-	if err != nil {
-		return nil, fmt.Errorf("Error connecting to test db (%s): %s", dbPath, err)
-	}
-
-	// prepare test.db
-	b, err := ioutil.ReadFile(test_db_setup)
-	if err != nil {
-		return nil, fmt.Errorf("Error reading setup script: %s", err)
+func getTestDbConnection(t testing.TB, dbPath string) (*sqlitedb, error) {
+	var testdb = sqlitedb{
+		DBFilePath: dbPath,
 	}
 
-	// execute setup script
-	_, err = db.Exec(string(b))
+	err := testdb.Connect()
 	if err != nil {
-		return nil, fmt.Errorf("Error executing setup script: %s", err)
-	}
-
-	var testdb = sqlitedb{
-		DBFilePath: dbPath,
+		return nil, fmt.Errorf("Error connecting to test db (%s): %s", dbPath, err)
 	}
 
-	err = testdb.Connect()
+	// prepare test.db
+	err = testdb.Migrate()
 	if err != nil {
-		return &testdb, nil
+		return nil, fmt.Errorf("Error migrating test db: %s", err)
 	}
 
 	return &testdb, nil
 }
 
-func testDbDeferredCleanup(t *testing.T, db *sqlitedb, dbPath string) {
+func testDbDeferredCleanup(t testing.TB, db *sqlitedb, dbPath string) {
 	err := db.Close()
 	if err != nil {
 		t.Errorf("Error closing test database: %s", err)
@@ -71,11 +59,12 @@ func Test_SqliteParts(t *testing.T) {
 	var partId int64 = 0
 	var linkId int64 = 0
 	const partName = "4.7k"
+	const partValue = "4.7k"
 	const partKind = "Resistor"
 
 	t.Run("CreatePart", func(t *testing.T) {
 		t.Run("should create part", func(t *testing.T) {
-			pid, err := testdb.CreatePart(partName, partKind)
+			pid, err := testdb.CreatePart(partName, partValue, partKind)
 
 			partId = pid
 
@@ -86,7 +75,7 @@ func Test_SqliteParts(t *testing.T) {
 		t.Run("should return InvalidPartType when part Type is invalid", func(t *testing.T) {
 			invalidType := "Flux Capacitor"
 
-			pid, err := testdb.CreatePart(partName, core.PartType(invalidType))
+			pid, err := testdb.CreatePart(partName, partValue, core.PartType(invalidType))
 
 			assert.Equal(t, int64(-1), pid)
 			assert.NotNil(t, err)
@@ -101,6 +90,7 @@ func Test_SqliteParts(t *testing.T) {
 				ID:    partId,
 				Kind:  partKind,
 				Name:  partName,
+				Value: partValue,
 				Links: []core.Link(nil),
 			}
 
@@ -185,6 +175,12 @@ func Test_SqliteParts(t *testing.T) {
 			assert.IsType(t, core.PartNotFound{}, err)
 			assert.Equal(t, partId, err.(core.PartNotFound).PartID)
 		})
+
+		t.Run("should return LinkNotFound when the part does not have the link", func(t *testing.T) {
+			err := testdb.RemoveLinkFromPart(linkId, partId)
+
+			assert.Equal(t, core.LinkNotFound{LinkID: linkId, OwnerID: partId}, err)
+		})
 	})
 
 	t.Run("RemovePart", func(t *testing.T) {
@@ -214,15 +210,15 @@ func Test_SqliteParts(t *testing.T) {
 	t.Run("GetAllParts", func(t *testing.T) {
 		t.Run("should return all parts", func(t *testing.T) {
 			expectedParts := []core.Part{
-				{Name: "4.7k", Kind: "Resistor"},
-				{Name: "47uf", Kind: "Capacitor"},
-				{Name: "TL072", Kind: "IC"},
+				{Name: "4.7k", Kind: "Resistor", Value: "4.7k"},
+				{Name: "47uf", Kind: "Capacitor", Value: "47uF"},
+				{Name: "TL072", Kind: "IC", Value: "TL072"},
 			}
 
 			for i := range expectedParts {
 				part := &expectedParts[i]
 
-				id, err := testdb.CreatePart(part.Name, part.Kind)
+				id, err := testdb.CreatePart(part.Name, part.Value, part.Kind)
 				if err != nil {
 					t.Fatalf("Error inserting test part (%d:%#v): %s",
 						i, part, err)
@@ -255,7 +251,7 @@ func Test_SQLiteKits(t *testing.T) {
 	const partName = "4.7k"
 	const partKind = "Resistor"
 
-	partId, err := testdb.CreatePart(partName, core.PartType(partKind))
+	partId, err := testdb.CreatePart(partName, partName, core.PartType(partKind))
 
 	assert.Nil(t, err)
 
@@ -294,6 +290,29 @@ func Test_SQLiteKits(t *testing.T) {
 		})
 	})
 
+	t.Run("GetKits", func(t *testing.T) {
+		t.Run("should return the kits in order", func(t *testing.T) {
+			otherId, err := testdb.CreateKit("Other", "", "")
+			assert.Nil(t, err)
+			defer testdb.RemoveKit(otherId)
+
+			kits, err := testdb.GetKits([]int64{otherId, kitId})
+
+			assert.Nil(t, err)
+			if assert.Len(t, kits, 2) {
+				assert.Equal(t, otherId, kits[0].ID)
+				assert.Equal(t, kitId, kits[1].ID)
+				assert.Equal(t, kitName, kits[1].Name)
+			}
+		})
+
+		t.Run("should return KitNotFound for a missing kit", func(t *testing.T) {
+			_, err := testdb.GetKits([]int64{kitId, 9999})
+
+			assert.Equal(t, core.KitNotFound{KitID: 9999}, err)
+		})
+	})
+
 	t.Run("AddPartToKit", func(t *testing.T) {
 		t.Run("should add part to kit", func(t *testing.T) {
 			err := testdb.AddPartToKit(partId, kitId, quantity)
@@ -320,6 +339,24 @@ func Test_SQLiteKits(t *testing.T) {
 			assert.IsType(t, core.PartNotFound{}, err)
 			assert.Equal(t, badPartId, err.(core.PartNotFound).PartID)
 		})
+
+		t.Run("should return PartAlreadyInKit when part is already in kit", func(t *testing.T) {
+			err := testdb.AddPartToKit(partId, kitId, quantity)
+
+			assert.Equal(t, core.PartAlreadyInKit{KitID: kitId, PartID: partId}, err)
+		})
+	})
+
+	t.Run("RemovePart", func(t *testing.T) {
+		t.Run("should return PartInUse when part is in a kit", func(t *testing.T) {
+			err := testdb.RemovePart(partId)
+
+			assert.Equal(t, core.PartInUse{PartID: partId}, err)
+
+			_, err = testdb.GetPart(partId)
+
+			assert.Nil(t, err)
+		})
 	})
 
 	t.Run("GetKitPartUsage", func(t *testing.T) {
@@ -342,7 +379,7 @@ func Test_SQLiteKits(t *testing.T) {
 		})
 
 		t.Run("should return an empty list when kit has no parts", func(t *testing.T) {
-			noRefPartId, err := testdb.CreatePart("test", core.PartType("Resistor"))
+			noRefPartId, err := testdb.CreatePart("test", "TEST", core.PartType("Resistor"))
 
 			assert.Nil(t, err)
 
@@ -440,6 +477,20 @@ func Test_SQLiteKits(t *testing.T) {
 			assert.Nil(t, err)
 			assert.Len(t, partRefs, 0)
 		})
+
+		t.Run("should return PartNotInKit when the part is not in the kit", func(t *testing.T) {
+			err := testdb.RemovePartFromKit(partId, kitId)
+
+			assert.Equal(t, core.PartNotInKit{KitID: kitId, PartID: partId}, err)
+		})
+
+		t.Run("should return KitNotFound when kit does not exist", func(t *testing.T) {
+			badKitId := int64(9999)
+
+			err := testdb.RemovePartFromKit(partId, badKitId)
+
+			assert.Equal(t, core.KitNotFound{KitID: badKitId}, err)
+		})
 	})
 
 	t.Run("AddLinkToKit", func(t *testing.T) {
@@ -506,11 +557,21 @@ func Test_SQLiteKits(t *testing.T) {
 			assert.IsType(t, core.KitNotFound{}, err)
 			assert.Equal(t, badKitId, err.(core.KitNotFound).KitID)
 		})
+
+		t.Run("should return KitLinkNotFound when the kit does not have the link", func(t *testing.T) {
+			err := testdb.RemoveLinkFromKit(linkId, kitId)
+
+			assert.Equal(t, core.KitLinkNotFound{LinkID: linkId, KitID: kitId}, err)
+		})
 	})
 
 	t.Run("RemoveKit", func(t *testing.T) {
 		t.Run("should remove kit and return KitNotFound if accessed", func(t *testing.T) {
-			err := testdb.RemoveKit(kitId)
+			assert.Nil(t, testdb.AddPartToKit(partId, kitId, quantity))
+			_, err := testdb.AddLinkToKit(testLink, kitId)
+			assert.Nil(t, err)
+
+			err = testdb.RemoveKit(kitId)
 
 			assert.Nil(t, err)
 
@@ -520,6 +581,19 @@ func Test_SQLiteKits(t *testing.T) {
 			assert.IsType(t, core.KitNotFound{}, err)
 			assert.Equal(t, kitId, err.(core.KitNotFound).KitID)
 		})
+
+		t.Run("should remove kit parts and links with the kit", func(t *testing.T) {
+			kitIds, err := testdb.GetKitPartUsage(partId)
+
+			assert.Nil(t, err)
+			assert.Len(t, kitIds, 0)
+
+			var links int
+			err = testdb.queryRow("select count(*) from kitlinks where kitId = ?", kitId).Scan(&links)
+
+			assert.Nil(t, err)
+			assert.Equal(t, 0, links)
+		})
 	})
 
 	t.Run("GetAllKits", func(t *testing.T) {
@@ -550,3 +624,1009 @@ func Test_SQLiteKits(t *testing.T) {
 		})
 	})
 }
+
+func Test_SqliteMergeParts(t *testing.T) {
+	const dbPath = "./import/dbmergetest.db"
+	testdb, err := getTestDbConnection(t, dbPath)
+	if err != nil {
+		t.Fatalf("Error connecting to test db (%s): %s", dbPath, err)
+	}
+	defer testDbDeferredCleanup(t, testdb, dbPath)
+
+	partId, err := testdb.CreatePart("1k", "1k", core.Resistor)
+	assert.Nil(t, err)
+	duplicateId, err := testdb.CreatePart("1000r", "1k", core.Resistor)
+	assert.Nil(t, err)
+	capId, err := testdb.CreatePart("47nf", "47nF", core.Capacitor)
+	assert.Nil(t, err)
+	tenkId, err := testdb.CreatePart("10k", "10k", core.Resistor)
+	assert.Nil(t, err)
+
+	sharedKitId, err := testdb.CreateKit("shared", "", "")
+	assert.Nil(t, err)
+	otherKitId, err := testdb.CreateKit("other", "", "")
+	assert.Nil(t, err)
+
+	assert.Nil(t, testdb.AddPartToKit(partId, sharedKitId, 2))
+	assert.Nil(t, testdb.AddPartToKit(duplicateId, sharedKitId, 3))
+	assert.Nil(t, testdb.AddPartToKit(duplicateId, otherKitId, 4))
+
+	_, err = testdb.AddLinkToPart(testLink, partId)
+	assert.Nil(t, err)
+	_, err = testdb.AddLinkToPart(testLink, duplicateId)
+	assert.Nil(t, err)
+	_, err = testdb.AddLinkToPart("example.com/other", duplicateId)
+	assert.Nil(t, err)
+	_, err = testdb.AdjustStock(partId, core.Received, 10, "")
+	assert.Nil(t, err)
+	_, err = testdb.AdjustStock(duplicateId, core.Received, 5, "")
+	assert.Nil(t, err)
+
+	t.Run("should return CannotMergeParts when merging a part into itself", func(t *testing.T) {
+		err := testdb.MergeParts(partId, partId)
+
+		assert.NotNil(t, err)
+		assert.IsType(t, core.CannotMergeParts{}, err)
+	})
+
+	t.Run("should return CannotMergeParts when kinds differ", func(t *testing.T) {
+		err := testdb.MergeParts(partId, capId)
+
+		assert.NotNil(t, err)
+		assert.IsType(t, core.CannotMergeParts{}, err)
+	})
+
+	t.Run("should return CannotMergeParts when values differ", func(t *testing.T) {
+		err := testdb.MergeParts(partId, tenkId)
+
+		assert.Equal(t, core.CannotMergeParts{PartID: partId, DuplicateID: tenkId}, err)
+
+		_, err = testdb.GetPart(tenkId)
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("should return PartNotFound when duplicate does not exist", func(t *testing.T) {
+		badPartId := int64(9999)
+
+		err := testdb.MergeParts(partId, badPartId)
+
+		assert.NotNil(t, err)
+		assert.IsType(t, core.PartNotFound{}, err)
+		assert.Equal(t, badPartId, err.(core.PartNotFound).PartID)
+	})
+
+	t.Run("should merge duplicate into part", func(t *testing.T) {
+		err := testdb.MergeParts(partId, duplicateId)
+
+		assert.Nil(t, err)
+
+		_, err = testdb.GetPart(duplicateId)
+
+		assert.IsType(t, core.PartNotFound{}, err)
+
+		shared, err := testdb.GetKitPartsForKit(sharedKitId)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []kitPartRef{{kitId: sharedKitId, partId: partId, quantity: 5}}, shared)
+
+		other, err := testdb.GetKitPartsForKit(otherKitId)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []kitPartRef{{kitId: otherKitId, partId: partId, quantity: 4}}, other)
+
+		links, err := testdb.GetPartLinks(partId)
+
+		assert.Nil(t, err)
+		assert.Len(t, links, 2)
+		assert.Equal(t, testLink, links[0].URL)
+		assert.Equal(t, "example.com/other", links[1].URL)
+
+		stock, err := testdb.GetStock(partId)
+
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(15), stock.Quantity)
+
+		history, err := testdb.GetStockAdjustments(partId)
+
+		assert.Nil(t, err)
+		assert.Len(t, history, 2)
+	})
+}
+
+func Test_SqliteInventory(t *testing.T) {
+	const dbPath = "./import/dbinventorytest.db"
+	testdb, err := getTestDbConnection(t, dbPath)
+	if err != nil {
+		t.Fatalf("Error connecting to test db (%s): %s", dbPath, err)
+	}
+	defer testDbDeferredCleanup(t, testdb, dbPath)
+
+	const location = "drawer A1"
+
+	partId, err := testdb.CreatePart("10k", "10k", core.Resistor)
+	assert.Nil(t, err)
+
+	t.Run("GetStock", func(t *testing.T) {
+		t.Run("should return empty stock for an untracked part", func(t *testing.T) {
+			stock, err := testdb.GetStock(partId)
+
+			assert.Nil(t, err)
+			assert.Equal(t, core.Stock{PartID: partId}, stock)
+		})
+
+		t.Run("should return PartNotFound when part does not exist", func(t *testing.T) {
+			badPartId := int64(9999)
+
+			_, err := testdb.GetStock(badPartId)
+
+			assert.NotNil(t, err)
+			assert.IsType(t, core.PartNotFound{}, err)
+			assert.Equal(t, badPartId, err.(core.PartNotFound).PartID)
+		})
+	})
+
+	t.Run("AdjustStock", func(t *testing.T) {
+		t.Run("should add received parts", func(t *testing.T) {
+			stock, err := testdb.AdjustStock(partId, core.Received, 25, "first order")
+
+			assert.Nil(t, err)
+			assert.Equal(t, uint64(25), stock.Quantity)
+		})
+
+		t.Run("should remove consumed parts", func(t *testing.T) {
+			stock, err := testdb.AdjustStock(partId, core.Consumed, 5, "")
+
+			assert.Nil(t, err)
+			assert.Equal(t, uint64(20), stock.Quantity)
+
+			stock, err = testdb.GetStock(partId)
+
+			assert.Nil(t, err)
+			assert.Equal(t, uint64(20), stock.Quantity)
+		})
+
+		t.Run("should return InsufficientStock when removing more than available", func(t *testing.T) {
+			_, err := testdb.AdjustStock(partId, core.Lost, 21, "")
+
+			assert.NotNil(t, err)
+			assert.IsType(t, core.InsufficientStock{}, err)
+
+			stock, err := testdb.GetStock(partId)
+
+			assert.Nil(t, err)
+			assert.Equal(t, uint64(20), stock.Quantity)
+		})
+
+		t.Run("should return PartNotFound when part does not exist", func(t *testing.T) {
+			badPartId := int64(9999)
+
+			_, err := testdb.AdjustStock(badPartId, core.Received, 1, "")
+
+			assert.NotNil(t, err)
+			assert.IsType(t, core.PartNotFound{}, err)
+			assert.Equal(t, badPartId, err.(core.PartNotFound).PartID)
+		})
+	})
+
+	t.Run("SetStockLocation", func(t *testing.T) {
+		t.Run("should set location and keep quantity", func(t *testing.T) {
+			stock, err := testdb.SetStockLocation(partId, location)
+
+			assert.Nil(t, err)
+			assert.Equal(t, core.Stock{PartID: partId, Quantity: 20, Location: location}, stock)
+
+			stock, err = testdb.GetStock(partId)
+
+			assert.Nil(t, err)
+			assert.Equal(t, core.Stock{PartID: partId, Quantity: 20, Location: location}, stock)
+		})
+	})
+
+	t.Run("GetAllStock", func(t *testing.T) {
+		t.Run("should return tracked stock", func(t *testing.T) {
+			stock, err := testdb.GetAllStock()
+
+			assert.Nil(t, err)
+			assert.Equal(t, []core.Stock{{PartID: partId, Quantity: 20, Location: location}}, stock)
+		})
+	})
+
+	t.Run("GetStockAdjustments", func(t *testing.T) {
+		t.Run("should return adjustment history", func(t *testing.T) {
+			adjustments, err := testdb.GetStockAdjustments(partId)
+
+			assert.Nil(t, err)
+			assert.Len(t, adjustments, 2)
+			assert.Equal(t, core.AdjustmentKind(core.Received), adjustments[0].Kind)
+			assert.Equal(t, uint64(25), adjustments[0].Quantity)
+			assert.Equal(t, "first order", adjustments[0].Note)
+			assert.False(t, adjustments[0].CreatedAt.IsZero())
+			assert.Equal(t, core.AdjustmentKind(core.Consumed), adjustments[1].Kind)
+		})
+
+		t.Run("should return PartNotFound when part does not exist", func(t *testing.T) {
+			badPartId := int64(9999)
+
+			_, err := testdb.GetStockAdjustments(badPartId)
+
+			assert.NotNil(t, err)
+			assert.IsType(t, core.PartNotFound{}, err)
+		})
+	})
+}
+
+func Test_SqliteImportKit(t *testing.T) {
+	const dbPath = "./import/dbimporttest.db"
+	testdb, err := getTestDbConnection(t, dbPath)
+	if err != nil {
+		t.Fatalf("Error connecting to test db (%s): %s", dbPath, err)
+	}
+	defer testDbDeferredCleanup(t, testdb, dbPath)
+
+	capId, err := testdb.CreatePart("1.5nf", "1.5nF", core.Capacitor)
+	assert.Nil(t, err)
+	icId, err := testdb.CreatePart("TL072", "TL072", core.IC)
+	assert.Nil(t, err)
+
+	t.Run("should import kit file", func(t *testing.T) {
+		f, err := os.Open("./import/ts808.json")
+		if err != nil {
+			t.Fatalf("Error opening kit file: %s", err)
+		}
+		defer f.Close()
+
+		spec, err := core.ReadKitSpec(f)
+
+		assert.Nil(t, err)
+
+		ref, err := testdb.ImportKit(spec)
+
+		assert.Nil(t, err)
+		assert.Greater(t, ref.kitId, int64(0))
+		assert.Equal(t, []int64{capId, icId}, ref.matched)
+		assert.Len(t, ref.created, len(spec.Parts)-2)
+
+		kit, err := testdb.GetKit(ref.kitId)
+
+		assert.Nil(t, err)
+		assert.Equal(t, spec.Name, kit.Name)
+
+		refs, err := testdb.GetKitPartsForKit(ref.kitId)
+
+		assert.Nil(t, err)
+		assert.Len(t, refs, len(spec.Parts))
+
+		part, err := testdb.GetPart(ref.created[0])
+
+		assert.Nil(t, err)
+		assert.Equal(t, "2.2 M", part.Name)
+		assert.Equal(t, "2.2M", part.Value)
+	})
+
+	t.Run("should add links and merge duplicate entries", func(t *testing.T) {
+		spec := core.KitSpec{
+			Name:  "links",
+			Links: []string{testLink},
+			Parts: []core.KitPartSpec{
+				{Kind: core.IC, Name: "tl072", Links: []string{testLink}},
+				{Kind: core.IC, Name: "TL072", Quantity: 2, Links: []string{testLink}},
+			},
+		}
+
+		ref, err := testdb.ImportKit(spec)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []int64{icId}, ref.matched)
+		assert.Len(t, ref.created, 0)
+
+		refs, err := testdb.GetKitPartsForKit(ref.kitId)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []kitPartRef{{kitId: ref.kitId, partId: icId, quantity: 3}}, refs)
+
+		kitLinks, err := testdb.GetKitLinks(ref.kitId)
+
+		assert.Nil(t, err)
+		assert.Len(t, kitLinks, 1)
+
+		partLinks, err := testdb.GetPartLinks(icId)
+
+		assert.Nil(t, err)
+		assert.Len(t, partLinks, 1)
+	})
+
+	t.Run("should not create anything when the spec is invalid", func(t *testing.T) {
+		before, err := testdb.GetAllKits()
+		assert.Nil(t, err)
+
+		spec := core.KitSpec{
+			Name: "invalid",
+			Parts: []core.KitPartSpec{
+				{Kind: core.IC, Name: "TL072"},
+				{Kind: "Flux Capacitor", Name: "1.21GW"},
+			},
+		}
+
+		_, err = testdb.ImportKit(spec)
+
+		assert.NotNil(t, err)
+		assert.IsType(t, core.InvalidPartType{}, err)
+
+		after, err := testdb.GetAllKits()
+
+		assert.Nil(t, err)
+		assert.Equal(t, before, after)
+	})
+}
+
+// assertKitRoundTrip exports kitId from src, reads the document back and
+// imports it into dst. The kit created in dst must export to the same
+// document as the original.
+func assertKitRoundTrip(t *testing.T, src, dst SqliteKitService, kitId int64) {
+	t.Helper()
+
+	kit, err := src.Get(kitId)
+	if err != nil {
+		t.Fatalf("Error getting kit %d: %s", kitId, err)
+	}
+
+	var buf bytes.Buffer
+	expected := core.NewKitSpec(kit)
+
+	err = core.WriteKitSpec(&buf, expected)
+	if err != nil {
+		t.Fatalf("Error writing kit %d: %s", kitId, err)
+	}
+
+	spec, err := core.ReadKitSpec(&buf)
+	if err != nil {
+		t.Fatalf("Error reading kit %d: %s", kitId, err)
+	}
+
+	result, err := dst.Import(spec)
+	if err != nil {
+		t.Fatalf("Error importing kit %d: %s", kitId, err)
+	}
+
+	imported, err := dst.Get(result.Kit.ID)
+	if err != nil {
+		t.Fatalf("Error getting imported kit %d: %s", result.Kit.ID, err)
+	}
+
+	assert.Equal(t, expected, core.NewKitSpec(imported))
+}
+
+func Test_SqliteKitRoundTrip(t *testing.T) {
+	const srcPath = "./import/dbroundtripsrctest.db"
+	const dstPath = "./import/dbroundtripdsttest.db"
+
+	srcdb, err := getTestDbConnection(t, srcPath)
+	if err != nil {
+		t.Fatalf("Error connecting to test db (%s): %s", srcPath, err)
+	}
+	defer testDbDeferredCleanup(t, srcdb, srcPath)
+
+	dstdb, err := getTestDbConnection(t, dstPath)
+	if err != nil {
+		t.Fatalf("Error connecting to test db (%s): %s", dstPath, err)
+	}
+	defer testDbDeferredCleanup(t, dstdb, dstPath)
+
+	src := SqliteKitService{db: srcdb, partservice: SqlitePartService{db: srcdb}}
+	dst := SqliteKitService{db: dstdb, partservice: SqlitePartService{db: dstdb}}
+
+	t.Run("should round trip an imported kit file", func(t *testing.T) {
+		f, err := os.Open("./import/ts808.json")
+		if err != nil {
+			t.Fatalf("Error opening kit file: %s", err)
+		}
+		defer f.Close()
+
+		spec, err := core.ReadKitSpec(f)
+
+		assert.Nil(t, err)
+
+		result, err := src.Import(spec)
+
+		assert.Nil(t, err)
+
+		assertKitRoundTrip(t, src, dst, result.Kit.ID)
+	})
+
+	t.Run("should round trip quantities and links", func(t *testing.T) {
+		kit, err := src.New("links", "schematic.pdf", "diagram.pdf")
+
+		assert.Nil(t, err)
+
+		_, err = src.AddLink(kit.ID, testLink)
+
+		assert.Nil(t, err)
+
+		part, err := src.partservice.New("4k7", core.Resistor)
+
+		assert.Nil(t, err)
+
+		_, err = src.partservice.AddLink(part.ID, testLink)
+
+		assert.Nil(t, err)
+
+		err = src.AddPart(kit.ID, part.ID, 4)
+
+		assert.Nil(t, err)
+
+		err = src.SetPartDesignators(kit.ID, part.ID, []string{"R1", "R4", "R5", "R9"})
+
+		assert.Nil(t, err)
+
+		assertKitRoundTrip(t, src, dst, kit.ID)
+	})
+}
+
+func Test_SqliteKitPartDesignators(t *testing.T) {
+	const dbPath = "./import/dbdesignatortest.db"
+	testdb, err := getTestDbConnection(t, dbPath)
+	if err != nil {
+		t.Fatalf("Error connecting to test db (%s): %s", dbPath, err)
+	}
+	defer testDbDeferredCleanup(t, testdb, dbPath)
+
+	kitId, err := testdb.CreateKit("kit", "", "")
+	assert.Nil(t, err)
+	tenkId, err := testdb.CreatePart("10k", "10k", core.Resistor)
+	assert.Nil(t, err)
+	onekId, err := testdb.CreatePart("1k", "1k", core.Resistor)
+	assert.Nil(t, err)
+
+	assert.Nil(t, testdb.AddPartToKit(tenkId, kitId, 1))
+	assert.Nil(t, testdb.AddPartToKit(onekId, kitId, 1))
+
+	t.Run("should set designators and quantity", func(t *testing.T) {
+		err := testdb.SetKitPartDesignators(tenkId, kitId, []string{"r1", "R7", "R12"})
+
+		assert.Nil(t, err)
+
+		refs, err := testdb.GetKitPartsForKit(kitId)
+
+		expected := []kitPartRef{
+			{kitId: kitId, partId: tenkId, quantity: 3, designators: []string{"R1", "R7", "R12"}},
+			{kitId: kitId, partId: onekId, quantity: 1},
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, refs)
+	})
+
+	t.Run("should return DesignatorMismatch when quantity does not match", func(t *testing.T) {
+		err := testdb.UpdatePartQuantity(tenkId, kitId, 2)
+
+		assert.Equal(t, core.DesignatorMismatch{KitID: kitId, PartID: tenkId, Designators: 3, Quantity: 2}, err)
+	})
+
+	t.Run("should return DesignatorInUse when another part uses a designator", func(t *testing.T) {
+		err := testdb.SetKitPartDesignators(onekId, kitId, []string{"R2", "R7"})
+
+		assert.Equal(t, core.DesignatorInUse{KitID: kitId, Designator: "R7"}, err)
+	})
+
+	t.Run("should return PartNotInKit when part is not in the kit", func(t *testing.T) {
+		otherId, err := testdb.CreatePart("TL072", "TL072", core.IC)
+		assert.Nil(t, err)
+
+		err = testdb.SetKitPartDesignators(otherId, kitId, []string{"IC1"})
+
+		assert.Equal(t, core.PartNotInKit{KitID: kitId, PartID: otherId}, err)
+	})
+
+	t.Run("should keep designators when merging parts", func(t *testing.T) {
+		dupId, err := testdb.CreatePart("10K", "10k", core.Resistor)
+		assert.Nil(t, err)
+
+		assert.Nil(t, testdb.AddPartToKit(dupId, kitId, 1))
+		assert.Nil(t, testdb.SetKitPartDesignators(dupId, kitId, []string{"R20"}))
+
+		err = testdb.MergeParts(tenkId, dupId)
+
+		assert.Nil(t, err)
+
+		refs, err := testdb.GetKitPartsForKit(kitId)
+
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(4), refs[0].quantity)
+		assert.Equal(t, []string{"R1", "R7", "R12", "R20"}, refs[0].designators)
+	})
+
+	t.Run("should remove designators with the kit part", func(t *testing.T) {
+		err := testdb.RemovePartFromKit(tenkId, kitId)
+
+		assert.Nil(t, err)
+
+		assert.Nil(t, testdb.AddPartToKit(tenkId, kitId, 1))
+
+		refs, err := testdb.GetKitPartsForKit(kitId)
+
+		assert.Nil(t, err)
+		assert.Nil(t, refs[len(refs)-1].designators)
+	})
+}
+
+func Test_SqliteWithTx(t *testing.T) {
+	const dbPath = "./import/dbtxtest.db"
+	testdb, err := getTestDbConnection(t, dbPath)
+	if err != nil {
+		t.Fatalf("Error connecting to test db (%s): %s", dbPath, err)
+	}
+	defer testDbDeferredCleanup(t, testdb, dbPath)
+
+	t.Run("should commit when fn succeeds", func(t *testing.T) {
+		var kitId int64
+
+		err := testdb.WithTx(func(tx isqlitedb) error {
+			var err error
+			kitId, err = tx.CreateKit("committed", "", "")
+
+			return err
+		})
+
+		assert.Nil(t, err)
+
+		_, err = testdb.GetKit(kitId)
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("should roll back every change when fn fails", func(t *testing.T) {
+		var kitId int64
+
+		err := testdb.WithTx(func(tx isqlitedb) error {
+			var err error
+			kitId, err = tx.CreateKit("rolled back", "", "")
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.AddLinkToKit(testLink, kitId)
+			if err != nil {
+				return err
+			}
+
+			return tx.AddPartToKit(9999, kitId, 1)
+		})
+
+		assert.IsType(t, core.PartNotFound{}, err)
+
+		_, err = testdb.GetKit(kitId)
+
+		assert.IsType(t, core.KitNotFound{}, err)
+	})
+
+	t.Run("should join an outer transaction", func(t *testing.T) {
+		var partId int64
+
+		err := testdb.WithTx(func(tx isqlitedb) error {
+			err := tx.WithTx(func(inner isqlitedb) error {
+				var err error
+				partId, err = inner.CreatePart("10k", "10k", core.Resistor)
+
+				return err
+			})
+			if err != nil {
+				return err
+			}
+
+			return core.PartInUse{PartID: partId}
+		})
+
+		assert.IsType(t, core.PartInUse{}, err)
+
+		_, err = testdb.GetPart(partId)
+
+		assert.IsType(t, core.PartNotFound{}, err)
+	})
+}
+
+func Test_SqliteServiceAtomic(t *testing.T) {
+	const dbPath = "./import/dbatomictest.db"
+	svc, err := CreateSqliteService(dbPath)
+	if err != nil {
+		t.Fatalf("Error creating test service (%s): %s", dbPath, err)
+	}
+	defer testDbDeferredCleanup(t, svc.Parts.(SqlitePartService).db.(*sqlitedb), dbPath)
+
+	t.Run("should roll back a kit when adding its parts fails", func(t *testing.T) {
+		var kitId int64
+
+		err := svc.Atomic(func(tx *service.BundlerService) error {
+			kit, err := tx.Kits.New("atomic", "", "")
+			if err != nil {
+				return err
+			}
+			kitId = kit.ID
+
+			return tx.Kits.AddPart(kit.ID, 9999, 1)
+		})
+
+		assert.IsType(t, core.PartNotFound{}, err)
+
+		_, err = svc.Kits.Get(kitId)
+
+		assert.IsType(t, core.KitNotFound{}, err)
+	})
+}
+
+func Test_SqliteServiceContext(t *testing.T) {
+	const dbPath = "./import/dbcontexttest.db"
+	svc, err := CreateSqliteService(dbPath)
+	if err != nil {
+		t.Fatalf("Error creating test service (%s): %s", dbPath, err)
+	}
+	defer testDbDeferredCleanup(t, svc.Parts.(SqlitePartService).db.(*sqlitedb), dbPath)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t.Run("should run queries with a live context", func(t *testing.T) {
+		part, err := svc.Parts.NewContext(context.Background(), "10k", core.Resistor)
+
+		assert.Nil(t, err)
+
+		actual, err := svc.Parts.GetContext(context.Background(), part.ID)
+
+		assert.Nil(t, err)
+		assert.Equal(t, part.ID, actual.ID)
+	})
+
+	t.Run("should stop reads when the context is done", func(t *testing.T) {
+		_, err := svc.Parts.GetAllContext(cancelled)
+
+		assert.ErrorIs(t, err, context.Canceled)
+
+		_, err = svc.Kits.GetAllContext(cancelled)
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("should not write when the context is done", func(t *testing.T) {
+		_, err := svc.Kits.NewContext(cancelled, "cancelled", "", "")
+
+		assert.ErrorIs(t, err, context.Canceled)
+
+		kits, err := svc.Kits.GetAll()
+
+		assert.Nil(t, err)
+		assert.Empty(t, kits)
+	})
+}
+
+func Test_SqliteUpdate(t *testing.T) {
+	const dbPath = "./import/dbupdatetest.db"
+	svc, err := CreateSqliteService(dbPath)
+	if err != nil {
+		t.Fatalf("Error creating test service (%s): %s", dbPath, err)
+	}
+	defer testDbDeferredCleanup(t, svc.Parts.(SqlitePartService).db.(*sqlitedb), dbPath)
+
+	part, err := svc.Parts.New("1000r", core.Resistor)
+	if err != nil {
+		t.Fatalf("Error creating part: %s", err)
+	}
+
+	kit, err := svc.Kits.New("Fuzz", "example.com/s", "example.com/d")
+	if err != nil {
+		t.Fatalf("Error creating kit: %s", err)
+	}
+
+	t.Run("should replace a part", func(t *testing.T) {
+		updated, err := svc.Parts.Update(part.ID, "TL072", core.IC)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "TL072", updated.Name)
+		assert.Equal(t, core.PartType(core.IC), updated.Kind)
+		assert.Equal(t, core.NormalizeValue(core.IC, "TL072"), updated.Value)
+
+		actual, err := svc.Parts.Get(part.ID)
+
+		assert.Nil(t, err)
+		assert.Equal(t, updated, actual)
+	})
+
+	t.Run("should patch a part", func(t *testing.T) {
+		name := "TL074"
+
+		updated, err := svc.Parts.Patch(part.ID, core.PartPatch{Name: &name})
+
+		assert.Nil(t, err)
+		assert.Equal(t, name, updated.Name)
+		assert.Equal(t, core.PartType(core.IC), updated.Kind)
+	})
+
+	t.Run("should not update a part with an invalid kind", func(t *testing.T) {
+		kind := core.PartType("Flux Capacitor")
+
+		_, err := svc.Parts.Patch(part.ID, core.PartPatch{Kind: &kind})
+
+		assert.IsType(t, core.InvalidPartType{}, err)
+	})
+
+	t.Run("should not update a part that does not exist", func(t *testing.T) {
+		_, err := svc.Parts.Update(9999, "10k", core.Resistor)
+
+		assert.IsType(t, core.PartNotFound{}, err)
+	})
+
+	t.Run("should replace a kit", func(t *testing.T) {
+		updated, err := svc.Kits.Update(kit.ID, "Big Fuzz", "example.com/s2", "")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "Big Fuzz", updated.Name)
+		assert.Equal(t, "example.com/s2", updated.Schematic)
+		assert.Equal(t, "", updated.Diagram)
+	})
+
+	t.Run("should patch a kit", func(t *testing.T) {
+		diagram := "example.com/d2"
+
+		updated, err := svc.Kits.Patch(kit.ID, core.KitPatch{Diagram: &diagram})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "Big Fuzz", updated.Name)
+		assert.Equal(t, "example.com/s2", updated.Schematic)
+		assert.Equal(t, diagram, updated.Diagram)
+	})
+
+	t.Run("should not update a kit that does not exist", func(t *testing.T) {
+		_, err := svc.Kits.Patch(9999, core.KitPatch{})
+
+		assert.IsType(t, core.KitNotFound{}, err)
+	})
+}
+
+func Test_SqliteFindParts(t *testing.T) {
+	const dbPath = "./import/dbfindtest.db"
+	svc, err := CreateSqliteService(dbPath)
+	if err != nil {
+		t.Fatalf("Error creating test service (%s): %s", dbPath, err)
+	}
+	defer testDbDeferredCleanup(t, svc.Parts.(SqlitePartService).db.(*sqlitedb), dbPath)
+
+	created := map[string]core.Part{}
+	for _, name := range []string{"10nf", "47nf", "0.1uf", "220nf"} {
+		part, err := svc.Parts.New(name, core.Capacitor)
+		if err != nil {
+			t.Fatalf("Error creating part %s: %s", name, err)
+		}
+
+		created[name] = part
+	}
+
+	_, err = svc.Parts.AddLink(created["47nf"].ID, "example.com/47nf")
+	if err != nil {
+		t.Fatalf("Error adding link: %s", err)
+	}
+
+	kit, err := svc.Kits.New("Fuzz", "", "")
+	if err != nil {
+		t.Fatalf("Error creating kit: %s", err)
+	}
+
+	err = svc.Kits.AddPart(kit.ID, created["0.1uf"].ID, 1)
+	if err != nil {
+		t.Fatalf("Error adding part to kit: %s", err)
+	}
+
+	names := func(parts []core.Part) []string {
+		out := []string{}
+		for _, p := range parts {
+			out = append(out, p.Name)
+		}
+		return out
+	}
+
+	yes, no := true, false
+
+	tests := []struct {
+		name     string
+		query    core.PartQuery
+		expected []string
+	}{
+		{"value range", core.PartQuery{Kind: core.Capacitor, Min: "10n", Max: "100n"}, []string{"10nf", "47nf", "0.1uf"}},
+		{"text", core.PartQuery{Text: "UF"}, []string{"0.1uf"}},
+		{"text in value", core.PartQuery{Text: "100n"}, []string{"0.1uf"}},
+		{"in use", core.PartQuery{InUse: &yes}, []string{"0.1uf"}},
+		{"not in use with links", core.PartQuery{InUse: &no, HasLinks: &yes}, []string{"47nf"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parts, err := svc.Parts.Find(test.query)
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, names(parts))
+		})
+	}
+}
+
+func Test_SqliteListParts(t *testing.T) {
+	const dbPath = "./import/dblistpartstest.db"
+	svc, err := CreateSqliteService(dbPath)
+	if err != nil {
+		t.Fatalf("Error creating test service (%s): %s", dbPath, err)
+	}
+	defer testDbDeferredCleanup(t, svc.Parts.(SqlitePartService).db.(*sqlitedb), dbPath)
+
+	parts := []struct {
+		name string
+		kind core.PartType
+	}{
+		{"220nf", core.Capacitor},
+		{"10k", core.Resistor},
+		{"0.1uf", core.Capacitor},
+		{"TL072", core.IC},
+		{"4k7", core.Resistor},
+		{"big electro", core.Capacitor},
+		{"10nf", core.Capacitor},
+	}
+
+	for _, p := range parts {
+		_, err := svc.Parts.New(p.name, p.kind)
+		if err != nil {
+			t.Fatalf("Error creating part %s: %s", p.name, err)
+		}
+	}
+
+	names := func(parts []core.Part) []string {
+		out := []string{}
+		for _, p := range parts {
+			out = append(out, p.Name)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		query    core.PartQuery
+		page     core.PageRequest
+		expected []string
+		total    int
+	}{
+		{"every part by id", core.PartQuery{}, core.PageRequest{}, []string{"220nf", "10k", "0.1uf", "TL072", "4k7", "big electro", "10nf"}, 7},
+		{"first page", core.PartQuery{}, core.PageRequest{Limit: 3}, []string{"220nf", "10k", "0.1uf"}, 7},
+		{"last page", core.PartQuery{}, core.PageRequest{Offset: 6, Limit: 3}, []string{"10nf"}, 7},
+		{"past the end", core.PartQuery{}, core.PageRequest{Offset: 10, Limit: 3}, []string{}, 7},
+		{"by name", core.PartQuery{}, core.PageRequest{Sort: core.SortByName, Limit: 3}, []string{"0.1uf", "10k", "10nf"}, 7},
+		{"by name descending", core.PartQuery{}, core.PageRequest{Sort: core.SortByName, Desc: true, Limit: 2}, []string{"big electro", "TL072"}, 7},
+		{"by kind", core.PartQuery{}, core.PageRequest{Sort: core.SortByKind}, []string{"0.1uf", "10nf", "220nf", "big electro", "TL072", "10k", "4k7"}, 7},
+		{"by value", core.PartQuery{Kind: core.Capacitor}, core.PageRequest{Sort: core.SortByValue}, []string{"big electro", "10nf", "0.1uf", "220nf"}, 4},
+		{"by value descending", core.PartQuery{Kind: core.Resistor}, core.PageRequest{Sort: core.SortByValue, Desc: true}, []string{"10k", "4k7"}, 2},
+		{"filtered page", core.PartQuery{Kind: core.Capacitor, Min: "10n"}, core.PageRequest{Sort: core.SortByValue, Offset: 1, Limit: 1}, []string{"0.1uf"}, 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			page, err := svc.Parts.List(test.query, test.page)
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, names(page.Parts))
+			assert.Equal(t, test.total, page.Total)
+		})
+	}
+
+	t.Run("should sort parts the same as core.SortParts", func(t *testing.T) {
+		all, err := svc.Parts.GetAll()
+		assert.Nil(t, err)
+
+		for _, key := range core.PartSortKeys {
+			for _, desc := range []bool{false, true} {
+				expected := append([]core.Part{}, all...)
+				core.SortParts(expected, key, desc)
+
+				page, err := svc.Parts.List(core.PartQuery{}, core.PageRequest{Sort: key, Desc: desc})
+
+				assert.Nil(t, err)
+				assert.Equal(t, names(expected), names(page.Parts), "sort %s desc %t", key, desc)
+			}
+		}
+	})
+
+	t.Run("should return an error for an invalid page", func(t *testing.T) {
+		_, err := svc.Parts.List(core.PartQuery{}, core.PageRequest{Sort: "parts"})
+
+		assert.IsType(t, core.InvalidPageRequest{}, err)
+	})
+}
+
+func Test_SqliteListKits(t *testing.T) {
+	const dbPath = "./import/dblistkitstest.db"
+	svc, err := CreateSqliteService(dbPath)
+	if err != nil {
+		t.Fatalf("Error creating test service (%s): %s", dbPath, err)
+	}
+	defer testDbDeferredCleanup(t, svc.Parts.(SqlitePartService).db.(*sqlitedb), dbPath)
+
+	parts := []core.Part{}
+	for _, name := range []string{"1k", "2k", "3k"} {
+		part, err := svc.Parts.New(name, core.Resistor)
+		if err != nil {
+			t.Fatalf("Error creating part %s: %s", name, err)
+		}
+
+		parts = append(parts, part)
+	}
+
+	kits := []struct {
+		name  string
+		parts int
+	}{
+		{"Fuzz", 2},
+		{"Delay", 0},
+		{"Overdrive", 3},
+		{"Boost", 1},
+	}
+
+	for _, k := range kits {
+		kit, err := svc.Kits.New(k.name, "", "")
+		if err != nil {
+			t.Fatalf("Error creating kit %s: %s", k.name, err)
+		}
+
+		for _, part := range parts[:k.parts] {
+			err = svc.Kits.AddPart(kit.ID, part.ID, 1)
+			if err != nil {
+				t.Fatalf("Error adding part to kit %s: %s", k.name, err)
+			}
+		}
+
+		_, err = svc.Kits.AddLink(kit.ID, "example.com/"+k.name)
+		if err != nil {
+			t.Fatalf("Error adding link to kit %s: %s", k.name, err)
+		}
+	}
+
+	names := func(kits []core.Kit) []string {
+		out := []string{}
+		for _, k := range kits {
+			out = append(out, k.Name)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		page     core.PageRequest
+		expected []string
+	}{
+		{"every kit by id", core.PageRequest{}, []string{"Fuzz", "Delay", "Overdrive", "Boost"}},
+		{"second page", core.PageRequest{Offset: 2, Limit: 2}, []string{"Overdrive", "Boost"}},
+		{"by name", core.PageRequest{Sort: core.SortByName, Limit: 3}, []string{"Boost", "Delay", "Fuzz"}},
+		{"by part count", core.PageRequest{Sort: core.SortByPartCount}, []string{"Delay", "Boost", "Fuzz", "Overdrive"}},
+		{"by part count descending", core.PageRequest{Sort: core.SortByPartCount, Desc: true, Limit: 1}, []string{"Overdrive"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			page, err := svc.Kits.List(test.page)
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, names(page.Kits))
+			assert.Equal(t, len(kits), page.Total)
+		})
+	}
+
+	t.Run("should return kits the same as Get", func(t *testing.T) {
+		page, err := svc.Kits.List(core.PageRequest{})
+		assert.Nil(t, err)
+
+		for _, kit := range page.Kits {
+			expected, err := svc.Kits.Get(kit.ID)
+
+			assert.Nil(t, err)
+			assert.Equal(t, expected, kit)
+		}
+	})
+
+	t.Run("should return an error for an invalid page", func(t *testing.T) {
+		_, err := svc.Kits.List(core.PageRequest{Sort: core.SortByValue})
+
+		assert.IsType(t, core.InvalidPageRequest{}, err)
+	})
+}
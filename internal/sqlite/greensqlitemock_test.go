@@ -1,6 +1,8 @@
 package sqlite
 
 import (
+	"context"
+
 	"github.com/sombrerosheep/partsbundler/pkg/core"
 )
 
@@ -16,18 +18,21 @@ var FakeParts = [...]core.Part{
 		ID:    1,
 		Kind:  "Resistor",
 		Name:  "1k",
+		Value: "1k",
 		Links: []core.Link(nil),
 	},
 	{
 		ID:    2,
 		Kind:  "Resistor",
 		Name:  "2k",
+		Value: "2k",
 		Links: []core.Link(nil),
 	},
 	{
 		ID:    3,
 		Kind:  "Resistor",
 		Name:  "3k",
+		Value: "3k",
 		Links: []core.Link(nil),
 	},
 }
@@ -35,7 +40,7 @@ var FakeParts = [...]core.Part{
 var FakeKitParts = [...]core.KitPart{
 	{Part: FakeParts[0], Quantity: 1},
 	{Part: FakeParts[1], Quantity: 2},
-	{Part: FakeParts[2], Quantity: 3},
+	{Part: FakeParts[2], Quantity: 3, Designators: []string{"R1", "R2", "R3"}},
 }
 
 var FakeKits = [...]core.Kit{
@@ -61,6 +66,14 @@ type GreenSqliteMock struct {
 	isqlitedb
 }
 
+func (db GreenSqliteMock) WithTx(fn func(tx isqlitedb) error) error {
+	return fn(db)
+}
+
+func (db GreenSqliteMock) WithContext(ctx context.Context) isqlitedb {
+	return db
+}
+
 func (db GreenSqliteMock) GetPart(partId int64) (core.Part, error) {
 	if partId <= int64(len(FakeKitParts)) && partId > 0 {
 		return FakeParts[partId-1], nil
@@ -69,14 +82,57 @@ func (db GreenSqliteMock) GetPart(partId int64) (core.Part, error) {
 	return FakeParts[0], nil
 }
 
+func (db GreenSqliteMock) GetParts(partIds []int64) ([]core.Part, error) {
+	parts := make([]core.Part, len(partIds))
+
+	for i, id := range partIds {
+		parts[i], _ = db.GetPart(id)
+	}
+
+	return parts, nil
+}
+
 func (db GreenSqliteMock) GetAllParts() ([]core.Part, error) {
 	return FakeParts[:], nil
 }
 
+func (db GreenSqliteMock) FindParts(query core.PartQuery, page core.PageRequest) ([]core.Part, int, error) {
+	parts, err := query.Filter(FakeParts[:], func(partId int64) bool {
+		return partId == FakeParts[0].ID
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	start, end := page.Bounds(len(parts))
+
+	return parts[start:end], len(parts), nil
+}
+
 func (db GreenSqliteMock) GetPartLinks(partId int64) ([]core.Link, error) {
 	return FakeLinks[:], nil
 }
 
+func (db GreenSqliteMock) GetLinksForParts(partIds []int64) (map[int64][]core.Link, error) {
+	links := map[int64][]core.Link{}
+
+	for _, id := range partIds {
+		links[id] = FakeLinks[:]
+	}
+
+	return links, nil
+}
+
+func (db GreenSqliteMock) GetAllPartLinks() (map[int64][]core.Link, error) {
+	links := map[int64][]core.Link{}
+
+	for _, p := range FakeParts {
+		links[p.ID] = FakeLinks[:]
+	}
+
+	return links, nil
+}
+
 func (db GreenSqliteMock) AddLinkToPart(link string, partId int64) (int64, error) {
 	return 1, nil
 }
@@ -85,10 +141,14 @@ func (db GreenSqliteMock) RemoveLinkFromPart(linkId, partId int64) error {
 	return nil
 }
 
-func (db GreenSqliteMock) CreatePart(name string, kind core.PartType) (int64, error) {
+func (db GreenSqliteMock) CreatePart(name, value string, kind core.PartType) (int64, error) {
 	return 1, nil
 }
 
+func (db GreenSqliteMock) UpdatePart(partId int64, name, value string, kind core.PartType) error {
+	return nil
+}
+
 func (db GreenSqliteMock) RemovePart(partId int64) error {
 	return nil
 }
@@ -100,11 +160,41 @@ func (db GreenSqliteMock) GetKit(kitId int64) (core.Kit, error) {
 	return FakeKits[0], nil
 }
 
+func (db GreenSqliteMock) GetKits(kitIds []int64) ([]core.Kit, error) {
+	kits := make([]core.Kit, len(kitIds))
+
+	for i, id := range kitIds {
+		kits[i], _ = db.GetKit(id)
+	}
+
+	return kits, nil
+}
+
 func (db GreenSqliteMock) GetKitPartsForKit(kitId int64) ([]kitPartRef, error) {
 	refs := []kitPartRef{
 		{kitId: kitId, partId: 1, quantity: 1},
 		{kitId: kitId, partId: 2, quantity: 2},
-		{kitId: kitId, partId: 3, quantity: 3},
+		{kitId: kitId, partId: 3, quantity: 3, designators: []string{"R1", "R2", "R3"}},
+	}
+
+	return refs, nil
+}
+
+func (db GreenSqliteMock) GetAllKitParts() (map[int64][]kitPartRef, error) {
+	refs := map[int64][]kitPartRef{}
+
+	for _, k := range FakeKits {
+		refs[k.ID], _ = db.GetKitPartsForKit(k.ID)
+	}
+
+	return refs, nil
+}
+
+func (db GreenSqliteMock) GetKitPartsForKits(kitIds []int64) (map[int64][]kitPartRef, error) {
+	refs := map[int64][]kitPartRef{}
+
+	for _, id := range kitIds {
+		refs[id], _ = db.GetKitPartsForKit(id)
 	}
 
 	return refs, nil
@@ -114,6 +204,13 @@ func (db GreenSqliteMock) GetAllKits() ([]core.Kit, error) {
 	return FakeKits[:], nil
 }
 
+func (db GreenSqliteMock) ListKits(page core.PageRequest) ([]core.Kit, int, error) {
+	kits := append([]core.Kit{}, FakeKits[:]...)
+	start, end := page.Bounds(len(kits))
+
+	return kits[start:end], len(kits), nil
+}
+
 func (db GreenSqliteMock) AddPartToKit(partId, kitId int64, quantity uint64) error {
 	return nil
 }
@@ -136,10 +233,34 @@ func (db GreenSqliteMock) RemovePartFromKit(partId, kitId int64) error {
 	return nil
 }
 
+func (db GreenSqliteMock) SetKitPartDesignators(partId, kitId int64, designators []string) error {
+	return nil
+}
+
 func (db GreenSqliteMock) GetKitLinks(kitId int64) ([]core.Link, error) {
 	return FakeLinks[:], nil
 }
 
+func (db GreenSqliteMock) GetAllKitLinks() (map[int64][]core.Link, error) {
+	links := map[int64][]core.Link{}
+
+	for _, k := range FakeKits {
+		links[k.ID] = FakeLinks[:]
+	}
+
+	return links, nil
+}
+
+func (db GreenSqliteMock) GetLinksForKits(kitIds []int64) (map[int64][]core.Link, error) {
+	links := map[int64][]core.Link{}
+
+	for _, id := range kitIds {
+		links[id] = FakeLinks[:]
+	}
+
+	return links, nil
+}
+
 func (db GreenSqliteMock) AddLinkToKit(link string, kitId int64) (int64, error) {
 	return 1, nil
 }
@@ -152,6 +273,52 @@ func (db GreenSqliteMock) CreateKit(name, schematic, diagram string) (int64, err
 	return 1, nil
 }
 
+func (db GreenSqliteMock) UpdateKit(kitId int64, name, schematic, diagram string) error {
+	return nil
+}
+
 func (db GreenSqliteMock) RemoveKit(kitId int64) error {
 	return nil
 }
+
+func (db GreenSqliteMock) MergeParts(partId, duplicateId int64) error {
+	return nil
+}
+
+var FakeStock = [...]core.Stock{
+	{PartID: 1, Quantity: 10, Location: "drawer A1"},
+	{PartID: 2, Quantity: 0, Location: "drawer A2"},
+}
+
+func (db GreenSqliteMock) GetStock(partId int64) (core.Stock, error) {
+	return FakeStock[0], nil
+}
+
+func (db GreenSqliteMock) GetAllStock() ([]core.Stock, error) {
+	return FakeStock[:], nil
+}
+
+func (db GreenSqliteMock) AdjustStock(partId int64, kind core.AdjustmentKind, quantity uint64, note string) (core.Stock, error) {
+	return FakeStock[0].Apply(kind, quantity)
+}
+
+func (db GreenSqliteMock) SetStockLocation(partId int64, location string) (core.Stock, error) {
+	stock := FakeStock[0]
+	stock.Location = location
+
+	return stock, nil
+}
+
+func (db GreenSqliteMock) GetStockAdjustments(partId int64) ([]core.StockAdjustment, error) {
+	return []core.StockAdjustment{}, nil
+}
+
+func (db GreenSqliteMock) ImportKit(spec core.KitSpec) (kitImportRef, error) {
+	ref := kitImportRef{
+		kitId:   FakeKits[0].ID,
+		matched: []int64{FakeParts[0].ID},
+		created: []int64{FakeParts[1].ID, FakeParts[2].ID},
+	}
+
+	return ref, nil
+}
@@ -1,5 +0,0 @@
-drop table parts;
-drop table kits;
-drop table kitparts;
-drop table kitlinks;
-drop table partlinks;
@@ -1,34 +1,34 @@
 -- Parts
-insert into parts(id, kind, name)
+insert into parts(id, kind, name, value)
   values
-    (1, "Resistor", "2.2M"),
-    (2, "Resistor", "1k"),
-    (3, "Resistor", "330k"),
-    (4, "Resistor", "100k"),
-    (5, "Resistor", "470r"),
-    (6, "Resistor", "470k"),
-    (7, "Resistor", "10k"),
-    (8, "Resistor", "47k"),
-    (9, "Resistor", "1k"),
-    (10, "Resistor", "4k7"),
-    (11, "Capacitor", "47nf"),
-    (12, "Capacitor", "1.5nf"),
-    (13, "Capacitor", "4.7uf"),
-    (14, "Capacitor", "47pf"),
-    (15, "Capacitor", "10nf"),
-    (16, "Capacitor", "2.2nf"),
-    (17, "Capacitor", "6.8nf"),
-    (18, "Capacitor", "47uf"),
-    (19, "IC","TL072"),
-    (20, "IC", "CD4066"),
-    (21, "Transistor", "2N3904"),
-    (22, "Diode", "1N4148"),
-    (23, "Diode", "1N5817"),
-    (24, "Potentiometer", "B1k"),
-    (25, "Potentiometer", "B100k"),
-    (26, "Potentiometer", "A10k"),
-    (27, "Switch", "2P4T Rotary"),
-    (28, "Potentiometer", "1k 3362P Trim");
+    (1, "Resistor", "2.2M", "2.2M"),
+    (2, "Resistor", "1k", "1k"),
+    (3, "Resistor", "330k", "330k"),
+    (4, "Resistor", "100k", "100k"),
+    (5, "Resistor", "470r", "470"),
+    (6, "Resistor", "470k", "470k"),
+    (7, "Resistor", "10k", "10k"),
+    (8, "Resistor", "47k", "47k"),
+    (9, "Resistor", "1k", "1k"),
+    (10, "Resistor", "4k7", "4.7k"),
+    (11, "Capacitor", "47nf", "47nF"),
+    (12, "Capacitor", "1.5nf", "1.5nF"),
+    (13, "Capacitor", "4.7uf", "4.7uF"),
+    (14, "Capacitor", "47pf", "47pF"),
+    (15, "Capacitor", "10nf", "10nF"),
+    (16, "Capacitor", "2.2nf", "2.2nF"),
+    (17, "Capacitor", "6.8nf", "6.8nF"),
+    (18, "Capacitor", "47uf", "47uF"),
+    (19, "IC","TL072", "TL072"),
+    (20, "IC", "CD4066", "CD4066"),
+    (21, "Transistor", "2N3904", "2N3904"),
+    (22, "Diode", "1N4148", "1N4148"),
+    (23, "Diode", "1N5817", "1N5817"),
+    (24, "Potentiometer", "B1k", "B1k"),
+    (25, "Potentiometer", "B100k", "B100k"),
+    (26, "Potentiometer", "A10k", "A10k"),
+    (27, "Switch", "2P4T Rotary", "2P4T ROTARY"),
+    (28, "Potentiometer", "1k 3362P Trim", "1K 3362P TRIM");
 
 -- kits
 -- -- ts808
@@ -68,6 +68,6 @@ insert into kitparts(kitId, partId, quantity)
 insert into kitlinks(kitId, link)
   values
     (1, "https://www.pedalpcb.com/product/cheesemonger/"),
-    (2, "https://docs.pedalpcb.com/project/CheeseMonger.pdf");
+    (1, "https://docs.pedalpcb.com/project/CheeseMonger.pdf");
 
 insert into partlinks(partId, link) values(19, "https://www.mouser.com/ProductDetail/Texas-Instruments/TL072CP?qs=5nGYs9Do7G3e6Tx9uHIgUA%3D%3D");
\ No newline at end of file
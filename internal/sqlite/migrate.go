@@ -0,0 +1,279 @@
+package sqlite
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+)
+
+// Migrations are named NNNN_description.sql and applied in order of
+// their version number. Once released a migration must not be edited;
+// schema changes are made by adding a new migration.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+type migration struct {
+	version int
+	name    string
+	stmt    string
+	step    migrationStep
+}
+
+// A migrationStep makes the part of a migration which cannot be written
+// in SQL. It runs after the migration's statements, in the same
+// transaction.
+type migrationStep func(tx *sql.Tx) error
+
+// migrationSteps are keyed by the version of their migration.
+var migrationSteps = map[int]migrationStep{
+	3: backfillPartValues,
+}
+
+type InvalidMigration struct {
+	Name string
+}
+
+func (m InvalidMigration) Error() string {
+	return fmt.Sprintf("Invalid migration '%s'", m.Name)
+}
+
+func loadMigrations() ([]migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := []migration{}
+
+	for _, entry := range entries {
+		name := entry.Name()
+
+		parts := strings.SplitN(strings.TrimSuffix(name, ".sql"), "_", 2)
+		if len(parts) != 2 {
+			return nil, InvalidMigration{name}
+		}
+
+		version, err := strconv.Atoi(parts[0])
+		if err != nil || version <= 0 {
+			return nil, InvalidMigration{name}
+		}
+
+		b, err := migrationFiles.ReadFile(path.Join("migrations", name))
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, migration{
+			version: version,
+			name:    parts[1],
+			stmt:    string(b),
+			step:    migrationSteps[version],
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].version == migrations[i-1].version {
+			return nil, InvalidMigration{migrations[i].name}
+		}
+	}
+
+	return migrations, nil
+}
+
+// SchemaVersion returns the version of the last migration applied to
+// the database, or 0 when none have been.
+func (db sqlitedb) SchemaVersion() (int, error) {
+	const createTable string = `
+		create table if not exists schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied TIMESTAMP NOT NULL
+		)
+	`
+	const query string = `
+		select coalesce(max(version), 0) from schema_version
+	`
+
+	_, err := db.exec(createTable)
+	if err != nil {
+		return 0, err
+	}
+
+	var version int
+	err = db.queryRow(query).Scan(&version)
+
+	return version, err
+}
+
+// Migrate applies the embedded migrations which have not yet been
+// applied to the database.
+func (db sqlitedb) Migrate() error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	return db.migrate(migrations)
+}
+
+func (db sqlitedb) migrate(migrations []migration) error {
+	current, err := db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		err = db.apply(m)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// apply runs a migration in a transaction with foreign keys disabled so
+// that it may rebuild tables, then checks that no foreign key is left
+// broken before committing.
+func (db sqlitedb) apply(m migration) error {
+	const recordVersion string = `
+		insert into schema_version(version, name, applied)
+			values(?, ?, ?)
+	`
+	const checkForeignKeys string = `
+		pragma foreign_key_check
+	`
+
+	ctx := db.context()
+
+	conn, err := db.conn.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, "pragma foreign_keys = off")
+	if err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, "pragma foreign_keys = on")
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(m.stmt)
+	if err == nil && m.step != nil {
+		err = m.step(tx)
+	}
+
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("Error applying migration %04d_%s: %s", m.version, m.name, err)
+	}
+
+	rows, err := tx.Query(checkForeignKeys)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	broken := rows.Next()
+	rows.Close()
+
+	if broken {
+		tx.Rollback()
+		return fmt.Errorf("Error applying migration %04d_%s: foreign key constraint failed", m.version, m.name)
+	}
+
+	_, err = tx.Exec(recordVersion, m.version, m.name, time.Now().UTC())
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// backfillPartValues adds the value column to the parts of databases
+// created before parts had one, and sets the normalized value of the
+// parts saved without one.
+func backfillPartValues(tx *sql.Tx) error {
+	const hasColumn string = `
+		select count(*) from pragma_table_info('parts') where name = 'value'
+	`
+	const addColumn string = `
+		alter table parts add column value TEXT DEFAULT "" NOT NULL
+	`
+	const query string = `
+		select id, kind, name from parts where value = ''
+	`
+	const update string = `
+		update parts set value = ? where id = ?
+	`
+
+	var columns int
+
+	err := tx.QueryRow(hasColumn).Scan(&columns)
+	if err != nil {
+		return err
+	}
+
+	if columns == 0 {
+		_, err = tx.Exec(addColumn)
+		if err != nil {
+			return err
+		}
+	}
+
+	rows, err := tx.Query(query)
+	if err != nil {
+		return err
+	}
+
+	values := map[int64]string{}
+
+	for rows.Next() {
+		var id int64
+		var kind, name string
+
+		err = rows.Scan(&id, &kind, &name)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		values[id] = core.NormalizeValue(core.PartType(kind), name)
+	}
+
+	err = rows.Close()
+	if err != nil {
+		return err
+	}
+
+	for id, value := range values {
+		_, err = tx.Exec(update, value, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,285 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_loadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, migrations)
+
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.version)
+		assert.NotEmpty(t, m.name)
+		assert.NotEmpty(t, m.stmt)
+	}
+}
+
+func Test_SqliteMigrate(t *testing.T) {
+	const dbPath = "./import/dbmigratetest.db"
+	testdb := &sqlitedb{DBFilePath: dbPath}
+
+	err := testdb.Connect()
+	if err != nil {
+		t.Fatalf("Error connecting to test db (%s): %s", dbPath, err)
+	}
+	defer testDbDeferredCleanup(t, testdb, dbPath)
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("Error loading migrations: %s", err)
+	}
+
+	t.Run("should start at version 0", func(t *testing.T) {
+		version, err := testdb.SchemaVersion()
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, version)
+	})
+
+	t.Run("should apply all migrations to a new database", func(t *testing.T) {
+		err := testdb.Migrate()
+
+		assert.Nil(t, err)
+
+		version, err := testdb.SchemaVersion()
+
+		assert.Nil(t, err)
+		assert.Equal(t, migrations[len(migrations)-1].version, version)
+
+		_, err = testdb.GetAllParts()
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("should do nothing when already migrated", func(t *testing.T) {
+		err := testdb.Migrate()
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("should roll back a failed migration", func(t *testing.T) {
+		next := migrations[len(migrations)-1].version + 1
+		failing := append(migrations, migration{
+			version: next,
+			name:    "failing",
+			stmt:    "create table migratetest (id INTEGER PRIMARY KEY); insert into nope values(1);",
+		})
+
+		err := testdb.migrate(failing)
+
+		assert.NotNil(t, err)
+
+		version, err := testdb.SchemaVersion()
+
+		assert.Nil(t, err)
+		assert.Equal(t, next-1, version)
+
+		_, err = testdb.exec("select * from migratetest")
+
+		assert.NotNil(t, err)
+	})
+}
+
+func Test_CreateSqliteDB(t *testing.T) {
+	const dbPath = "./import/dbcreatetest.db"
+
+	stor, err := CreateSqliteDB(dbPath)
+	if err != nil {
+		t.Fatalf("Error creating test db (%s): %s", dbPath, err)
+	}
+	defer testDbDeferredCleanup(t, stor.(*sqlitedb), dbPath)
+
+	kits, err := stor.GetAllKits()
+
+	assert.Nil(t, err)
+	assert.Empty(t, kits)
+}
+
+func Test_CreateSqliteDBWithOptions(t *testing.T) {
+	const dbPath = "./import/dboptionstest.db"
+
+	stor, err := CreateSqliteDB("file:" + dbPath + "?cache=shared&_busy_timeout=5000")
+	if err != nil {
+		t.Fatalf("Error creating test db (%s): %s", dbPath, err)
+	}
+	defer testDbDeferredCleanup(t, stor.(*sqlitedb), dbPath)
+
+	var enabled bool
+	err = stor.(*sqlitedb).queryRow("pragma foreign_keys").Scan(&enabled)
+
+	assert.Nil(t, err)
+	assert.True(t, enabled)
+}
+
+func Test_SqliteMigrateForeignKeys(t *testing.T) {
+	const dbPath = "./import/dbmigratefktest.db"
+	testdb := &sqlitedb{DBFilePath: dbPath}
+
+	err := testdb.Connect()
+	if err != nil {
+		t.Fatalf("Error connecting to test db (%s): %s", dbPath, err)
+	}
+	defer testDbDeferredCleanup(t, testdb, dbPath)
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("Error loading migrations: %s", err)
+	}
+
+	var before []migration
+	for _, m := range migrations {
+		if m.name == "foreign_keys" {
+			break
+		}
+
+		before = append(before, m)
+	}
+
+	err = testdb.migrate(before)
+	if err != nil {
+		t.Fatalf("Error applying migrations before foreign keys: %s", err)
+	}
+
+	const legacy string = `
+		insert into parts(id, kind, name, value) values (1, "Resistor", "1k", "1k");
+		insert into kits(id, name) values (1, "kit");
+		insert into kitparts(id, partId, kitId, quantity) values
+			(1, 1, 1, 2), (2, 1, 1, 3), (3, 1, 2, 1), (4, 2, 1, 1);
+		insert into kitpartdesignators(kitPartId, designator) values
+			(1, "R1"), (2, "R2"), (3, "R3");
+		insert into kitlinks(kitId, link) values (1, "example.com"), (2, "example.com");
+		insert into partlinks(partId, link) values (1, "example.com"), (2, "example.com");
+	`
+
+	_, err = testdb.exec(legacy)
+	if err != nil {
+		t.Fatalf("Error inserting legacy rows: %s", err)
+	}
+
+	err = testdb.migrate(migrations)
+
+	assert.Nil(t, err)
+
+	t.Run("should combine parts added to a kit more than once", func(t *testing.T) {
+		refs, err := testdb.GetKitPartsForKit(1)
+
+		expected := []kitPartRef{
+			{kitId: 1, partId: 1, quantity: 5, designators: []string{"R1", "R2"}},
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, refs)
+	})
+
+	t.Run("should remove orphaned rows", func(t *testing.T) {
+		counts := map[string]int{
+			"select count(*) from kitparts":           1,
+			"select count(*) from kitpartdesignators": 2,
+			"select count(*) from kitlinks":           1,
+			"select count(*) from partlinks":          1,
+		}
+
+		for query, expected := range counts {
+			var count int
+			err := testdb.queryRow(query).Scan(&count)
+
+			assert.Nil(t, err)
+			assert.Equal(t, expected, count, query)
+		}
+	})
+
+	t.Run("should enforce foreign keys", func(t *testing.T) {
+		_, err := testdb.exec("insert into kitlinks(kitId, link) values (99, 'example.com')")
+
+		assert.NotNil(t, err)
+	})
+}
+
+func Test_SqliteMigrateBaseline(t *testing.T) {
+	const dbPath = "./import/dbmigratebaselinetest.db"
+	testdb := &sqlitedb{DBFilePath: dbPath}
+
+	err := testdb.Connect()
+	if err != nil {
+		t.Fatalf("Error connecting to test db (%s): %s", dbPath, err)
+	}
+	defer testDbDeferredCleanup(t, testdb, dbPath)
+
+	// databases made before migrations were added were created by hand
+	// from setup.sql and have no schema_version table
+	const setup string = `
+		create table parts (id INTEGER PRIMARY KEY, kind TEXT NOT NULL, name TEXT NOT NULL);
+		create table kits (
+			id INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			schematic TEXT DEFAULT "" NOT NULL,
+			diagram TEXT DEFAULT "" NOT NULL
+		);
+		create table kitparts (
+			id INTEGER PRIMARY KEY,
+			partId INTEGER NOT NULL,
+			kitId INTEGER NOT NULL,
+			quantity UNSIGNED BIG INT NOT NULL
+		);
+		create table kitlinks (id INTEGER PRIMARY KEY, kitId INTEGER NOT NULL, link TEXT NOT NULL);
+		create table partlinks (id INTEGER PRIMARY KEY, partId INTEGER NOT NULL, link TEXT NOT NULL);
+	`
+	const legacy string = `
+		insert into parts(id, kind, name) values (1, "Resistor", "4k7"), (2, "IC", "TL072");
+		insert into kits(id, name) values (1, "kit");
+		insert into kitparts(id, partId, kitId, quantity) values (1, 1, 1, 2);
+	`
+
+	_, err = testdb.exec(setup + legacy)
+	if err != nil {
+		t.Fatalf("Error creating baseline db: %s", err)
+	}
+
+	err = testdb.Migrate()
+
+	assert.Nil(t, err)
+
+	t.Run("should keep the existing rows", func(t *testing.T) {
+		parts, err := testdb.GetAllParts()
+
+		assert.Nil(t, err)
+		assert.Len(t, parts, 2)
+
+		refs, err := testdb.GetKitPartsForKit(1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []kitPartRef{{kitId: 1, partId: 1, quantity: 2}}, refs)
+	})
+
+	t.Run("should fill in the value of existing parts", func(t *testing.T) {
+		parts, err := testdb.GetAllParts()
+
+		assert.Nil(t, err)
+		assert.Equal(t, "4.7k", parts[0].Value)
+		assert.Equal(t, "TL072", parts[1].Value)
+
+		ref, err := testdb.ImportKit(core.KitSpec{
+			Name:  "imported",
+			Parts: []core.KitPartSpec{{Kind: core.Resistor, Name: "4.7K", Quantity: 1}},
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []int64{1}, ref.matched)
+	})
+
+	t.Run("should add the tables introduced since", func(t *testing.T) {
+		_, err := testdb.GetStock(1)
+
+		assert.Nil(t, err)
+
+		err = testdb.SetKitPartDesignators(1, 1, []string{"R1", "R2"})
+
+		assert.Nil(t, err)
+	})
+}
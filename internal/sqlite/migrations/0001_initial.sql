@@ -2,7 +2,8 @@
 CREATE TABLE IF NOT EXISTS parts (
   id INTEGER PRIMARY KEY, 
   kind TEXT NOT NULL,
-  name TEXT NOT NULL
+  name TEXT NOT NULL,
+  value TEXT DEFAULT "" NOT NULL
 );
 -- kit
 CREATE TABLE IF NOT EXISTS kits (
@@ -18,6 +19,12 @@ CREATE TABLE IF NOT EXISTS kitparts (
   kitId INTEGER NOT NULL, 
   quantity UNSIGNED BIG INT NOT NULL
 );
+-- kit part reference designators
+CREATE TABLE IF NOT EXISTS kitpartdesignators (
+  id INTEGER PRIMARY KEY,
+  kitPartId INTEGER NOT NULL,
+  designator TEXT NOT NULL
+);
 -- kit links
 CREATE TABLE IF NOT EXISTS kitlinks (
   id INTEGER PRIMARY KEY,
@@ -30,3 +37,18 @@ CREATE TABLE IF NOT EXISTS partlinks (
   partId INTEGER NOT NULL, 
   link TEXT NOT NULL
 );
+-- part stock on hand
+CREATE TABLE IF NOT EXISTS stock (
+  partId INTEGER PRIMARY KEY,
+  quantity UNSIGNED BIG INT DEFAULT 0 NOT NULL,
+  location TEXT DEFAULT "" NOT NULL
+);
+-- part stock history
+CREATE TABLE IF NOT EXISTS stockadjustments (
+  id INTEGER PRIMARY KEY,
+  partId INTEGER NOT NULL,
+  kind TEXT NOT NULL,
+  quantity UNSIGNED BIG INT NOT NULL,
+  note TEXT DEFAULT "" NOT NULL,
+  created TIMESTAMP NOT NULL
+);
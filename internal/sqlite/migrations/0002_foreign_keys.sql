@@ -0,0 +1,91 @@
+-- remove rows left behind by deletes made before foreign keys were enforced
+DELETE FROM kitparts
+  WHERE kitId NOT IN (SELECT id FROM kits)
+    OR partId NOT IN (SELECT id FROM parts);
+DELETE FROM kitpartdesignators WHERE kitPartId NOT IN (SELECT id FROM kitparts);
+DELETE FROM kitlinks WHERE kitId NOT IN (SELECT id FROM kits);
+DELETE FROM partlinks WHERE partId NOT IN (SELECT id FROM parts);
+DELETE FROM stock WHERE partId NOT IN (SELECT id FROM parts);
+DELETE FROM stockadjustments WHERE partId NOT IN (SELECT id FROM parts);
+-- combine parts added to the same kit more than once
+UPDATE kitparts
+  SET quantity = (
+    SELECT sum(dup.quantity) FROM kitparts dup
+      WHERE dup.kitId = kitparts.kitId AND dup.partId = kitparts.partId
+  )
+  WHERE id IN (
+    SELECT min(id) FROM kitparts GROUP BY kitId, partId HAVING count(*) > 1
+  );
+UPDATE kitpartdesignators
+  SET kitPartId = (
+    SELECT min(kp.id) FROM kitparts kp, kitparts dup
+      WHERE dup.id = kitpartdesignators.kitPartId
+        AND kp.kitId = dup.kitId AND kp.partId = dup.partId
+  );
+DELETE FROM kitparts
+  WHERE id NOT IN (SELECT min(id) FROM kitparts GROUP BY kitId, partId);
+-- kit part associations
+CREATE TABLE kitparts_new (
+  id INTEGER PRIMARY KEY,
+  partId INTEGER NOT NULL REFERENCES parts(id),
+  kitId INTEGER NOT NULL REFERENCES kits(id) ON DELETE CASCADE,
+  quantity UNSIGNED BIG INT NOT NULL,
+  UNIQUE (kitId, partId)
+);
+INSERT INTO kitparts_new(id, partId, kitId, quantity)
+  SELECT id, partId, kitId, quantity FROM kitparts;
+DROP TABLE kitparts;
+ALTER TABLE kitparts_new RENAME TO kitparts;
+-- kit part reference designators
+CREATE TABLE kitpartdesignators_new (
+  id INTEGER PRIMARY KEY,
+  kitPartId INTEGER NOT NULL REFERENCES kitparts(id) ON DELETE CASCADE,
+  designator TEXT NOT NULL
+);
+INSERT INTO kitpartdesignators_new(id, kitPartId, designator)
+  SELECT id, kitPartId, designator FROM kitpartdesignators;
+DROP TABLE kitpartdesignators;
+ALTER TABLE kitpartdesignators_new RENAME TO kitpartdesignators;
+-- kit links
+CREATE TABLE kitlinks_new (
+  id INTEGER PRIMARY KEY,
+  kitId INTEGER NOT NULL REFERENCES kits(id) ON DELETE CASCADE,
+  link TEXT NOT NULL
+);
+INSERT INTO kitlinks_new(id, kitId, link)
+  SELECT id, kitId, link FROM kitlinks;
+DROP TABLE kitlinks;
+ALTER TABLE kitlinks_new RENAME TO kitlinks;
+-- part links
+CREATE TABLE partlinks_new (
+  id INTEGER PRIMARY KEY,
+  partId INTEGER NOT NULL REFERENCES parts(id) ON DELETE CASCADE,
+  link TEXT NOT NULL
+);
+INSERT INTO partlinks_new(id, partId, link)
+  SELECT id, partId, link FROM partlinks;
+DROP TABLE partlinks;
+ALTER TABLE partlinks_new RENAME TO partlinks;
+-- part stock on hand
+CREATE TABLE stock_new (
+  partId INTEGER PRIMARY KEY REFERENCES parts(id) ON DELETE CASCADE,
+  quantity UNSIGNED BIG INT DEFAULT 0 NOT NULL,
+  location TEXT DEFAULT "" NOT NULL
+);
+INSERT INTO stock_new(partId, quantity, location)
+  SELECT partId, quantity, location FROM stock;
+DROP TABLE stock;
+ALTER TABLE stock_new RENAME TO stock;
+-- part stock history
+CREATE TABLE stockadjustments_new (
+  id INTEGER PRIMARY KEY,
+  partId INTEGER NOT NULL REFERENCES parts(id) ON DELETE CASCADE,
+  kind TEXT NOT NULL,
+  quantity UNSIGNED BIG INT NOT NULL,
+  note TEXT DEFAULT "" NOT NULL,
+  created TIMESTAMP NOT NULL
+);
+INSERT INTO stockadjustments_new(id, partId, kind, quantity, note, created)
+  SELECT id, partId, kind, quantity, note, created FROM stockadjustments;
+DROP TABLE stockadjustments;
+ALTER TABLE stockadjustments_new RENAME TO stockadjustments;
@@ -0,0 +1,4 @@
+-- databases created before migrations were added have no parts.value
+-- column; backfillPartValues in migrate.go adds it when it is missing,
+-- since sqlite cannot add a column only when it does not exist, and
+-- fills in the value of parts saved without one
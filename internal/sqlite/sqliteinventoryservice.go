@@ -0,0 +1,44 @@
+package sqlite
+
+import (
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+)
+
+type SqliteInventoryService struct {
+	db isqlitedb
+}
+
+func (service SqliteInventoryService) GetAll() ([]core.Stock, error) {
+	return service.db.GetAllStock()
+}
+
+func (service SqliteInventoryService) Get(partId int64) (core.Stock, error) {
+	stock, err := service.db.GetStock(partId)
+	if err != nil {
+		return core.Stock{}, err
+	}
+
+	return stock, nil
+}
+
+func (service SqliteInventoryService) Adjust(partId int64, kind core.AdjustmentKind, quantity uint64, note string) (core.Stock, error) {
+	stock, err := service.db.AdjustStock(partId, kind, quantity, note)
+	if err != nil {
+		return core.Stock{}, err
+	}
+
+	return stock, nil
+}
+
+func (service SqliteInventoryService) SetLocation(partId int64, location string) (core.Stock, error) {
+	stock, err := service.db.SetStockLocation(partId, location)
+	if err != nil {
+		return core.Stock{}, err
+	}
+
+	return stock, nil
+}
+
+func (service SqliteInventoryService) GetHistory(partId int64) ([]core.StockAdjustment, error) {
+	return service.db.GetStockAdjustments(partId)
+}
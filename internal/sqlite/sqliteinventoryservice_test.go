@@ -0,0 +1,76 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_sqliteinventoryservice_GetAll(t *testing.T) {
+	t.Run("When no errors are returned", func(t *testing.T) {
+		sut := SqliteInventoryService{
+			db: GreenSqliteMock{},
+		}
+
+		stock, err := sut.GetAll()
+
+		assert.Nil(t, err)
+		assert.Equal(t, FakeStock[:], stock)
+	})
+}
+
+func Test_sqliteinventoryservice_Get(t *testing.T) {
+	t.Run("When no errors are returned", func(t *testing.T) {
+		sut := SqliteInventoryService{
+			db: GreenSqliteMock{},
+		}
+
+		stock, err := sut.Get(FakeStock[0].PartID)
+
+		assert.Nil(t, err)
+		assert.Equal(t, FakeStock[0], stock)
+	})
+}
+
+func Test_sqliteinventoryservice_Adjust(t *testing.T) {
+	t.Run("When no errors are returned", func(t *testing.T) {
+		sut := SqliteInventoryService{
+			db: GreenSqliteMock{},
+		}
+
+		expected := FakeStock[0]
+		expected.Quantity += 5
+
+		stock, err := sut.Adjust(FakeStock[0].PartID, core.Received, 5, "")
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, stock)
+	})
+}
+
+func Test_sqliteinventoryservice_SetLocation(t *testing.T) {
+	t.Run("When no errors are returned", func(t *testing.T) {
+		sut := SqliteInventoryService{
+			db: GreenSqliteMock{},
+		}
+
+		stock, err := sut.SetLocation(FakeStock[0].PartID, "bin 7")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "bin 7", stock.Location)
+	})
+}
+
+func Test_sqliteinventoryservice_GetHistory(t *testing.T) {
+	t.Run("When no errors are returned", func(t *testing.T) {
+		sut := SqliteInventoryService{
+			db: GreenSqliteMock{},
+		}
+
+		history, err := sut.GetHistory(FakeStock[0].PartID)
+
+		assert.Nil(t, err)
+		assert.Len(t, history, 0)
+	})
+}
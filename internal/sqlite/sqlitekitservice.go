@@ -1,6 +1,8 @@
 package sqlite
 
 import (
+	"context"
+
 	"github.com/sombrerosheep/partsbundler/pkg/core"
 	"github.com/sombrerosheep/partsbundler/pkg/service"
 )
@@ -16,20 +18,54 @@ func (service SqliteKitService) getKitParts(kitId int64) ([]core.KitPart, error)
 		return nil, err
 	}
 
-	kitParts := make([]core.KitPart, len(partRefs))
+	kitParts, err := service.loadKitParts(map[int64][]kitPartRef{kitId: partRefs})
+	if err != nil {
+		return nil, err
+	}
+
+	return kitParts[kitId], nil
+}
 
-	for i, partRef := range partRefs {
-		kitPart := core.KitPart{
-			Quantity: partRef.quantity,
+// loadKitParts resolves the parts referenced by refs with a single
+// batched lookup and returns the kit parts keyed by kit id. Every kit in
+// refs gets a non-nil list.
+func (service SqliteKitService) loadKitParts(refs map[int64][]kitPartRef) (map[int64][]core.KitPart, error) {
+	ids := []int64{}
+	seen := map[int64]bool{}
+
+	for _, partRefs := range refs {
+		for _, partRef := range partRefs {
+			if !seen[partRef.partId] {
+				seen[partRef.partId] = true
+				ids = append(ids, partRef.partId)
+			}
 		}
+	}
 
-		part, err := service.partservice.Get(partRef.partId)
-		if err != nil {
-			return nil, err
+	parts, err := service.partservice.GetParts(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[int64]core.Part, len(parts))
+	for _, part := range parts {
+		byId[part.ID] = part
+	}
+
+	kitParts := make(map[int64][]core.KitPart, len(refs))
+
+	for kitId, partRefs := range refs {
+		list := make([]core.KitPart, len(partRefs))
+
+		for i, partRef := range partRefs {
+			list[i] = core.KitPart{
+				Part:        byId[partRef.partId],
+				Quantity:    partRef.quantity,
+				Designators: partRef.designators,
+			}
 		}
 
-		kitPart.Part = part
-		kitParts[i] = kitPart
+		kitParts[kitId] = list
 	}
 
 	return kitParts, nil
@@ -41,20 +77,78 @@ func (service SqliteKitService) GetAll() ([]core.Kit, error) {
 		return nil, err
 	}
 
-	for i := range kits {
-		kitParts, err := service.getKitParts(kits[i].ID)
-		if err != nil {
-			return nil, err
-		}
+	partRefs, err := service.db.GetAllKitParts()
+	if err != nil {
+		return nil, err
+	}
 
-		kits[i].Parts = kitParts
+	kitLinks, err := service.db.GetAllKitLinks()
+	if err != nil {
+		return nil, err
+	}
 
-		kitLinks, err := service.db.GetKitLinks(kits[i].ID)
-		if err != nil {
-			return nil, err
-		}
+	return service.assembleKits(kits, partRefs, kitLinks)
+}
+
+func (service SqliteKitService) List(page core.PageRequest) (core.KitPage, error) {
+	err := page.Validate(core.KitSortKeys)
+	if err != nil {
+		return core.KitPage{}, err
+	}
+
+	kits, total, err := service.db.ListKits(page)
+	if err != nil {
+		return core.KitPage{}, err
+	}
+
+	ids := make([]int64, len(kits))
+	for i, kit := range kits {
+		ids[i] = kit.ID
+	}
+
+	partRefs, err := service.db.GetKitPartsForKits(ids)
+	if err != nil {
+		return core.KitPage{}, err
+	}
+
+	kitLinks, err := service.db.GetLinksForKits(ids)
+	if err != nil {
+		return core.KitPage{}, err
+	}
+
+	kits, err = service.assembleKits(kits, partRefs, kitLinks)
+	if err != nil {
+		return core.KitPage{}, err
+	}
+
+	return core.KitPage{
+		Kits:   kits,
+		Total:  total,
+		Offset: page.Offset,
+		Limit:  page.Limit,
+	}, nil
+}
+
+// assembleKits fills in the parts and links of kits from the part
+// references and links loaded for them.
+func (service SqliteKitService) assembleKits(kits []core.Kit, partRefs map[int64][]kitPartRef, kitLinks map[int64][]core.Link) ([]core.Kit, error) {
+	refs := make(map[int64][]kitPartRef, len(kits))
+	for _, kit := range kits {
+		refs[kit.ID] = partRefs[kit.ID]
+	}
+
+	kitParts, err := service.loadKitParts(refs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range kits {
+		kits[i].Parts = kitParts[kits[i].ID]
 
-		kits[i].Links = kitLinks
+		kits[i].Links = kitLinks[kits[i].ID]
+		if kits[i].Links == nil {
+			kits[i].Links = []core.Link{}
+		}
 	}
 
 	return kits, nil
@@ -86,6 +180,11 @@ func (service SqliteKitService) AddLink(kitId int64, link string) (core.Link, er
 		URL: link,
 	}
 
+	err := l.Validate()
+	if err != nil {
+		return core.Link{}, err
+	}
+
 	id, err := service.db.AddLinkToKit(link, kitId)
 	if err != nil {
 		return l, err
@@ -101,6 +200,11 @@ func (service SqliteKitService) RemoveLink(kitId int64, linkId int64) error {
 }
 
 func (service SqliteKitService) AddPart(kitId, partId int64, quantity uint64) error {
+	err := core.ValidateQuantity(quantity)
+	if err != nil {
+		return err
+	}
+
 	return service.db.AddPartToKit(partId, kitId, quantity)
 }
 
@@ -114,9 +218,18 @@ func (service SqliteKitService) GetPartUsage(partId int64) ([]int64, error) {
 }
 
 func (service SqliteKitService) SetPartQuantity(kitId int64, partId int64, quantity uint64) error {
+	err := core.ValidateQuantity(quantity)
+	if err != nil {
+		return err
+	}
+
 	return service.db.UpdatePartQuantity(partId, kitId, quantity)
 }
 
+func (service SqliteKitService) SetPartDesignators(kitId, partId int64, designators []string) error {
+	return service.db.SetKitPartDesignators(partId, kitId, designators)
+}
+
 func (service SqliteKitService) RemovePart(kitId, partId int64) error {
 	return service.db.RemovePartFromKit(partId, kitId)
 }
@@ -131,6 +244,11 @@ func (service SqliteKitService) New(name string, schematic string, diagram strin
 		Links:     []core.Link{},
 	}
 
+	err := kit.Validate()
+	if err != nil {
+		return kit, err
+	}
+
 	kitId, err := service.db.CreateKit(name, schematic, diagram)
 	if err != nil {
 		return kit, err
@@ -141,16 +259,199 @@ func (service SqliteKitService) New(name string, schematic string, diagram strin
 	return kit, nil
 }
 
+func (service SqliteKitService) Update(kitId int64, name, schematic, diagram string) (core.Kit, error) {
+	err := core.Kit{Name: name, Schematic: schematic, Diagram: diagram}.Validate()
+	if err != nil {
+		return core.Kit{}, err
+	}
+
+	err = service.db.UpdateKit(kitId, name, schematic, diagram)
+	if err != nil {
+		return core.Kit{}, err
+	}
+
+	return service.Get(kitId)
+}
+
+func (service SqliteKitService) Patch(kitId int64, patch core.KitPatch) (core.Kit, error) {
+	var kit core.Kit
+
+	err := service.db.WithTx(func(tx isqlitedb) error {
+		txservice := SqliteKitService{
+			db:          tx,
+			partservice: SqlitePartService{db: tx},
+		}
+
+		current, err := tx.GetKit(kitId)
+		if err != nil {
+			return err
+		}
+
+		patched := patch.Apply(current)
+
+		kit, err = txservice.Update(kitId, patched.Name, patched.Schematic, patched.Diagram)
+
+		return err
+	})
+	if err != nil {
+		return core.Kit{}, err
+	}
+
+	return kit, nil
+}
+
 func (service SqliteKitService) Delete(kitId int64) error {
 	return service.db.RemoveKit(kitId)
 }
 
+// Plan loads the kits of every build, and their parts, with a fixed
+// number of batched queries however many kits are built.
+func (service SqliteKitService) Plan(builds []core.KitBuild, onHand map[int64]uint64) (core.Plan, error) {
+	ids := []int64{}
+	seen := map[int64]bool{}
+
+	for _, build := range builds {
+		if !seen[build.KitID] {
+			seen[build.KitID] = true
+			ids = append(ids, build.KitID)
+		}
+	}
+
+	kits, err := service.db.GetKits(ids)
+	if err != nil {
+		return core.Plan{}, err
+	}
+
+	partRefs, err := service.db.GetKitPartsForKits(ids)
+	if err != nil {
+		return core.Plan{}, err
+	}
+
+	kits, err = service.assembleKits(kits, partRefs, nil)
+	if err != nil {
+		return core.Plan{}, err
+	}
+
+	byId := make(map[int64]core.Kit, len(kits))
+	for _, kit := range kits {
+		byId[kit.ID] = kit
+	}
+
+	return core.NewPlan(builds, byId, onHand)
+}
+
+func (service SqliteKitService) Import(spec core.KitSpec) (core.KitImport, error) {
+	ref, err := service.db.ImportKit(spec)
+	if err != nil {
+		return core.KitImport{}, err
+	}
+
+	kit, err := service.Get(ref.kitId)
+	if err != nil {
+		return core.KitImport{}, err
+	}
+
+	matched, err := service.partservice.GetParts(ref.matched)
+	if err != nil {
+		return core.KitImport{}, err
+	}
+
+	created, err := service.partservice.GetParts(ref.created)
+	if err != nil {
+		return core.KitImport{}, err
+	}
+
+	result := core.KitImport{
+		Kit:     kit,
+		Matched: matched,
+		Created: created,
+	}
+
+	return result, nil
+}
+
+// withContext returns a copy of the service whose queries run with ctx.
+func (service SqliteKitService) withContext(ctx context.Context) SqliteKitService {
+	return SqliteKitService{
+		db:          service.db.WithContext(ctx),
+		partservice: service.partservice.withContext(ctx),
+	}
+}
+
+func (service SqliteKitService) GetAllContext(ctx context.Context) ([]core.Kit, error) {
+	return service.withContext(ctx).GetAll()
+}
+
+func (service SqliteKitService) ListContext(ctx context.Context, page core.PageRequest) (core.KitPage, error) {
+	return service.withContext(ctx).List(page)
+}
+
+func (service SqliteKitService) GetContext(ctx context.Context, kitId int64) (core.Kit, error) {
+	return service.withContext(ctx).Get(kitId)
+}
+
+func (service SqliteKitService) AddLinkContext(ctx context.Context, kitId int64, link string) (core.Link, error) {
+	return service.withContext(ctx).AddLink(kitId, link)
+}
+
+func (service SqliteKitService) RemoveLinkContext(ctx context.Context, kitId int64, linkId int64) error {
+	return service.withContext(ctx).RemoveLink(kitId, linkId)
+}
+
+func (service SqliteKitService) AddPartContext(ctx context.Context, kitId, partId int64, quantity uint64) error {
+	return service.withContext(ctx).AddPart(kitId, partId, quantity)
+}
+
+func (service SqliteKitService) GetPartUsageContext(ctx context.Context, partId int64) ([]int64, error) {
+	return service.withContext(ctx).GetPartUsage(partId)
+}
+
+func (service SqliteKitService) SetPartQuantityContext(ctx context.Context, kitId int64, partId int64, quantity uint64) error {
+	return service.withContext(ctx).SetPartQuantity(kitId, partId, quantity)
+}
+
+func (service SqliteKitService) SetPartDesignatorsContext(ctx context.Context, kitId, partId int64, designators []string) error {
+	return service.withContext(ctx).SetPartDesignators(kitId, partId, designators)
+}
+
+func (service SqliteKitService) RemovePartContext(ctx context.Context, kitId, partId int64) error {
+	return service.withContext(ctx).RemovePart(kitId, partId)
+}
+
+func (service SqliteKitService) NewContext(ctx context.Context, name string, schematic string, diagram string) (core.Kit, error) {
+	return service.withContext(ctx).New(name, schematic, diagram)
+}
+
+func (service SqliteKitService) UpdateContext(ctx context.Context, kitId int64, name, schematic, diagram string) (core.Kit, error) {
+	return service.withContext(ctx).Update(kitId, name, schematic, diagram)
+}
+
+func (service SqliteKitService) PatchContext(ctx context.Context, kitId int64, patch core.KitPatch) (core.Kit, error) {
+	return service.withContext(ctx).Patch(kitId, patch)
+}
+
+func (service SqliteKitService) DeleteContext(ctx context.Context, kitId int64) error {
+	return service.withContext(ctx).Delete(kitId)
+}
+
+func (service SqliteKitService) PlanContext(ctx context.Context, builds []core.KitBuild, onHand map[int64]uint64) (core.Plan, error) {
+	return service.withContext(ctx).Plan(builds, onHand)
+}
+
+func (service SqliteKitService) ImportContext(ctx context.Context, spec core.KitSpec) (core.KitImport, error) {
+	return service.withContext(ctx).Import(spec)
+}
+
 func CreateSqliteService(dbPath string) (*service.BundlerService, error) {
 	stor, err := CreateSqliteDB(dbPath)
 	if err != nil {
 		return nil, err
 	}
 
+	return newSqliteService(stor), nil
+}
+
+func newSqliteService(stor isqlitedb) *service.BundlerService {
 	parts := SqlitePartService{
 		db: stor,
 	}
@@ -158,11 +459,23 @@ func CreateSqliteService(dbPath string) (*service.BundlerService, error) {
 		db:          stor,
 		partservice: parts,
 	}
+	inventory := SqliteInventoryService{
+		db: stor,
+	}
+	transactor := SqliteTransactor{
+		db: stor,
+	}
+	storage := SqliteStorage{
+		db: stor,
+	}
 
 	svc := &service.BundlerService{
-		Parts: parts,
-		Kits:  kits,
+		Parts:      parts,
+		Kits:       kits,
+		Inventory:  inventory,
+		Transactor: transactor,
+		Storage:    storage,
 	}
 
-	return svc, nil
+	return svc
 }
@@ -40,6 +40,39 @@ func Test_sqlitekitservice_GetAll(t *testing.T) {
 	})
 }
 
+func Test_sqlitekitservice_List(t *testing.T) {
+	t.Run("When no errors are returned", func(t *testing.T) {
+		sut := SqliteKitService{
+			db: GreenSqliteMock{},
+			partservice: SqlitePartService{
+				db: GreenSqliteMock{},
+			},
+		}
+
+		expected, err := sut.GetAll()
+		assert.Nil(t, err)
+
+		page, err := sut.List(core.PageRequest{Offset: 1, Limit: 1})
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected[1:2], page.Kits)
+		assert.Equal(t, len(FakeKits), page.Total)
+	})
+
+	t.Run("should return an error for an invalid page", func(t *testing.T) {
+		sut := SqliteKitService{
+			db: GreenSqliteMock{},
+			partservice: SqlitePartService{
+				db: GreenSqliteMock{},
+			},
+		}
+
+		_, err := sut.List(core.PageRequest{Sort: core.SortByKind})
+
+		assert.IsType(t, core.InvalidPageRequest{}, err)
+	})
+}
+
 func Test_sqlitekitservice_Get(t *testing.T) {
 	t.Run("When no errors are returned", func(t *testing.T) {
 		sut := SqliteKitService{
@@ -152,6 +185,34 @@ func Test_sqlitekitservice_SetPartQuantity(t *testing.T) {
 
 		assert.Nil(t, err)
 	})
+
+	t.Run("should reject a zero quantity", func(t *testing.T) {
+		sut := SqliteKitService{
+			db: GreenSqliteMock{},
+			partservice: SqlitePartService{
+				db: GreenSqliteMock{},
+			},
+		}
+
+		err := sut.SetPartQuantity(FakeKits[0].ID, FakeParts[0].ID, 0)
+
+		assert.Equal(t, core.InvalidField("quantity", "must be greater than zero"), err)
+	})
+}
+
+func Test_sqlitekitservice_SetPartDesignators(t *testing.T) {
+	t.Run("When no errors are returned", func(t *testing.T) {
+		sut := SqliteKitService{
+			db: GreenSqliteMock{},
+			partservice: SqlitePartService{
+				db: GreenSqliteMock{},
+			},
+		}
+
+		err := sut.SetPartDesignators(FakeKits[0].ID, FakeParts[2].ID, []string{"R1", "R2", "R3"})
+
+		assert.Nil(t, err)
+	})
 }
 
 func Test_sqlitekitservice_RemovePart(t *testing.T) {
@@ -192,6 +253,19 @@ func Test_sqlitekitservice_New(t *testing.T) {
 		assert.Nil(t, err)
 		assert.Equal(t, expectedKit, kit)
 	})
+
+	t.Run("should reject a kit without a name", func(t *testing.T) {
+		sut := SqliteKitService{
+			db: GreenSqliteMock{},
+			partservice: SqlitePartService{
+				db: GreenSqliteMock{},
+			},
+		}
+
+		_, err := sut.New("", FakeKits[0].Schematic, FakeKits[0].Diagram)
+
+		assert.Equal(t, core.InvalidField("name", "must not be empty"), err)
+	})
 }
 
 func Test_sqlitekitservice_Delete(t *testing.T) {
@@ -208,3 +282,100 @@ func Test_sqlitekitservice_Delete(t *testing.T) {
 		assert.Nil(t, err)
 	})
 }
+
+func Test_sqlitekitservice_Plan(t *testing.T) {
+	t.Run("When no errors are returned", func(t *testing.T) {
+		sut := SqliteKitService{
+			db: GreenSqliteMock{},
+			partservice: SqlitePartService{
+				db: GreenSqliteMock{},
+			},
+		}
+
+		builds := []core.KitBuild{
+			{KitID: FakeKits[0].ID, Count: 2},
+			{KitID: FakeKits[1].ID, Count: 1},
+		}
+		onHand := map[int64]uint64{FakeParts[0].ID: 1}
+
+		expectedItems := make([]core.PlanItem, len(FakeKitParts))
+		for i := range FakeKitParts {
+			p := FakeKitParts[i].Part
+			p.Links = FakeLinks[:]
+			required := FakeKitParts[i].Quantity * 3
+
+			expectedItems[i] = core.PlanItem{
+				Part:      p,
+				Required:  required,
+				OnHand:    onHand[p.ID],
+				Shortfall: required - onHand[p.ID],
+			}
+		}
+
+		plan, err := sut.Plan(builds, onHand)
+
+		assert.Nil(t, err)
+		assert.Equal(t, builds, plan.Builds)
+		assert.Equal(t, expectedItems, plan.Items)
+	})
+}
+
+func Test_sqlitekitservice_Import(t *testing.T) {
+	t.Run("When no errors are returned", func(t *testing.T) {
+		sut := SqliteKitService{
+			db: GreenSqliteMock{},
+			partservice: SqlitePartService{
+				db: GreenSqliteMock{},
+			},
+		}
+
+		parts := make([]core.Part, len(FakeParts))
+		for i := range FakeParts {
+			p := FakeParts[i]
+			p.Links = FakeLinks[:]
+
+			parts[i] = p
+		}
+
+		spec := core.KitSpec{Name: FakeKits[0].Name}
+
+		result, err := sut.Import(spec)
+
+		assert.Nil(t, err)
+		assert.Equal(t, FakeKits[0].ID, result.Kit.ID)
+		assert.Equal(t, parts[:1], result.Matched)
+		assert.Equal(t, parts[1:], result.Created)
+	})
+}
+
+func Test_sqlitekitservice_Update(t *testing.T) {
+	t.Run("When no errors are returned", func(t *testing.T) {
+		sut := SqliteKitService{
+			db: GreenSqliteMock{},
+			partservice: SqlitePartService{
+				db: GreenSqliteMock{},
+			},
+		}
+
+		kit, err := sut.Update(FakeKits[0].ID, "Renamed", "", "")
+
+		assert.Nil(t, err)
+		assert.Equal(t, FakeKits[0].ID, kit.ID)
+	})
+}
+
+func Test_sqlitekitservice_Patch(t *testing.T) {
+	t.Run("When no errors are returned", func(t *testing.T) {
+		sut := SqliteKitService{
+			db: GreenSqliteMock{},
+			partservice: SqlitePartService{
+				db: GreenSqliteMock{},
+			},
+		}
+
+		kit, err := sut.Patch(FakeKits[0].ID, core.KitPatch{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, FakeKits[0].ID, kit.ID)
+	})
+}
@@ -1,6 +1,8 @@
 package sqlite
 
 import (
+	"context"
+
 	"github.com/sombrerosheep/partsbundler/pkg/core"
 )
 
@@ -14,52 +16,103 @@ func (service SqlitePartService) GetAll() ([]core.Part, error) {
 		return nil, err
 	}
 
-	for i := range parts {
-		part := &parts[i]
-
-		links, err := service.db.GetPartLinks(part.ID)
-		if err != nil {
-			return nil, err
-		}
-
-		part.Links = links
+	links, err := service.db.GetAllPartLinks()
+	if err != nil {
+		return nil, err
 	}
 
+	setPartLinks(parts, links)
+
 	return parts, nil
 }
 
-func (service SqlitePartService) GetParts(ids []int64) ([]core.Part, error) {
-	parts := make([]core.Part, len(ids))
-
-	for i, id := range ids {
-		part, err := service.Get(id)
-		if err != nil {
-			return nil, err
+// setPartLinks assigns each part its links, using an empty list for
+// parts that have none.
+func setPartLinks(parts []core.Part, links map[int64][]core.Link) {
+	for i := range parts {
+		parts[i].Links = links[parts[i].ID]
+		if parts[i].Links == nil {
+			parts[i].Links = []core.Link{}
 		}
+	}
+}
 
-		parts[i] = part
+func (service SqlitePartService) GetParts(ids []int64) ([]core.Part, error) {
+	parts, err := service.db.GetParts(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := service.db.GetLinksForParts(ids)
+	if err != nil {
+		return nil, err
 	}
 
+	setPartLinks(parts, links)
+
 	return parts, nil
 }
 
 func (service SqlitePartService) Get(partId int64) (core.Part, error) {
-	part, err := service.db.GetPart(partId)
+	parts, err := service.GetParts([]int64{partId})
 	if err != nil {
 		return core.Part{}, err
 	}
 
-	links, err := service.db.GetPartLinks(part.ID)
+	return parts[0], nil
+}
+
+func (service SqlitePartService) Find(query core.PartQuery) ([]core.Part, error) {
+	page, err := service.List(query, core.PageRequest{})
 	if err != nil {
-		return core.Part{}, err
+		return nil, err
+	}
+
+	return page.Parts, nil
+}
+
+func (service SqlitePartService) List(query core.PartQuery, page core.PageRequest) (core.PartPage, error) {
+	err := query.Validate()
+	if err != nil {
+		return core.PartPage{}, err
 	}
 
-	part.Links = links
+	err = page.Validate(core.PartSortKeys)
+	if err != nil {
+		return core.PartPage{}, err
+	}
 
-	return part, nil
+	parts, total, err := service.db.FindParts(query, page)
+	if err != nil {
+		return core.PartPage{}, err
+	}
+
+	ids := make([]int64, len(parts))
+	for i, part := range parts {
+		ids[i] = part.ID
+	}
+
+	links, err := service.db.GetLinksForParts(ids)
+	if err != nil {
+		return core.PartPage{}, err
+	}
+
+	setPartLinks(parts, links)
+
+	return core.PartPage{
+		Parts:  parts,
+		Total:  total,
+		Offset: page.Offset,
+		Limit:  page.Limit,
+	}, nil
 }
 
 func (service SqlitePartService) AddLink(partId int64, link string) (core.Link, error) {
+	err := core.Link{URL: link}.Validate()
+	if err != nil {
+		return core.Link{}, err
+	}
+
 	linkId, err := service.db.AddLinkToPart(link, partId)
 	if err != nil {
 		return core.Link{}, err
@@ -86,10 +139,16 @@ func (service SqlitePartService) New(name string, kind core.PartType) (core.Part
 	part := core.Part{
 		Name:  name,
 		Kind:  kind,
+		Value: core.NormalizeValue(kind, name),
 		Links: []core.Link{},
 	}
 
-	partId, err := service.db.CreatePart(name, kind)
+	err := part.Validate()
+	if err != nil {
+		return part, err
+	}
+
+	partId, err := service.db.CreatePart(name, part.Value, kind)
 	if err != nil {
 		return part, err
 	}
@@ -99,8 +158,114 @@ func (service SqlitePartService) New(name string, kind core.PartType) (core.Part
 	return part, nil
 }
 
+func (service SqlitePartService) Update(partId int64, name string, kind core.PartType) (core.Part, error) {
+	err := core.Part{Name: name, Kind: kind}.Validate()
+	if err != nil {
+		return core.Part{}, err
+	}
+
+	err = service.db.UpdatePart(partId, name, core.NormalizeValue(kind, name), kind)
+	if err != nil {
+		return core.Part{}, err
+	}
+
+	return service.Get(partId)
+}
+
+func (service SqlitePartService) Patch(partId int64, patch core.PartPatch) (core.Part, error) {
+	var part core.Part
+
+	err := service.db.WithTx(func(tx isqlitedb) error {
+		txservice := SqlitePartService{db: tx}
+
+		current, err := txservice.Get(partId)
+		if err != nil {
+			return err
+		}
+
+		patched := patch.Apply(current)
+
+		part, err = txservice.Update(partId, patched.Name, patched.Kind)
+
+		return err
+	})
+	if err != nil {
+		return core.Part{}, err
+	}
+
+	return part, nil
+}
+
 func (service SqlitePartService) Delete(partId int64) error {
 	err := service.db.RemovePart(partId)
 
 	return err
 }
+
+func (service SqlitePartService) FindDuplicates() ([][]core.Part, error) {
+	parts, err := service.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	return core.FindDuplicates(parts), nil
+}
+
+func (service SqlitePartService) Merge(partId int64, duplicateId int64) error {
+	return service.db.MergeParts(partId, duplicateId)
+}
+
+// withContext returns a copy of the service whose queries run with ctx.
+func (service SqlitePartService) withContext(ctx context.Context) SqlitePartService {
+	return SqlitePartService{
+		db: service.db.WithContext(ctx),
+	}
+}
+
+func (service SqlitePartService) GetAllContext(ctx context.Context) ([]core.Part, error) {
+	return service.withContext(ctx).GetAll()
+}
+
+func (service SqlitePartService) GetContext(ctx context.Context, partId int64) (core.Part, error) {
+	return service.withContext(ctx).Get(partId)
+}
+
+func (service SqlitePartService) FindContext(ctx context.Context, query core.PartQuery) ([]core.Part, error) {
+	return service.withContext(ctx).Find(query)
+}
+
+func (service SqlitePartService) ListContext(ctx context.Context, query core.PartQuery, page core.PageRequest) (core.PartPage, error) {
+	return service.withContext(ctx).List(query, page)
+}
+
+func (service SqlitePartService) AddLinkContext(ctx context.Context, partId int64, link string) (core.Link, error) {
+	return service.withContext(ctx).AddLink(partId, link)
+}
+
+func (service SqlitePartService) RemoveLinkContext(ctx context.Context, partId int64, linkId int64) error {
+	return service.withContext(ctx).RemoveLink(partId, linkId)
+}
+
+func (service SqlitePartService) NewContext(ctx context.Context, name string, kind core.PartType) (core.Part, error) {
+	return service.withContext(ctx).New(name, kind)
+}
+
+func (service SqlitePartService) UpdateContext(ctx context.Context, partId int64, name string, kind core.PartType) (core.Part, error) {
+	return service.withContext(ctx).Update(partId, name, kind)
+}
+
+func (service SqlitePartService) PatchContext(ctx context.Context, partId int64, patch core.PartPatch) (core.Part, error) {
+	return service.withContext(ctx).Patch(partId, patch)
+}
+
+func (service SqlitePartService) DeleteContext(ctx context.Context, partId int64) error {
+	return service.withContext(ctx).Delete(partId)
+}
+
+func (service SqlitePartService) FindDuplicatesContext(ctx context.Context) ([][]core.Part, error) {
+	return service.withContext(ctx).FindDuplicates()
+}
+
+func (service SqlitePartService) MergeContext(ctx context.Context, partId int64, duplicateId int64) error {
+	return service.withContext(ctx).Merge(partId, duplicateId)
+}
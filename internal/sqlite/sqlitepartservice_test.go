@@ -85,6 +85,18 @@ func Test_sqlitepartservice_New(t *testing.T) {
 		assert.Nil(t, err)
 		assert.Equal(t, part, expectedPart)
 	})
+
+	t.Run("should normalize the part value", func(t *testing.T) {
+		sut := SqlitePartService{
+			db: GreenSqliteMock{},
+		}
+
+		part, err := sut.New("1500pf", core.Capacitor)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "1500pf", part.Name)
+		assert.Equal(t, "1.5nF", part.Value)
+	})
 }
 
 func Test_sqlitepartservice_Delete(t *testing.T) {
@@ -98,3 +110,81 @@ func Test_sqlitepartservice_Delete(t *testing.T) {
 		assert.Nil(t, err)
 	})
 }
+
+func Test_sqlitepartservice_FindDuplicates(t *testing.T) {
+	t.Run("When no errors are returned", func(t *testing.T) {
+		sut := SqlitePartService{
+			db: GreenSqliteMock{},
+		}
+
+		dupes, err := sut.FindDuplicates()
+
+		assert.Nil(t, err)
+		assert.Len(t, dupes, 0)
+	})
+}
+
+func Test_sqlitepartservice_Merge(t *testing.T) {
+	t.Run("Merge", func(t *testing.T) {
+		sut := SqlitePartService{
+			db: GreenSqliteMock{},
+		}
+
+		err := sut.Merge(1, 2)
+
+		assert.Nil(t, err)
+	})
+}
+
+func Test_sqlitepartservice_Update(t *testing.T) {
+	t.Run("When no errors are returned", func(t *testing.T) {
+		sut := SqlitePartService{
+			db: GreenSqliteMock{},
+		}
+
+		part, err := sut.Update(FakeParts[0].ID, "4k7", core.Resistor)
+
+		assert.Nil(t, err)
+		assert.Equal(t, FakeParts[0].ID, part.ID)
+	})
+}
+
+func Test_sqlitepartservice_Patch(t *testing.T) {
+	t.Run("When no errors are returned", func(t *testing.T) {
+		sut := SqlitePartService{
+			db: GreenSqliteMock{},
+		}
+
+		part, err := sut.Patch(FakeParts[0].ID, core.PartPatch{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, FakeParts[0].ID, part.ID)
+	})
+}
+
+func Test_sqlitepartservice_Find(t *testing.T) {
+	t.Run("When no errors are returned", func(t *testing.T) {
+		sut := SqlitePartService{
+			db: GreenSqliteMock{},
+		}
+
+		inUse := true
+		expected := FakeParts[0]
+		expected.Links = FakeLinks[:]
+
+		parts, err := sut.Find(core.PartQuery{Kind: core.Resistor, Max: "1k", InUse: &inUse})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []core.Part{expected}, parts)
+	})
+
+	t.Run("should return an error for an invalid query", func(t *testing.T) {
+		sut := SqlitePartService{
+			db: GreenSqliteMock{},
+		}
+
+		_, err := sut.Find(core.PartQuery{Min: "10k"})
+
+		assert.IsType(t, core.InvalidPartQuery{}, err)
+	})
+}
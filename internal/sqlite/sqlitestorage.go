@@ -0,0 +1,19 @@
+package sqlite
+
+import (
+	"context"
+)
+
+type SqliteStorage struct {
+	db isqlitedb
+}
+
+// Ping checks that the database file can be opened and queried.
+func (s SqliteStorage) Ping(ctx context.Context) error {
+	return s.db.WithContext(ctx).Ping()
+}
+
+// Close closes the database connection.
+func (s SqliteStorage) Close() error {
+	return s.db.Close()
+}
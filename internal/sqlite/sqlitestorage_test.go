@@ -0,0 +1,47 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_sqlitestorage(t *testing.T) {
+	const dbPath = "./import/dbstoragetest.db"
+
+	testdb, err := getTestDbConnection(t, dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(dbPath)
+
+	sut := SqliteStorage{
+		db: testdb,
+	}
+
+	t.Run("should ping an open database", func(t *testing.T) {
+		err := sut.Ping(context.Background())
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("should fail to ping with a cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := sut.Ping(ctx)
+
+		assert.Equal(t, context.Canceled, err)
+	})
+
+	t.Run("should fail to ping once closed", func(t *testing.T) {
+		err := sut.Close()
+		assert.Nil(t, err)
+
+		err = sut.Ping(context.Background())
+
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,17 @@
+package sqlite
+
+import (
+	"github.com/sombrerosheep/partsbundler/pkg/service"
+)
+
+type SqliteTransactor struct {
+	db isqlitedb
+}
+
+// Atomic runs fn in a single sqlite transaction. Calls to Atomic made
+// from within fn join the outer transaction.
+func (t SqliteTransactor) Atomic(fn func(svc *service.BundlerService) error) error {
+	return t.db.WithTx(func(tx isqlitedb) error {
+		return fn(newSqliteService(tx))
+	})
+}
@@ -0,0 +1,42 @@
+package sqlite
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sombrerosheep/partsbundler/pkg/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_sqlitetransactor_Atomic(t *testing.T) {
+	t.Run("When no errors are returned", func(t *testing.T) {
+		sut := SqliteTransactor{
+			db: GreenSqliteMock{},
+		}
+
+		called := false
+		err := sut.Atomic(func(svc *service.BundlerService) error {
+			called = true
+
+			_, err := svc.Parts.Get(FakeParts[0].ID)
+
+			return err
+		})
+
+		assert.Nil(t, err)
+		assert.True(t, called)
+	})
+
+	t.Run("should return the error returned by fn", func(t *testing.T) {
+		sut := SqliteTransactor{
+			db: GreenSqliteMock{},
+		}
+
+		expected := errors.New("failed")
+		err := sut.Atomic(func(svc *service.BundlerService) error {
+			return expected
+		})
+
+		assert.Equal(t, expected, err)
+	})
+}
@@ -0,0 +1,291 @@
+// Package bom reads kits from the CSV bills of materials published by
+// pedal PCB vendors.
+package bom
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+	"github.com/sombrerosheep/partsbundler/pkg/service"
+)
+
+type InvalidLine struct {
+	Line   int
+	Reason string
+}
+
+func (l InvalidLine) Error() string {
+	return fmt.Sprintf("Line %d: %s", l.Line, l.Reason)
+}
+
+// InvalidBOM holds every line of a BOM which could not be read.
+type InvalidBOM struct {
+	Lines []InvalidLine
+}
+
+func (b InvalidBOM) Error() string {
+	reasons := make([]string, len(b.Lines))
+	for i, l := range b.Lines {
+		reasons[i] = l.Error()
+	}
+
+	return fmt.Sprintf("Invalid BOM: %s", strings.Join(reasons, "; "))
+}
+
+// Mapping names the BOM columns holding each field and maps the kinds
+// used by the BOM onto part types. Column names and kinds are matched
+// without regard to case. Ref and Quantity columns are optional.
+type Mapping struct {
+	Ref      string                   `json:"ref"`
+	Value    string                   `json:"value"`
+	Kind     string                   `json:"kind"`
+	Quantity string                   `json:"quantity"`
+	Kinds    map[string]core.PartType `json:"kinds"`
+}
+
+// DefaultMapping reads the Ref, Value, Type and Qty columns and knows
+// the common abbreviations for each part type.
+func DefaultMapping() Mapping {
+	return Mapping{
+		Ref:      "Ref",
+		Value:    "Value",
+		Kind:     "Type",
+		Quantity: "Qty",
+		Kinds: map[string]core.PartType{
+			"res":           core.Resistor,
+			"resistor":      core.Resistor,
+			"cap":           core.Capacitor,
+			"capacitor":     core.Capacitor,
+			"electrolytic":  core.Capacitor,
+			"film":          core.Capacitor,
+			"ceramic":       core.Capacitor,
+			"ic":            core.IC,
+			"opamp":         core.IC,
+			"transistor":    core.Transistor,
+			"jfet":          core.Transistor,
+			"diode":         core.Diode,
+			"led":           core.Diode,
+			"pot":           core.Potentiometer,
+			"potentiometer": core.Potentiometer,
+			"trimmer":       core.Potentiometer,
+			"switch":        core.Switch,
+			"footswitch":    core.Switch,
+		},
+	}
+}
+
+// AddKinds adds the kind aliases written in s as alias:kind pairs
+// separated by commas, such as "Tube:IC,Knob:Potentiometer". An alias
+// replaces any existing alias of the same name.
+func (m *Mapping) AddKinds(s string) error {
+	kinds := map[string]core.PartType{}
+
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		kv := strings.SplitN(pair, ":", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return core.InvalidField("kinds", fmt.Sprintf("'%s' is not an alias:kind pair", pair))
+		}
+
+		kind := core.PartType(strings.TrimSpace(kv[1]))
+		if err := kind.IsValid(); err != nil {
+			return err
+		}
+
+		kinds[strings.TrimSpace(kv[0])] = kind
+	}
+
+	if m.Kinds == nil {
+		m.Kinds = map[string]core.PartType{}
+	}
+
+	for alias, kind := range kinds {
+		for existing := range m.Kinds {
+			if strings.EqualFold(existing, alias) {
+				delete(m.Kinds, existing)
+			}
+		}
+
+		m.Kinds[alias] = kind
+	}
+
+	return nil
+}
+
+func (m Mapping) kind(s string) (core.PartType, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	for alias, kind := range m.Kinds {
+		if strings.ToLower(alias) == s {
+			return kind, true
+		}
+	}
+
+	return "", false
+}
+
+type columns struct {
+	ref, value, kind, quantity int
+}
+
+func (m Mapping) columns(header []string) (columns, []InvalidLine) {
+	find := func(name string) int {
+		if name == "" {
+			return -1
+		}
+
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), name) {
+				return i
+			}
+		}
+
+		return -1
+	}
+
+	cols := columns{
+		ref:      find(m.Ref),
+		value:    find(m.Value),
+		kind:     find(m.Kind),
+		quantity: find(m.Quantity),
+	}
+
+	errs := []InvalidLine{}
+	if cols.value < 0 {
+		errs = append(errs, InvalidLine{1, fmt.Sprintf("missing value column '%s'", m.Value)})
+	}
+	if cols.kind < 0 {
+		errs = append(errs, InvalidLine{1, fmt.Sprintf("missing kind column '%s'", m.Kind)})
+	}
+
+	return cols, errs
+}
+
+func field(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+
+	return strings.TrimSpace(record[i])
+}
+
+// Read parses a CSV BOM with a header row into a kit named name. The
+// references on each line become the part's designators. Lines for the
+// same kind and value are combined. When a line has no quantity it is
+// the number of references on the line, or 1. Blank lines are skipped
+// and every invalid line is reported in InvalidBOM.
+func Read(r io.Reader, name string, m Mapping) (core.KitSpec, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return core.KitSpec{}, InvalidBOM{[]InvalidLine{{1, "missing header"}}}
+	}
+	if err != nil {
+		return core.KitSpec{}, err
+	}
+
+	cols, errs := m.columns(header)
+	if len(errs) > 0 {
+		return core.KitSpec{}, InvalidBOM{errs}
+	}
+
+	spec := core.KitSpec{
+		Name:  name,
+		Parts: []core.KitPartSpec{},
+	}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return core.KitSpec{}, err
+		}
+
+		value := field(record, cols.value)
+		kindName := field(record, cols.kind)
+		if value == "" && kindName == "" {
+			continue
+		}
+
+		kind, ok := m.kind(kindName)
+		if !ok {
+			kind = core.PartType(kindName)
+			if kind.IsValid() != nil {
+				errs = append(errs, InvalidLine{line, fmt.Sprintf("unknown kind '%s'", kindName)})
+				continue
+			}
+		}
+
+		if value == "" {
+			errs = append(errs, InvalidLine{line, "missing value"})
+			continue
+		}
+
+		refs := core.ParseDesignators(field(record, cols.ref))
+
+		var quantity uint64
+		if q := field(record, cols.quantity); q != "" {
+			quantity, err = strconv.ParseUint(q, 10, 64)
+			if err != nil || quantity == 0 {
+				errs = append(errs, InvalidLine{line, fmt.Sprintf("invalid quantity '%s'", q)})
+				continue
+			}
+
+			if len(refs) > 0 && uint64(len(refs)) != quantity {
+				errs = append(errs, InvalidLine{line, fmt.Sprintf("quantity %d does not match %d references", quantity, len(refs))})
+				continue
+			}
+		}
+
+		spec.Parts = append(spec.Parts, core.KitPartSpec{
+			Kind:        kind,
+			Name:        value,
+			Quantity:    quantity,
+			Designators: refs,
+		})
+	}
+
+	if len(errs) > 0 {
+		return core.KitSpec{}, InvalidBOM{errs}
+	}
+
+	spec.Parts = spec.Merged()
+
+	for i := range spec.Parts {
+		spec.Parts[i].Links = nil
+	}
+
+	if err := spec.Validate(); err != nil {
+		return core.KitSpec{}, err
+	}
+
+	return spec, nil
+}
+
+// Import reads a CSV BOM and creates the kit it describes. Nothing is
+// created when any line of the BOM is invalid.
+func Import(kits service.IKitService, r io.Reader, name string, m Mapping) (core.KitImport, error) {
+	return ImportContext(context.Background(), kits, r, name, m)
+}
+
+// ImportContext is like Import but creates the kit with ctx.
+func ImportContext(ctx context.Context, kits service.IKitService, r io.Reader, name string, m Mapping) (core.KitImport, error) {
+	spec, err := Read(r, name, m)
+	if err != nil {
+		return core.KitImport{}, err
+	}
+
+	return kits.ImportContext(ctx, spec)
+}
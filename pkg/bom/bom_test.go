@@ -0,0 +1,149 @@
+package bom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+	"github.com/sombrerosheep/partsbundler/pkg/service/mock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testBOM = `Ref,Value,Type,Qty
+R1,2.2M,Res,1
+"R2, R3",10k,Res,
+R4,10K,Resistor,1
+C1,1500pf,Cap,1
+"C2, C3",1.5nF,Cap,2
+
+IC1,TL072,IC,1
+VR1,B100k,Pot,
+TR1,a50k,Trimmer,1
+`
+
+func Test_Read(t *testing.T) {
+	t.Run("should read and combine lines", func(t *testing.T) {
+		spec, err := Read(strings.NewReader(testBOM), "TS-808", DefaultMapping())
+
+		expected := core.KitSpec{
+			Name: "TS-808",
+			Parts: []core.KitPartSpec{
+				{Kind: core.Resistor, Name: "2.2M", Quantity: 1, Designators: []string{"R1"}},
+				{Kind: core.Resistor, Name: "10k", Quantity: 3, Designators: []string{"R2", "R3", "R4"}},
+				{Kind: core.Capacitor, Name: "1500pf", Quantity: 3, Designators: []string{"C1", "C2", "C3"}},
+				{Kind: core.IC, Name: "TL072", Quantity: 1, Designators: []string{"IC1"}},
+				{Kind: core.Potentiometer, Name: "B100k", Quantity: 1, Designators: []string{"VR1"}},
+				{Kind: core.Potentiometer, Name: "a50k", Quantity: 1, Designators: []string{"TR1"}},
+			},
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, spec)
+	})
+
+	t.Run("should use configured columns and kinds", func(t *testing.T) {
+		input := "Designator,Part,Category,Count\nQ1 Q2,2N5088,BJT,2\n"
+
+		m := Mapping{
+			Ref:      "designator",
+			Value:    "part",
+			Kind:     "category",
+			Quantity: "count",
+			Kinds:    map[string]core.PartType{"BJT": core.Transistor},
+		}
+
+		spec, err := Read(strings.NewReader(input), "kit", m)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []core.KitPartSpec{{Kind: core.Transistor, Name: "2N5088", Quantity: 2, Designators: []string{"Q1", "Q2"}}}, spec.Parts)
+	})
+
+	t.Run("should report each invalid line", func(t *testing.T) {
+		input := "Ref,Value,Type,Qty\nR1,10k,Res,1\nX1,1.21GW,Flux Capacitor,1\nR2,,Res,1\nR3,1k,Res,some\nR4 R5,1k,Res,3\n"
+
+		_, err := Read(strings.NewReader(input), "kit", DefaultMapping())
+
+		expected := InvalidBOM{
+			Lines: []InvalidLine{
+				{Line: 3, Reason: "unknown kind 'Flux Capacitor'"},
+				{Line: 4, Reason: "missing value"},
+				{Line: 5, Reason: "invalid quantity 'some'"},
+				{Line: 6, Reason: "quantity 3 does not match 2 references"},
+			},
+		}
+
+		assert.Equal(t, expected, err)
+	})
+
+	t.Run("should report missing columns", func(t *testing.T) {
+		input := "Ref,Qty\nR1,1\n"
+
+		_, err := Read(strings.NewReader(input), "kit", DefaultMapping())
+
+		assert.IsType(t, InvalidBOM{}, err)
+		assert.Len(t, err.(InvalidBOM).Lines, 2)
+	})
+
+	t.Run("should return InvalidKitSpec when name is missing", func(t *testing.T) {
+		_, err := Read(strings.NewReader(testBOM), "", DefaultMapping())
+
+		assert.IsType(t, core.InvalidKitSpec{}, err)
+	})
+}
+
+func Test_Mapping_AddKinds(t *testing.T) {
+	t.Run("should add and replace kind aliases", func(t *testing.T) {
+		m := DefaultMapping()
+
+		err := m.AddKinds("Tube:IC, RES:Potentiometer,")
+
+		assert.Nil(t, err)
+		assert.Equal(t, core.PartType(core.IC), m.Kinds["Tube"])
+		assert.Equal(t, core.PartType(core.Potentiometer), m.Kinds["RES"])
+		assert.NotContains(t, m.Kinds, "res")
+
+		spec, err := Read(strings.NewReader("Ref,Value,Type\nV1,12AX7,tube\n"), "Tube", m)
+
+		assert.Nil(t, err)
+		assert.Equal(t, core.PartType(core.IC), spec.Parts[0].Kind)
+	})
+
+	t.Run("should reject invalid pairs and kinds", func(t *testing.T) {
+		m := DefaultMapping()
+
+		err := m.AddKinds("Tube")
+		assert.Equal(t, core.InvalidField("kinds", "'Tube' is not an alias:kind pair"), err)
+
+		err = m.AddKinds("Tube:Valve")
+		assert.Equal(t, core.InvalidPartType{InvalidType: "Valve"}, err)
+
+		assert.NotContains(t, m.Kinds, "Tube")
+	})
+}
+
+func Test_Import(t *testing.T) {
+	t.Run("should create kit", func(t *testing.T) {
+		input := "Ref,Value,Type,Qty\nR1,1000r,Res,1\nR2,1k,Res,1\n"
+
+		result, err := Import(mock.StubBundlerService.Kits, strings.NewReader(input), "bom", DefaultMapping())
+
+		assert.Nil(t, err)
+		assert.Equal(t, "bom", result.Kit.Name)
+		assert.Equal(t, []core.Part{mock.FakeParts[0]}, result.Matched)
+		assert.Equal(t, uint64(2), result.Kit.Parts[0].Quantity)
+	})
+
+	t.Run("should not create kit when a line is invalid", func(t *testing.T) {
+		kits, _ := mock.StubBundlerService.Kits.GetAll()
+		input := "Ref,Value,Type,Qty\nR1,1k,Res,1\nX1,1.21GW,Flux Capacitor,1\n"
+
+		_, err := Import(mock.StubBundlerService.Kits, strings.NewReader(input), "bom", DefaultMapping())
+
+		assert.IsType(t, InvalidBOM{}, err)
+
+		after, _ := mock.StubBundlerService.Kits.GetAll()
+
+		assert.Len(t, after, len(kits))
+	})
+}
@@ -0,0 +1,270 @@
+// Package client implements the bundler services over the REST API of a
+// running bundler-server, so code written against service.BundlerService
+// can use a remote server in place of a local database.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+	"github.com/sombrerosheep/partsbundler/pkg/service"
+)
+
+// maxPageLimit is the largest page bundler-server returns.
+const maxPageLimit = 1000
+
+type InvalidServerURL struct {
+	URL string
+}
+
+func (u InvalidServerURL) Error() string {
+	return fmt.Sprintf("Invalid server URL '%s'", u.URL)
+}
+
+// UnexpectedResponse is returned when the server answers with a status
+// or body the client does not understand.
+type UnexpectedResponse struct {
+	Status int
+	Body   string
+}
+
+func (r UnexpectedResponse) Error() string {
+	return fmt.Sprintf("Unexpected response %d: %s", r.Status, r.Body)
+}
+
+// CreateClientService returns a BundlerService backed by the
+// bundler-server at baseURL. A nil httpClient uses a client of the
+// service's own, whose connections are closed by Close. Atomic returns
+// service.AtomicUnsupported, as the API has no transactions.
+func CreateClientService(baseURL string, httpClient *http.Client) (*service.BundlerService, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, InvalidServerURL{baseURL}
+	}
+
+	owned := httpClient == nil
+	if owned {
+		httpClient = &http.Client{Transport: http.DefaultTransport.(*http.Transport).Clone()}
+	}
+
+	c := restClient{
+		baseURL: strings.TrimSuffix(u.String(), "/"),
+		http:    httpClient,
+	}
+
+	svc := &service.BundlerService{
+		Parts:      ClientPartService{c: c},
+		Kits:       ClientKitService{c: c},
+		Inventory:  ClientInventoryService{c: c},
+		Transactor: ClientTransactor{},
+		Storage:    ClientStorage{c: c, owned: owned},
+	}
+
+	return svc, nil
+}
+
+// restClient sends requests to bundler-server. Requests run with ctx,
+// so they are cancelled when ctx is done.
+type restClient struct {
+	baseURL string
+	http    *http.Client
+	ctx     context.Context
+}
+
+func (c restClient) withContext(ctx context.Context) restClient {
+	c.ctx = ctx
+
+	return c
+}
+
+func (c restClient) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+
+	return c.ctx
+}
+
+// do sends in as the JSON body of a request to path and decodes a
+// successful response into out. Either may be nil. Error responses are
+// returned as the core error they describe.
+func (c restClient) do(method string, path string, query url.Values, in interface{}, out interface{}) (http.Header, error) {
+	var body io.Reader
+	if in != nil {
+		buf, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+
+		body = bytes.NewReader(buf)
+	}
+
+	uri := c.baseURL + path
+	if len(query) > 0 {
+		uri += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(c.context(), method, uri, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode >= 400 {
+		return nil, decodeError(res.StatusCode, data)
+	}
+
+	if out != nil && res.StatusCode != http.StatusNoContent {
+		if err := json.Unmarshal(data, out); err != nil {
+			return nil, UnexpectedResponse{res.StatusCode, string(data)}
+		}
+	}
+
+	return res.Header, nil
+}
+
+// totalCount reads the X-Total-Count header of a list response.
+func totalCount(h http.Header) (int, error) {
+	total, err := strconv.Atoi(h.Get("X-Total-Count"))
+	if err != nil {
+		return 0, UnexpectedResponse{http.StatusOK, "missing X-Total-Count header"}
+	}
+
+	return total, nil
+}
+
+// listPages fetches the page of a list endpoint selected by page, asking
+// for at most maxPageLimit items at a time. fetch returns the number of
+// items it received and the total across every page.
+func listPages(page core.PageRequest, fetch func(query url.Values) (int, int, error)) (int, error) {
+	offset := page.Offset
+	total := 0
+
+	for {
+		limit := maxPageLimit
+		if page.Limit > 0 && page.Offset+page.Limit-offset < limit {
+			limit = page.Offset + page.Limit - offset
+		}
+
+		query := url.Values{}
+		query.Set("offset", strconv.Itoa(offset))
+		query.Set("limit", strconv.Itoa(limit))
+		if page.Sort != "" {
+			query.Set("sort", page.Sort)
+		}
+		if page.Desc {
+			query.Set("order", "desc")
+		}
+
+		n, t, err := fetch(query)
+		if err != nil {
+			return 0, err
+		}
+
+		total = t
+		offset += n
+
+		if n < limit || offset >= total || (page.Limit > 0 && offset >= page.Offset+page.Limit) {
+			return total, nil
+		}
+	}
+}
+
+func decodeError(status int, data []byte) error {
+	var body core.ErrorResponse
+	if err := json.Unmarshal(data, &body); err != nil || body.Error.Code == "" {
+		return UnexpectedResponse{status, string(data)}
+	}
+
+	e := body.Error
+	d := details(e.Details)
+
+	switch e.Code {
+	case "part_not_found":
+		return core.PartNotFound{PartID: d.int64("partId")}
+	case "kit_not_found":
+		return core.KitNotFound{KitID: d.int64("kitId")}
+	case "link_not_found":
+		return core.LinkNotFound{LinkID: d.int64("linkId"), OwnerID: d.int64("ownerId")}
+	case "kit_link_not_found":
+		return core.KitLinkNotFound{LinkID: d.int64("linkId"), KitID: d.int64("kitId")}
+	case "part_not_in_kit":
+		return core.PartNotInKit{KitID: d.int64("kitId"), PartID: d.int64("partId")}
+	case "part_in_use":
+		return core.PartInUse{PartID: d.int64("partId")}
+	case "part_already_in_kit":
+		return core.PartAlreadyInKit{KitID: d.int64("kitId"), PartID: d.int64("partId")}
+	case "designator_in_use":
+		return core.DesignatorInUse{KitID: d.int64("kitId"), Designator: d.string("designator")}
+	case "designator_mismatch":
+		return core.DesignatorMismatch{
+			KitID:       d.int64("kitId"),
+			PartID:      d.int64("partId"),
+			Designators: int(d.int64("designators")),
+			Quantity:    uint64(d.int64("quantity")),
+		}
+	case "insufficient_stock":
+		return core.InsufficientStock{
+			PartID:    d.int64("partId"),
+			Available: uint64(d.int64("available")),
+			Requested: uint64(d.int64("requested")),
+		}
+	case "cannot_merge_parts":
+		return core.CannotMergeParts{PartID: d.int64("partId"), DuplicateID: d.int64("duplicateId")}
+	case "invalid_part_type":
+		return core.InvalidPartType{InvalidType: d.string("kind")}
+	case "invalid_adjustment_kind":
+		return core.InvalidAdjustmentKind{InvalidKind: d.string("kind")}
+	case "invalid_value":
+		return core.InvalidValue{Kind: core.PartType(d.string("kind")), Value: d.string("value")}
+	case "invalid_part_query":
+		return core.InvalidPartQuery{Reason: d.string("reason")}
+	case "invalid_page_request":
+		return core.InvalidPageRequest{Reason: d.string("reason")}
+	case "invalid_kit_spec":
+		return core.InvalidKitSpec{Reason: d.string("reason")}
+	case "invalid_request":
+		return core.ValidationError{Fields: e.Fields}
+	}
+
+	return e
+}
+
+// details reads the values of APIError.Details, which arrive as generic
+// JSON values.
+type details map[string]interface{}
+
+func (d details) int64(key string) int64 {
+	n, _ := d[key].(float64)
+
+	return int64(n)
+}
+
+func (d details) string(key string) string {
+	s, _ := d[key].(string)
+
+	return s
+}
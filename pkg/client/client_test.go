@@ -0,0 +1,230 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+	"github.com/sombrerosheep/partsbundler/pkg/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeServer answers every request with handler and returns a service
+// pointed at it.
+func fakeServer(t *testing.T, handler http.HandlerFunc) *service.BundlerService {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	svc, err := CreateClientService(srv.URL, srv.Client())
+	assert.Nil(t, err)
+
+	return svc
+}
+
+func respond(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write([]byte(body))
+}
+
+func Test_CreateClientService(t *testing.T) {
+	t.Run("should reject urls that are not http", func(t *testing.T) {
+		for _, u := range []string{"", "localhost:3000", "ftp://example.com", "http://", "://bad"} {
+			svc, err := CreateClientService(u, nil)
+
+			assert.Nil(t, svc, u)
+			assert.Equal(t, InvalidServerURL{u}, err, u)
+		}
+	})
+
+	t.Run("should accept a trailing slash", func(t *testing.T) {
+		var path string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			respond(w, http.StatusOK, `{"id": 1, "name": "1k", "kind": "Resistor"}`)
+		}))
+		defer srv.Close()
+
+		svc, err := CreateClientService(srv.URL+"/", srv.Client())
+		assert.Nil(t, err)
+
+		_, err = svc.Parts.Get(1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "/parts/1", path)
+	})
+}
+
+func Test_Errors(t *testing.T) {
+	t.Run("should return PartNotFound for a missing part", func(t *testing.T) {
+		svc := fakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			respond(w, http.StatusNotFound,
+				`{"error": {"code": "part_not_found", "message": "Part not found", "details": {"partId": 7}}}`)
+		})
+
+		_, err := svc.Parts.Get(7)
+
+		assert.Equal(t, core.PartNotFound{PartID: 7}, err)
+	})
+
+	t.Run("should return KitNotFound for a missing kit", func(t *testing.T) {
+		svc := fakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			respond(w, http.StatusNotFound,
+				`{"error": {"code": "kit_not_found", "message": "Kit not found", "details": {"kitId": 3}}}`)
+		})
+
+		_, err := svc.Kits.Get(3)
+
+		assert.Equal(t, core.KitNotFound{KitID: 3}, err)
+	})
+
+	t.Run("should return a ValidationError for invalid requests", func(t *testing.T) {
+		svc := fakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			respond(w, http.StatusBadRequest,
+				`{"error": {"code": "invalid_request", "message": "Invalid request", "fields": [{"field": "name", "message": "must not be empty"}]}}`)
+		})
+
+		_, err := svc.Parts.New("", core.Resistor)
+
+		assert.Equal(t, core.ValidationError{Fields: []core.FieldError{{Field: "name", Message: "must not be empty"}}}, err)
+	})
+
+	t.Run("should return the APIError of unknown codes", func(t *testing.T) {
+		svc := fakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			respond(w, http.StatusInternalServerError,
+				`{"error": {"code": "internal_error", "message": "Internal error"}}`)
+		})
+
+		_, err := svc.Kits.Get(1)
+
+		assert.Equal(t, core.APIError{Code: "internal_error", Message: "Internal error"}, err)
+	})
+
+	t.Run("should return UnexpectedResponse for bodies that are not errors", func(t *testing.T) {
+		svc := fakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("bad gateway"))
+		})
+
+		err := svc.Parts.Delete(1)
+
+		assert.Equal(t, UnexpectedResponse{http.StatusBadGateway, "bad gateway"}, err)
+	})
+}
+
+func Test_PartList(t *testing.T) {
+	t.Run("should send the query and page", func(t *testing.T) {
+		var query string
+		svc := fakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.RawQuery
+			w.Header().Set("X-Total-Count", "12")
+			respond(w, http.StatusOK, `[{"id": 4, "name": "1k", "kind": "Resistor"}]`)
+		})
+
+		inUse := true
+		page, err := svc.Parts.List(
+			core.PartQuery{Kind: core.Resistor, Text: "1k", InUse: &inUse},
+			core.PageRequest{Offset: 3, Limit: 1, Sort: "name", Desc: true},
+		)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "kind=Resistor&limit=1&offset=3&order=desc&q=1k&sort=name&used=true", query)
+		assert.Equal(t, core.PartPage{
+			Parts:  []core.Part{{ID: 4, Name: "1k", Kind: core.Resistor}},
+			Offset: 3,
+			Limit:  1,
+			Total:  12,
+		}, page)
+	})
+
+	t.Run("should fetch every page", func(t *testing.T) {
+		total := maxPageLimit + 5
+		requests := 0
+		svc := fakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+			body := "["
+			for i := offset; i < offset+limit && i < total; i++ {
+				if i > offset {
+					body += ","
+				}
+				body += `{"id": ` + strconv.Itoa(i+1) + `, "name": "part", "kind": "IC"}`
+			}
+
+			w.Header().Set("X-Total-Count", strconv.Itoa(total))
+			respond(w, http.StatusOK, body+"]")
+		})
+
+		parts, err := svc.Parts.GetAll()
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, requests)
+		assert.Len(t, parts, total)
+		assert.Equal(t, int64(total), parts[total-1].ID)
+	})
+
+	t.Run("should fail without a total", func(t *testing.T) {
+		svc := fakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			respond(w, http.StatusOK, `[]`)
+		})
+
+		_, err := svc.Kits.GetAll()
+
+		assert.IsType(t, UnexpectedResponse{}, err)
+	})
+}
+
+// idleCounter counts the calls made to close its idle connections.
+type idleCounter struct {
+	http.RoundTripper
+	closed int
+}
+
+func (c *idleCounter) CloseIdleConnections() {
+	c.closed++
+}
+
+func Test_Close(t *testing.T) {
+	t.Run("should leave a client it was given open", func(t *testing.T) {
+		transport := &idleCounter{RoundTripper: http.DefaultTransport}
+
+		svc, err := CreateClientService("http://localhost:3000", &http.Client{Transport: transport})
+		assert.Nil(t, err)
+
+		err = svc.Close()
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, transport.closed)
+	})
+
+	t.Run("should close a client of its own", func(t *testing.T) {
+		svc, err := CreateClientService("http://localhost:3000", nil)
+		assert.Nil(t, err)
+
+		storage := svc.Storage.(ClientStorage)
+
+		assert.True(t, storage.owned)
+		assert.NotSame(t, http.DefaultClient, storage.c.http)
+		assert.Nil(t, svc.Close())
+	})
+}
+
+func Test_Atomic(t *testing.T) {
+	t.Run("should not run fn", func(t *testing.T) {
+		svc, err := CreateClientService("http://localhost:3000", nil)
+		assert.Nil(t, err)
+
+		called := false
+		err = svc.Atomic(func(tx *service.BundlerService) error {
+			called = true
+			return nil
+		})
+
+		assert.IsType(t, service.AtomicUnsupported{}, err)
+		assert.False(t, called)
+	})
+}
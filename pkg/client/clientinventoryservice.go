@@ -0,0 +1,49 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+)
+
+type ClientInventoryService struct {
+	c restClient
+}
+
+func (service ClientInventoryService) GetAll() ([]core.Stock, error) {
+	var stock []core.Stock
+	_, err := service.c.do(http.MethodGet, "/inventory", nil, nil, &stock)
+
+	return stock, err
+}
+
+func (service ClientInventoryService) Get(partId int64) (core.Stock, error) {
+	var stock core.Stock
+	_, err := service.c.do(http.MethodGet, fmt.Sprintf("/inventory/%d", partId), nil, nil, &stock)
+
+	return stock, err
+}
+
+func (service ClientInventoryService) Adjust(partId int64, kind core.AdjustmentKind, quantity uint64, note string) (core.Stock, error) {
+	input := core.StockAdjustment{Kind: kind, Quantity: quantity, Note: note}
+
+	var stock core.Stock
+	_, err := service.c.do(http.MethodPost, fmt.Sprintf("/inventory/%d/adjustments", partId), nil, input, &stock)
+
+	return stock, err
+}
+
+func (service ClientInventoryService) SetLocation(partId int64, location string) (core.Stock, error) {
+	var stock core.Stock
+	_, err := service.c.do(http.MethodPut, fmt.Sprintf("/inventory/%d/location", partId), nil, core.Stock{Location: location}, &stock)
+
+	return stock, err
+}
+
+func (service ClientInventoryService) GetHistory(partId int64) ([]core.StockAdjustment, error) {
+	var history []core.StockAdjustment
+	_, err := service.c.do(http.MethodGet, fmt.Sprintf("/inventory/%d/adjustments", partId), nil, nil, &history)
+
+	return history, err
+}
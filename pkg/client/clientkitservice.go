@@ -0,0 +1,282 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+)
+
+type ClientKitService struct {
+	c restClient
+}
+
+func (service ClientKitService) GetAll() ([]core.Kit, error) {
+	page, err := service.List(core.PageRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return page.Kits, nil
+}
+
+func (service ClientKitService) Get(kitId int64) (core.Kit, error) {
+	var kit core.Kit
+	_, err := service.c.do(http.MethodGet, fmt.Sprintf("/kits/%d", kitId), nil, nil, &kit)
+	if err != nil {
+		return kit, err
+	}
+
+	return withLinks(kit), nil
+}
+
+// List fetches the page in requests of at most maxPageLimit kits.
+func (service ClientKitService) List(page core.PageRequest) (core.KitPage, error) {
+	result := core.KitPage{
+		Kits:   []core.Kit{},
+		Offset: page.Offset,
+		Limit:  page.Limit,
+	}
+
+	total, err := listPages(page, func(values url.Values) (int, int, error) {
+		var kits []core.Kit
+		h, err := service.c.do(http.MethodGet, "/kits", values, nil, &kits)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		for _, kit := range kits {
+			result.Kits = append(result.Kits, withLinks(kit))
+		}
+
+		total, err := totalCount(h)
+
+		return len(kits), total, err
+	})
+	if err != nil {
+		return core.KitPage{}, err
+	}
+
+	result.Total = total
+
+	return result, nil
+}
+
+// AddLink validates the link before sending it, as the server looks up
+// the kit first and the other services validate first.
+func (service ClientKitService) AddLink(kitId int64, link string) (core.Link, error) {
+	err := core.Link{URL: link}.Validate()
+	if err != nil {
+		return core.Link{}, err
+	}
+
+	var newLink core.Link
+	_, err = service.c.do(http.MethodPost, fmt.Sprintf("/kits/%d/links", kitId), nil, core.Link{URL: link}, &newLink)
+
+	return newLink, err
+}
+
+func (service ClientKitService) RemoveLink(kitId int64, linkId int64) error {
+	_, err := service.c.do(http.MethodDelete, fmt.Sprintf("/kits/%d/links/%d", kitId, linkId), nil, nil, nil)
+
+	return err
+}
+
+func (service ClientKitService) AddPart(kitId int64, partId int64, quantity uint64) error {
+	query := url.Values{"quantity": {strconv.FormatUint(quantity, 10)}}
+	_, err := service.c.do(http.MethodPost, fmt.Sprintf("/kits/%d/parts/%d", kitId, partId), query, nil, nil)
+
+	return err
+}
+
+// GetPartUsage lists every kit to find the ones using the part, as the
+// API has no route for it. The part is looked up first so a missing
+// part is reported as PartNotFound.
+func (service ClientKitService) GetPartUsage(partId int64) ([]int64, error) {
+	_, err := ClientPartService{c: service.c}.Get(partId)
+	if err != nil {
+		return nil, err
+	}
+
+	kits, err := service.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	kitIds := []int64{}
+	for _, kit := range kits {
+		for _, kp := range kit.Parts {
+			if kp.ID == partId {
+				kitIds = append(kitIds, kit.ID)
+				break
+			}
+		}
+	}
+
+	return kitIds, nil
+}
+
+func (service ClientKitService) SetPartQuantity(kitId int64, partId int64, quantity uint64) error {
+	_, err := service.c.do(http.MethodPut, fmt.Sprintf("/kits/%d/parts/%d/%d", kitId, partId, quantity), nil, nil, nil)
+
+	return err
+}
+
+func (service ClientKitService) SetPartDesignators(kitId int64, partId int64, designators []string) error {
+	if designators == nil {
+		designators = []string{}
+	}
+
+	_, err := service.c.do(http.MethodPut, fmt.Sprintf("/kits/%d/parts/%d/designators", kitId, partId), nil, designators, nil)
+
+	return err
+}
+
+func (service ClientKitService) RemovePart(kitId int64, partId int64) error {
+	_, err := service.c.do(http.MethodDelete, fmt.Sprintf("/kits/%d/parts/%d", kitId, partId), nil, nil, nil)
+
+	return err
+}
+
+func (service ClientKitService) New(name string, schematic string, diagram string) (core.Kit, error) {
+	input := core.Kit{Name: name, Schematic: schematic, Diagram: diagram}
+
+	var kit core.Kit
+	_, err := service.c.do(http.MethodPost, "/kits", nil, input, &kit)
+	if err != nil {
+		return kit, err
+	}
+
+	return withLinks(kit), nil
+}
+
+func (service ClientKitService) Update(kitId int64, name string, schematic string, diagram string) (core.Kit, error) {
+	input := core.Kit{Name: name, Schematic: schematic, Diagram: diagram}
+
+	var kit core.Kit
+	_, err := service.c.do(http.MethodPut, fmt.Sprintf("/kits/%d", kitId), nil, input, &kit)
+	if err != nil {
+		return kit, err
+	}
+
+	return withLinks(kit), nil
+}
+
+func (service ClientKitService) Patch(kitId int64, patch core.KitPatch) (core.Kit, error) {
+	var kit core.Kit
+	_, err := service.c.do(http.MethodPatch, fmt.Sprintf("/kits/%d", kitId), nil, patch, &kit)
+	if err != nil {
+		return kit, err
+	}
+
+	return withLinks(kit), nil
+}
+
+func (service ClientKitService) Delete(kitId int64) error {
+	_, err := service.c.do(http.MethodDelete, fmt.Sprintf("/kits/%d", kitId), nil, nil, nil)
+
+	return err
+}
+
+func (service ClientKitService) Plan(builds []core.KitBuild, onHand map[int64]uint64) (core.Plan, error) {
+	var plan core.Plan
+	_, err := service.c.do(http.MethodPost, "/plans", nil, core.PlanRequest{Builds: builds, OnHand: onHand}, &plan)
+
+	return plan, err
+}
+
+func (service ClientKitService) Import(spec core.KitSpec) (core.KitImport, error) {
+	var result core.KitImport
+	_, err := service.c.do(http.MethodPost, "/kits/import", nil, spec, &result)
+
+	if err != nil {
+		return result, err
+	}
+
+	result.Kit = withLinks(result.Kit)
+
+	return result, nil
+}
+
+// withLinks gives a kit decoded without links, which the API leaves out
+// when a kit has none, the empty list the other services return.
+func withLinks(kit core.Kit) core.Kit {
+	if kit.Links == nil {
+		kit.Links = []core.Link{}
+	}
+
+	return kit
+}
+
+func (service ClientKitService) withContext(ctx context.Context) ClientKitService {
+	return ClientKitService{
+		c: service.c.withContext(ctx),
+	}
+}
+
+func (service ClientKitService) GetAllContext(ctx context.Context) ([]core.Kit, error) {
+	return service.withContext(ctx).GetAll()
+}
+
+func (service ClientKitService) GetContext(ctx context.Context, kitId int64) (core.Kit, error) {
+	return service.withContext(ctx).Get(kitId)
+}
+
+func (service ClientKitService) ListContext(ctx context.Context, page core.PageRequest) (core.KitPage, error) {
+	return service.withContext(ctx).List(page)
+}
+
+func (service ClientKitService) AddLinkContext(ctx context.Context, kitId int64, link string) (core.Link, error) {
+	return service.withContext(ctx).AddLink(kitId, link)
+}
+
+func (service ClientKitService) RemoveLinkContext(ctx context.Context, kitId int64, linkId int64) error {
+	return service.withContext(ctx).RemoveLink(kitId, linkId)
+}
+
+func (service ClientKitService) AddPartContext(ctx context.Context, kitId int64, partId int64, quantity uint64) error {
+	return service.withContext(ctx).AddPart(kitId, partId, quantity)
+}
+
+func (service ClientKitService) GetPartUsageContext(ctx context.Context, partId int64) ([]int64, error) {
+	return service.withContext(ctx).GetPartUsage(partId)
+}
+
+func (service ClientKitService) SetPartQuantityContext(ctx context.Context, kitId int64, partId int64, quantity uint64) error {
+	return service.withContext(ctx).SetPartQuantity(kitId, partId, quantity)
+}
+
+func (service ClientKitService) SetPartDesignatorsContext(ctx context.Context, kitId int64, partId int64, designators []string) error {
+	return service.withContext(ctx).SetPartDesignators(kitId, partId, designators)
+}
+
+func (service ClientKitService) RemovePartContext(ctx context.Context, kitId int64, partId int64) error {
+	return service.withContext(ctx).RemovePart(kitId, partId)
+}
+
+func (service ClientKitService) NewContext(ctx context.Context, name string, schematic string, diagram string) (core.Kit, error) {
+	return service.withContext(ctx).New(name, schematic, diagram)
+}
+
+func (service ClientKitService) UpdateContext(ctx context.Context, kitId int64, name string, schematic string, diagram string) (core.Kit, error) {
+	return service.withContext(ctx).Update(kitId, name, schematic, diagram)
+}
+
+func (service ClientKitService) PatchContext(ctx context.Context, kitId int64, patch core.KitPatch) (core.Kit, error) {
+	return service.withContext(ctx).Patch(kitId, patch)
+}
+
+func (service ClientKitService) DeleteContext(ctx context.Context, kitId int64) error {
+	return service.withContext(ctx).Delete(kitId)
+}
+
+func (service ClientKitService) PlanContext(ctx context.Context, builds []core.KitBuild, onHand map[int64]uint64) (core.Plan, error) {
+	return service.withContext(ctx).Plan(builds, onHand)
+}
+
+func (service ClientKitService) ImportContext(ctx context.Context, spec core.KitSpec) (core.KitImport, error) {
+	return service.withContext(ctx).Import(spec)
+}
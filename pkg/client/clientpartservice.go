@@ -0,0 +1,205 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+)
+
+type ClientPartService struct {
+	c restClient
+}
+
+func (service ClientPartService) GetAll() ([]core.Part, error) {
+	return service.Find(core.PartQuery{})
+}
+
+func (service ClientPartService) Get(partId int64) (core.Part, error) {
+	var part core.Part
+	_, err := service.c.do(http.MethodGet, fmt.Sprintf("/parts/%d", partId), nil, nil, &part)
+
+	return part, err
+}
+
+func (service ClientPartService) Find(query core.PartQuery) ([]core.Part, error) {
+	page, err := service.List(query, core.PageRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return page.Parts, nil
+}
+
+// List fetches the page in requests of at most maxPageLimit parts.
+func (service ClientPartService) List(query core.PartQuery, page core.PageRequest) (core.PartPage, error) {
+	result := core.PartPage{
+		Parts:  []core.Part{},
+		Offset: page.Offset,
+		Limit:  page.Limit,
+	}
+
+	total, err := listPages(page, func(values url.Values) (int, int, error) {
+		setPartQuery(values, query)
+
+		var parts []core.Part
+		h, err := service.c.do(http.MethodGet, "/parts", values, nil, &parts)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		result.Parts = append(result.Parts, parts...)
+
+		total, err := totalCount(h)
+
+		return len(parts), total, err
+	})
+	if err != nil {
+		return core.PartPage{}, err
+	}
+
+	result.Total = total
+
+	return result, nil
+}
+
+// setPartQuery adds the filters of query to the query parameters read
+// by GET /parts.
+func setPartQuery(values url.Values, query core.PartQuery) {
+	set := func(key string, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+
+	set("kind", string(query.Kind))
+	set("q", query.Text)
+	set("min", query.Min)
+	set("max", query.Max)
+
+	if query.InUse != nil {
+		values.Set("used", strconv.FormatBool(*query.InUse))
+	}
+
+	if query.HasLinks != nil {
+		values.Set("links", strconv.FormatBool(*query.HasLinks))
+	}
+}
+
+// AddLink validates the link before sending it, as the server looks up
+// the part first and the other services validate first.
+func (service ClientPartService) AddLink(partId int64, link string) (core.Link, error) {
+	err := core.Link{URL: link}.Validate()
+	if err != nil {
+		return core.Link{}, err
+	}
+
+	var newLink core.Link
+	_, err = service.c.do(http.MethodPost, fmt.Sprintf("/parts/%d/links", partId), nil, core.Link{URL: link}, &newLink)
+
+	return newLink, err
+}
+
+func (service ClientPartService) RemoveLink(partId int64, linkId int64) error {
+	_, err := service.c.do(http.MethodDelete, fmt.Sprintf("/parts/%d/links/%d", partId, linkId), nil, nil, nil)
+
+	return err
+}
+
+func (service ClientPartService) New(name string, kind core.PartType) (core.Part, error) {
+	var part core.Part
+	_, err := service.c.do(http.MethodPost, "/parts", nil, core.Part{Name: name, Kind: kind}, &part)
+
+	return part, err
+}
+
+func (service ClientPartService) Update(partId int64, name string, kind core.PartType) (core.Part, error) {
+	var part core.Part
+	_, err := service.c.do(http.MethodPut, fmt.Sprintf("/parts/%d", partId), nil, core.Part{Name: name, Kind: kind}, &part)
+
+	return part, err
+}
+
+func (service ClientPartService) Patch(partId int64, patch core.PartPatch) (core.Part, error) {
+	var part core.Part
+	_, err := service.c.do(http.MethodPatch, fmt.Sprintf("/parts/%d", partId), nil, patch, &part)
+
+	return part, err
+}
+
+func (service ClientPartService) Delete(partId int64) error {
+	_, err := service.c.do(http.MethodDelete, fmt.Sprintf("/parts/%d", partId), nil, nil, nil)
+
+	return err
+}
+
+func (service ClientPartService) FindDuplicates() ([][]core.Part, error) {
+	var dupes [][]core.Part
+	_, err := service.c.do(http.MethodGet, "/parts/duplicates", nil, nil, &dupes)
+
+	return dupes, err
+}
+
+func (service ClientPartService) Merge(partId int64, duplicateId int64) error {
+	query := url.Values{"duplicate": {strconv.FormatInt(duplicateId, 10)}}
+	_, err := service.c.do(http.MethodPost, fmt.Sprintf("/parts/%d/merge", partId), query, nil, nil)
+
+	return err
+}
+
+func (service ClientPartService) withContext(ctx context.Context) ClientPartService {
+	return ClientPartService{
+		c: service.c.withContext(ctx),
+	}
+}
+
+func (service ClientPartService) GetAllContext(ctx context.Context) ([]core.Part, error) {
+	return service.withContext(ctx).GetAll()
+}
+
+func (service ClientPartService) GetContext(ctx context.Context, partId int64) (core.Part, error) {
+	return service.withContext(ctx).Get(partId)
+}
+
+func (service ClientPartService) FindContext(ctx context.Context, query core.PartQuery) ([]core.Part, error) {
+	return service.withContext(ctx).Find(query)
+}
+
+func (service ClientPartService) ListContext(ctx context.Context, query core.PartQuery, page core.PageRequest) (core.PartPage, error) {
+	return service.withContext(ctx).List(query, page)
+}
+
+func (service ClientPartService) AddLinkContext(ctx context.Context, partId int64, link string) (core.Link, error) {
+	return service.withContext(ctx).AddLink(partId, link)
+}
+
+func (service ClientPartService) RemoveLinkContext(ctx context.Context, partId int64, linkId int64) error {
+	return service.withContext(ctx).RemoveLink(partId, linkId)
+}
+
+func (service ClientPartService) NewContext(ctx context.Context, name string, kind core.PartType) (core.Part, error) {
+	return service.withContext(ctx).New(name, kind)
+}
+
+func (service ClientPartService) UpdateContext(ctx context.Context, partId int64, name string, kind core.PartType) (core.Part, error) {
+	return service.withContext(ctx).Update(partId, name, kind)
+}
+
+func (service ClientPartService) PatchContext(ctx context.Context, partId int64, patch core.PartPatch) (core.Part, error) {
+	return service.withContext(ctx).Patch(partId, patch)
+}
+
+func (service ClientPartService) DeleteContext(ctx context.Context, partId int64) error {
+	return service.withContext(ctx).Delete(partId)
+}
+
+func (service ClientPartService) FindDuplicatesContext(ctx context.Context) ([][]core.Part, error) {
+	return service.withContext(ctx).FindDuplicates()
+}
+
+func (service ClientPartService) MergeContext(ctx context.Context, partId int64, duplicateId int64) error {
+	return service.withContext(ctx).Merge(partId, duplicateId)
+}
@@ -0,0 +1,29 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+type ClientStorage struct {
+	c     restClient
+	owned bool
+}
+
+// Ping asks the server whether it is ready to serve requests.
+func (s ClientStorage) Ping(ctx context.Context) error {
+	_, err := s.c.withContext(ctx).do(http.MethodGet, "/readyz", nil, nil, nil)
+
+	return err
+}
+
+// Close closes idle connections to the server when the service made
+// its own http.Client. A client given to CreateClientService is left
+// for its owner to close. The server itself keeps running.
+func (s ClientStorage) Close() error {
+	if s.owned {
+		s.c.http.CloseIdleConnections()
+	}
+
+	return nil
+}
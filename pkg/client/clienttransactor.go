@@ -0,0 +1,14 @@
+package client
+
+import (
+	"github.com/sombrerosheep/partsbundler/pkg/service"
+)
+
+type ClientTransactor struct{}
+
+// Atomic returns service.AtomicUnsupported without calling fn, as the
+// API has no transactions and the requests fn would make could not be
+// rolled back.
+func (t ClientTransactor) Atomic(fn func(svc *service.BundlerService) error) error {
+	return service.AtomicUnsupported{Reason: "bundler-server has no transactions"}
+}
@@ -0,0 +1,28 @@
+package core
+
+// ErrorResponse is the body of every error returned by the HTTP API.
+type ErrorResponse struct {
+	Error APIError `json:"error"`
+}
+
+// APIError describes a failed request. Code is a stable identifier such
+// as "part_not_found" which clients can match on instead of Message.
+// Details holds the ids and values the error refers to and Fields lists
+// the request fields which were invalid.
+type APIError struct {
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+	Fields  []FieldError           `json:"fields,omitempty"`
+}
+
+func (e APIError) Error() string {
+	return e.Message
+}
+
+// FieldError is a problem with one field, parameter or line of a
+// request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+type InvalidAdjustmentKind struct {
+	InvalidKind string
+}
+
+func (a InvalidAdjustmentKind) Error() string {
+	return fmt.Sprintf("Invalid AdjustmentKind '%s'", a.InvalidKind)
+}
+
+type AdjustmentKind string
+
+const (
+	Received AdjustmentKind = "received"
+	Consumed                = "consumed"
+	Counted                 = "counted"
+	Lost                    = "lost"
+)
+
+func (a AdjustmentKind) IsValid() error {
+	switch a {
+	case Received, Consumed, Counted, Lost:
+		return nil
+	}
+
+	return InvalidAdjustmentKind{string(a)}
+}
+
+// Stock is the quantity of a part on hand and where it is stored.
+type Stock struct {
+	PartID   int64  `json:"partId"`
+	Quantity uint64 `json:"quantity"`
+	Location string `json:"location"`
+}
+
+// StockAdjustment records a change to the stock of a part. For Counted
+// adjustments Quantity is the counted total rather than a difference.
+type StockAdjustment struct {
+	ID        int64          `json:"id"`
+	PartID    int64          `json:"partId"`
+	Kind      AdjustmentKind `json:"kind"`
+	Quantity  uint64         `json:"quantity"`
+	Note      string         `json:"note,omitempty"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+type InsufficientStock struct {
+	PartID    int64
+	Available uint64
+	Requested uint64
+}
+
+func (s InsufficientStock) Error() string {
+	return fmt.Sprintf("Part %d has %d in stock, cannot remove %d",
+		s.PartID, s.Available, s.Requested)
+}
+
+// Apply returns the stock after the adjustment has been made.
+func (s Stock) Apply(kind AdjustmentKind, quantity uint64) (Stock, error) {
+	if err := kind.IsValid(); err != nil {
+		return s, err
+	}
+
+	switch kind {
+	case Received:
+		s.Quantity += quantity
+	case Consumed, Lost:
+		if quantity > s.Quantity {
+			return s, InsufficientStock{
+				PartID:    s.PartID,
+				Available: s.Quantity,
+				Requested: quantity,
+			}
+		}
+		s.Quantity -= quantity
+	case Counted:
+		s.Quantity = quantity
+	}
+
+	return s, nil
+}
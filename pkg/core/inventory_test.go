@@ -0,0 +1,53 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Stock_Apply(t *testing.T) {
+	tests := []struct {
+		kind     AdjustmentKind
+		quantity uint64
+		expected uint64
+	}{
+		{Received, 5, 15},
+		{Consumed, 4, 6},
+		{Lost, 10, 0},
+		{Counted, 3, 3},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %d should leave %d", test.kind, test.quantity, test.expected), func(t *testing.T) {
+			stock := Stock{PartID: 1, Quantity: 10, Location: "A1"}
+
+			actual, err := stock.Apply(test.kind, test.quantity)
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, actual.Quantity)
+			assert.Equal(t, stock.Location, actual.Location)
+		})
+	}
+
+	t.Run("should return InsufficientStock when removing more than available", func(t *testing.T) {
+		stock := Stock{PartID: 1, Quantity: 2}
+
+		_, err := stock.Apply(Consumed, 3)
+
+		assert.NotNil(t, err)
+		assert.IsType(t, InsufficientStock{}, err)
+		assert.Equal(t, uint64(2), err.(InsufficientStock).Available)
+	})
+
+	t.Run("should return InvalidAdjustmentKind when kind is invalid", func(t *testing.T) {
+		stock := Stock{PartID: 1, Quantity: 2}
+
+		_, err := stock.Apply("borrowed", 1)
+
+		assert.NotNil(t, err)
+		assert.IsType(t, InvalidAdjustmentKind{}, err)
+		assert.Equal(t, "borrowed", err.(InvalidAdjustmentKind).InvalidKind)
+	})
+}
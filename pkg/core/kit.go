@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"strings"
 )
 
 type Kit struct {
@@ -13,9 +14,37 @@ type Kit struct {
 	Links     []Link    `json:"links,omitempty"`
 }
 
+// KitPatch holds the fields of a kit to change. Nil fields are left as
+// they are.
+type KitPatch struct {
+	Name      *string `json:"name,omitempty"`
+	Schematic *string `json:"schematics,omitempty"`
+	Diagram   *string `json:"diagram,omitempty"`
+}
+
+// Apply returns a copy of kit with the patch applied.
+func (p KitPatch) Apply(kit Kit) Kit {
+	if p.Name != nil {
+		kit.Name = *p.Name
+	}
+
+	if p.Schematic != nil {
+		kit.Schematic = *p.Schematic
+	}
+
+	if p.Diagram != nil {
+		kit.Diagram = *p.Diagram
+	}
+
+	return kit
+}
+
+// KitPart is a part used by a kit. When Designators are present
+// Quantity is the number of designators.
 type KitPart struct {
 	Part
-	Quantity uint64 `json:"quantity"`
+	Quantity    uint64   `json:"quantity"`
+	Designators []string `json:"designators,omitempty"`
 }
 
 type KitNotFound struct {
@@ -33,3 +62,66 @@ type PartInUse struct {
 func (p PartInUse) Error() string {
 	return fmt.Sprintf("Part %d is in use by one or more kits", p.PartID)
 }
+
+type PartNotInKit struct {
+	KitID, PartID int64
+}
+
+func (p PartNotInKit) Error() string {
+	return fmt.Sprintf("Part %d is not in Kit %d", p.PartID, p.KitID)
+}
+
+type PartAlreadyInKit struct {
+	KitID, PartID int64
+}
+
+func (p PartAlreadyInKit) Error() string {
+	return fmt.Sprintf("Part %d is already in Kit %d", p.PartID, p.KitID)
+}
+
+type DesignatorInUse struct {
+	KitID      int64
+	Designator string
+}
+
+func (d DesignatorInUse) Error() string {
+	return fmt.Sprintf("Designator %s is already used in Kit %d", d.Designator, d.KitID)
+}
+
+type DesignatorMismatch struct {
+	KitID, PartID int64
+	Designators   int
+	Quantity      uint64
+}
+
+func (d DesignatorMismatch) Error() string {
+	return fmt.Sprintf("Part %d in Kit %d has %d designators, cannot set quantity to %d",
+		d.PartID, d.KitID, d.Designators, d.Quantity)
+}
+
+// ParseDesignators splits a list of reference designators such as
+// "R1, R7 R12" and normalizes them with NormalizeDesignators.
+func ParseDesignators(s string) []string {
+	return NormalizeDesignators(strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' '
+	}))
+}
+
+// NormalizeDesignators upper cases designators and drops blanks and
+// repeats, keeping the order in which they first appear.
+func NormalizeDesignators(designators []string) []string {
+	normalized := []string{}
+	seen := map[string]bool{}
+
+	for _, d := range designators {
+		d = strings.ToUpper(strings.TrimSpace(d))
+		if d == "" || seen[d] {
+			continue
+		}
+
+		seen[d] = true
+		normalized = append(normalized, d)
+	}
+
+	return normalized
+}
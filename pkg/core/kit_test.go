@@ -0,0 +1,43 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseDesignators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"R1", []string{"R1"}},
+		{"R1, R7, R12", []string{"R1", "R7", "R12"}},
+		{"c1 c2;C3", []string{"C1", "C2", "C3"}},
+		{"R1,R1, r1", []string{"R1"}},
+		{" , ", []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			assert.Equal(t, test.expected, ParseDesignators(test.input))
+		})
+	}
+}
+
+func Test_KitPatch_Apply(t *testing.T) {
+	kit := Kit{ID: 1, Name: "Fuzz", Schematic: "example.com/s", Diagram: "example.com/d"}
+
+	t.Run("should leave nil fields as they are", func(t *testing.T) {
+		assert.Equal(t, kit, KitPatch{}.Apply(kit))
+	})
+
+	t.Run("should set the given fields", func(t *testing.T) {
+		name := "Big Fuzz"
+		diagram := ""
+
+		actual := KitPatch{Name: &name, Diagram: &diagram}.Apply(kit)
+
+		assert.Equal(t, Kit{ID: 1, Name: "Big Fuzz", Schematic: "example.com/s"}, actual)
+	})
+}
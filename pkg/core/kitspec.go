@@ -0,0 +1,221 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+type InvalidKitSpec struct {
+	Reason string
+}
+
+func (k InvalidKitSpec) Error() string {
+	return fmt.Sprintf("Invalid kit: %s", k.Reason)
+}
+
+// KitSpec is a portable description of a kit which identifies parts
+// by kind and value rather than by database id.
+type KitSpec struct {
+	Name      string        `json:"name"`
+	Schematic string        `json:"schematic,omitempty"`
+	Diagram   string        `json:"diagram,omitempty"`
+	Links     []string      `json:"links,omitempty"`
+	Parts     []KitPartSpec `json:"parts"`
+}
+
+// KitPartSpec is a part of a KitSpec. Quantity may be left out when
+// Designators are given.
+type KitPartSpec struct {
+	Kind        PartType `json:"kind"`
+	Name        string   `json:"name"`
+	Quantity    uint64   `json:"quantity,omitempty"`
+	Designators []string `json:"designators,omitempty"`
+	Links       []string `json:"links,omitempty"`
+}
+
+// KitImport reports the kit created by an import and which of its
+// parts matched existing parts or had to be created.
+type KitImport struct {
+	Kit     Kit    `json:"kit"`
+	Matched []Part `json:"matched"`
+	Created []Part `json:"created"`
+}
+
+// Value returns the normalized value used to match the part against
+// existing parts.
+func (p KitPartSpec) Value() string {
+	return NormalizeValue(p.Kind, p.Name)
+}
+
+func (s KitSpec) Validate() error {
+	if s.Name == "" {
+		return InvalidKitSpec{"name is required"}
+	}
+
+	for i, p := range s.Parts {
+		if err := p.Kind.IsValid(); err != nil {
+			return err
+		}
+
+		if p.Name == "" {
+			return InvalidKitSpec{fmt.Sprintf("part %d has no name", i+1)}
+		}
+	}
+
+	designators := map[string]bool{}
+
+	for _, p := range s.Merged() {
+		if len(p.Designators) > 0 && uint64(len(p.Designators)) != p.Quantity {
+			return InvalidKitSpec{fmt.Sprintf("%s %s has %d designators but a quantity of %d",
+				p.Kind, p.Name, len(p.Designators), p.Quantity)}
+		}
+
+		for _, d := range p.Designators {
+			if designators[d] {
+				return InvalidKitSpec{fmt.Sprintf("designator %s is used more than once", d)}
+			}
+
+			designators[d] = true
+		}
+	}
+
+	return nil
+}
+
+// Merged returns the spec's parts with entries of the same kind and
+// value combined into one. A missing quantity is the number of
+// designators, or 1 when there are none.
+func (s KitSpec) Merged() []KitPartSpec {
+	merged := []KitPartSpec{}
+	index := map[string]int{}
+
+	for _, p := range s.Parts {
+		designators := NormalizeDesignators(p.Designators)
+
+		quantity := p.Quantity
+		if quantity == 0 {
+			quantity = uint64(len(designators))
+		}
+		if quantity == 0 {
+			quantity = 1
+		}
+
+		key := string(p.Kind) + "|" + p.Value()
+
+		if i, ok := index[key]; ok {
+			merged[i].Quantity += quantity
+			merged[i].Links = appendLinks(merged[i].Links, p.Links...)
+			if len(designators) > 0 {
+				merged[i].Designators = append(merged[i].Designators, designators...)
+			}
+			continue
+		}
+
+		p.Quantity = quantity
+		p.Links = appendLinks([]string{}, p.Links...)
+		p.Designators = nil
+		if len(designators) > 0 {
+			p.Designators = designators
+		}
+
+		index[key] = len(merged)
+		merged = append(merged, p)
+	}
+
+	return merged
+}
+
+func appendLinks(links []string, urls ...string) []string {
+	for _, url := range urls {
+		found := false
+		for _, l := range links {
+			if l == url {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			links = append(links, url)
+		}
+	}
+
+	return links
+}
+
+// NewKitSpec describes kit without its database ids. Parts sharing a
+// kind and value are merged and the parts are sorted by kind and value
+// so exporting equivalent kits produces the same document.
+func NewKitSpec(kit Kit) KitSpec {
+	spec := KitSpec{
+		Name:      kit.Name,
+		Schematic: kit.Schematic,
+		Diagram:   kit.Diagram,
+		Parts:     []KitPartSpec{},
+	}
+
+	for _, l := range kit.Links {
+		spec.Links = appendLinks(spec.Links, l.URL)
+	}
+
+	for _, kp := range kit.Parts {
+		p := KitPartSpec{
+			Kind:        kp.Kind,
+			Name:        kp.Name,
+			Quantity:    kp.Quantity,
+			Designators: kp.Designators,
+		}
+
+		for _, l := range kp.Links {
+			p.Links = append(p.Links, l.URL)
+		}
+
+		spec.Parts = append(spec.Parts, p)
+	}
+
+	spec.Parts = spec.Merged()
+
+	for i := range spec.Parts {
+		if len(spec.Parts[i].Links) == 0 {
+			spec.Parts[i].Links = nil
+		}
+	}
+
+	sort.SliceStable(spec.Parts, func(i, j int) bool {
+		a, b := spec.Parts[i], spec.Parts[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+
+		return a.Value() < b.Value()
+	})
+
+	return spec
+}
+
+// WriteKitSpec encodes spec as indented JSON readable by ReadKitSpec.
+func WriteKitSpec(w io.Writer, spec KitSpec) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(spec)
+}
+
+// ReadKitSpec decodes and validates a JSON kit description such as
+// internal/sqlite/import/ts808.json.
+func ReadKitSpec(r io.Reader) (KitSpec, error) {
+	var spec KitSpec
+
+	err := json.NewDecoder(r).Decode(&spec)
+	if err != nil {
+		return KitSpec{}, err
+	}
+
+	if err = spec.Validate(); err != nil {
+		return KitSpec{}, err
+	}
+
+	return spec, nil
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ReadKitSpec(t *testing.T) {
+	t.Run("should read kit import file", func(t *testing.T) {
+		f, err := os.Open("../../internal/sqlite/import/ts808.json")
+		if err != nil {
+			t.Fatalf("Error opening kit file: %s", err)
+		}
+		defer f.Close()
+
+		spec, err := ReadKitSpec(f)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "TS-808", spec.Name)
+		assert.Len(t, spec.Parts, 10)
+		assert.Equal(t, KitPartSpec{Kind: Capacitor, Name: "1500pf"}, spec.Parts[3])
+		assert.Equal(t, "1.5nF", spec.Parts[3].Value())
+	})
+
+	t.Run("should return InvalidPartType when a kind is invalid", func(t *testing.T) {
+		input := `{"name": "kit", "parts": [{"kind": "Flux Capacitor", "name": "1.21GW"}]}`
+
+		_, err := ReadKitSpec(strings.NewReader(input))
+
+		assert.NotNil(t, err)
+		assert.IsType(t, InvalidPartType{}, err)
+	})
+
+	t.Run("should return InvalidKitSpec when name is missing", func(t *testing.T) {
+		input := `{"parts": []}`
+
+		_, err := ReadKitSpec(strings.NewReader(input))
+
+		assert.NotNil(t, err)
+		assert.IsType(t, InvalidKitSpec{}, err)
+	})
+}
+
+func Test_KitSpec_Merged(t *testing.T) {
+	spec := KitSpec{
+		Name: "kit",
+		Parts: []KitPartSpec{
+			{Kind: Capacitor, Name: "1.5nf"},
+			{Kind: Resistor, Name: "10k", Quantity: 2},
+			{Kind: Capacitor, Name: "1500pf", Quantity: 3, Links: []string{"example.com"}},
+		},
+	}
+
+	expected := []KitPartSpec{
+		{Kind: Capacitor, Name: "1.5nf", Quantity: 4, Links: []string{"example.com"}},
+		{Kind: Resistor, Name: "10k", Quantity: 2, Links: []string{}},
+	}
+
+	assert.Equal(t, expected, spec.Merged())
+}
+
+func Test_NewKitSpec(t *testing.T) {
+	kit := Kit{
+		ID:        7,
+		Name:      "kit",
+		Schematic: "schematic.pdf",
+		Links:     []Link{{ID: 3, URL: "example.com/kit"}},
+		Parts: []KitPart{
+			{Part: Part{ID: 4, Kind: Resistor, Name: "10k", Value: "10k"}, Quantity: 2},
+			{Part: Part{ID: 1, Kind: Capacitor, Name: "1.5nf", Value: "1.5nF", Links: []Link{{ID: 9, URL: "example.com"}}}, Quantity: 1},
+			{Part: Part{ID: 2, Kind: Capacitor, Name: "1500pf", Value: "1.5nF", Links: []Link{{ID: 8, URL: "example.com"}}}, Quantity: 3},
+		},
+	}
+
+	expected := KitSpec{
+		Name:      "kit",
+		Schematic: "schematic.pdf",
+		Links:     []string{"example.com/kit"},
+		Parts: []KitPartSpec{
+			{Kind: Capacitor, Name: "1.5nf", Quantity: 4, Links: []string{"example.com"}},
+			{Kind: Resistor, Name: "10k", Quantity: 2},
+		},
+	}
+
+	actual := NewKitSpec(kit)
+
+	assert.Equal(t, expected, actual)
+
+	t.Run("should read what it writes", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := WriteKitSpec(&buf, actual)
+
+		assert.Nil(t, err)
+
+		read, err := ReadKitSpec(&buf)
+
+		assert.Nil(t, err)
+		assert.Equal(t, actual, read)
+	})
+}
+
+func Test_KitSpec_Designators(t *testing.T) {
+	t.Run("should count designators when quantity is missing", func(t *testing.T) {
+		spec := KitSpec{
+			Name: "kit",
+			Parts: []KitPartSpec{
+				{Kind: Resistor, Name: "10k", Designators: []string{"r1", "R7"}},
+				{Kind: Resistor, Name: "10K", Quantity: 1, Designators: []string{"R12"}},
+			},
+		}
+
+		expected := []KitPartSpec{
+			{Kind: Resistor, Name: "10k", Quantity: 3, Designators: []string{"R1", "R7", "R12"}, Links: []string{}},
+		}
+
+		assert.Nil(t, spec.Validate())
+		assert.Equal(t, expected, spec.Merged())
+	})
+
+	t.Run("should return InvalidKitSpec when quantity does not match", func(t *testing.T) {
+		spec := KitSpec{
+			Name: "kit",
+			Parts: []KitPartSpec{
+				{Kind: Resistor, Name: "10k", Quantity: 3, Designators: []string{"R1", "R7"}},
+			},
+		}
+
+		assert.IsType(t, InvalidKitSpec{}, spec.Validate())
+	})
+
+	t.Run("should return InvalidKitSpec when a designator is repeated", func(t *testing.T) {
+		spec := KitSpec{
+			Name: "kit",
+			Parts: []KitPartSpec{
+				{Kind: Resistor, Name: "10k", Designators: []string{"R1"}},
+				{Kind: Resistor, Name: "1k", Designators: []string{"R1"}},
+			},
+		}
+
+		assert.IsType(t, InvalidKitSpec{}, spec.Validate())
+	})
+}
@@ -16,3 +16,11 @@ type LinkNotFound struct {
 func (l LinkNotFound) Error() string {
 	return fmt.Sprintf("Link %d not found on Part %d", l.LinkID, l.OwnerID)
 }
+
+type KitLinkNotFound struct {
+	LinkID, KitID int64
+}
+
+func (l KitLinkNotFound) Error() string {
+	return fmt.Sprintf("Link %d not found on Kit %d", l.LinkID, l.KitID)
+}
@@ -0,0 +1,179 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Sort keys accepted by PageRequest.
+const (
+	SortByID        = "id"
+	SortByName      = "name"
+	SortByKind      = "kind"
+	SortByValue     = "value"
+	SortByPartCount = "parts"
+)
+
+// PartSortKeys and KitSortKeys list the sort keys each list accepts.
+var (
+	PartSortKeys = []string{SortByID, SortByName, SortByKind, SortByValue}
+	KitSortKeys  = []string{SortByID, SortByName, SortByPartCount}
+)
+
+type InvalidPageRequest struct {
+	Reason string
+}
+
+func (p InvalidPageRequest) Error() string {
+	return fmt.Sprintf("Invalid page request: %s", p.Reason)
+}
+
+// PageRequest selects a page of a sorted list. A Limit of zero returns
+// every item from Offset on. An empty Sort sorts by id.
+type PageRequest struct {
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+	Sort   string `json:"sort,omitempty"`
+	Desc   bool   `json:"desc,omitempty"`
+}
+
+// Validate reports whether the page request can be used with a list
+// sortable by keys.
+func (p PageRequest) Validate(keys []string) error {
+	if p.Offset < 0 {
+		return InvalidPageRequest{"offset must not be negative"}
+	}
+
+	if p.Limit < 0 {
+		return InvalidPageRequest{"limit must not be negative"}
+	}
+
+	if p.Sort == "" {
+		return nil
+	}
+
+	for _, k := range keys {
+		if p.Sort == k {
+			return nil
+		}
+	}
+
+	return InvalidPageRequest{fmt.Sprintf("cannot sort by '%s', expected one of %s", p.Sort, strings.Join(keys, ", "))}
+}
+
+// Bounds returns the slice bounds of the page within a list of total
+// items.
+func (p PageRequest) Bounds(total int) (int, int) {
+	start := p.Offset
+	if start > total {
+		start = total
+	}
+
+	end := total
+	if p.Limit > 0 && start+p.Limit < total {
+		end = start + p.Limit
+	}
+
+	return start, end
+}
+
+// PartPage is one page of parts. Total counts every matching part.
+type PartPage struct {
+	Parts  []Part `json:"parts"`
+	Total  int    `json:"total"`
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+}
+
+// KitPage is one page of kits. Total counts every kit.
+type KitPage struct {
+	Kits   []Kit `json:"kits"`
+	Total  int   `json:"total"`
+	Offset int   `json:"offset"`
+	Limit  int   `json:"limit"`
+}
+
+// partValueLess orders parts by kind, then by parsed value with parts
+// that have no value first, then by their normalized value.
+func partValueLess(a, b Part) bool {
+	if a.Kind != b.Kind {
+		return a.Kind < b.Kind
+	}
+
+	av, aerr := a.ParsedValue()
+	bv, berr := b.ParsedValue()
+
+	switch {
+	case aerr != nil && berr == nil:
+		return true
+	case aerr == nil && berr != nil:
+		return false
+	case aerr == nil && berr == nil && av.Magnitude != bv.Magnitude:
+		return av.Magnitude < bv.Magnitude
+	}
+
+	return a.Value < b.Value
+}
+
+// SortParts sorts parts in place by key, breaking ties by id.
+func SortParts(parts []Part, key string, desc bool) {
+	less := func(a, b Part) bool {
+		switch key {
+		case SortByName:
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		case SortByKind:
+			if a.Kind != b.Kind {
+				return a.Kind < b.Kind
+			}
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		case SortByValue:
+			if partValueLess(a, b) {
+				return true
+			}
+			if partValueLess(b, a) {
+				return false
+			}
+		}
+
+		return a.ID < b.ID
+	}
+
+	sort.SliceStable(parts, func(i, j int) bool {
+		if desc {
+			return less(parts[j], parts[i])
+		}
+
+		return less(parts[i], parts[j])
+	})
+}
+
+// SortKits sorts kits in place by key, breaking ties by id.
+func SortKits(kits []Kit, key string, desc bool) {
+	less := func(a, b Kit) bool {
+		switch key {
+		case SortByName:
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		case SortByPartCount:
+			if len(a.Parts) != len(b.Parts) {
+				return len(a.Parts) < len(b.Parts)
+			}
+		}
+
+		return a.ID < b.ID
+	}
+
+	sort.SliceStable(kits, func(i, j int) bool {
+		if desc {
+			return less(kits[j], kits[i])
+		}
+
+		return less(kits[i], kits[j])
+	})
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PageRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		page  PageRequest
+		valid bool
+	}{
+		{"empty request", PageRequest{}, true},
+		{"known sort key", PageRequest{Offset: 10, Limit: 5, Sort: SortByName, Desc: true}, true},
+		{"negative offset", PageRequest{Offset: -1}, false},
+		{"negative limit", PageRequest{Limit: -1}, false},
+		{"unknown sort key", PageRequest{Sort: SortByPartCount}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.page.Validate(PartSortKeys)
+
+			if test.valid {
+				assert.Nil(t, err)
+			} else {
+				assert.IsType(t, InvalidPageRequest{}, err)
+			}
+		})
+	}
+}
+
+func Test_PageRequest_Bounds(t *testing.T) {
+	tests := []struct {
+		name       string
+		page       PageRequest
+		start, end int
+	}{
+		{"everything", PageRequest{}, 0, 10},
+		{"first page", PageRequest{Limit: 4}, 0, 4},
+		{"partial last page", PageRequest{Offset: 8, Limit: 4}, 8, 10},
+		{"offset without limit", PageRequest{Offset: 3}, 3, 10},
+		{"past the end", PageRequest{Offset: 12, Limit: 4}, 10, 10},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start, end := test.page.Bounds(10)
+
+			assert.Equal(t, test.start, start)
+			assert.Equal(t, test.end, end)
+		})
+	}
+}
+
+func Test_SortParts(t *testing.T) {
+	parts := []Part{
+		{ID: 1, Kind: Capacitor, Name: "220nf", Value: "220nF"},
+		{ID: 2, Kind: Resistor, Name: "10k", Value: "10k"},
+		{ID: 3, Kind: Capacitor, Name: "0.1uf", Value: "100nF"},
+		{ID: 4, Kind: IC, Name: "TL072", Value: "TL072"},
+		{ID: 5, Kind: Capacitor, Name: "big electro", Value: "BIG ELECTRO"},
+		{ID: 6, Kind: Resistor, Name: "10k", Value: "10k"},
+	}
+
+	ids := func(parts []Part) []int64 {
+		out := []int64{}
+		for _, p := range parts {
+			out = append(out, p.ID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		key      string
+		desc     bool
+		expected []int64
+	}{
+		{"by id", SortByID, false, []int64{1, 2, 3, 4, 5, 6}},
+		{"by id descending", SortByID, true, []int64{6, 5, 4, 3, 2, 1}},
+		{"by name", SortByName, false, []int64{3, 2, 6, 1, 4, 5}},
+		{"by kind", SortByKind, false, []int64{3, 1, 5, 4, 2, 6}},
+		{"by value", SortByValue, false, []int64{5, 3, 1, 4, 2, 6}},
+		{"by value descending", SortByValue, true, []int64{6, 2, 4, 1, 3, 5}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sorted := append([]Part{}, parts...)
+
+			SortParts(sorted, test.key, test.desc)
+
+			assert.Equal(t, test.expected, ids(sorted))
+		})
+	}
+}
+
+func Test_SortKits(t *testing.T) {
+	kits := []Kit{
+		{ID: 1, Name: "Fuzz", Parts: make([]KitPart, 2)},
+		{ID: 2, Name: "Delay"},
+		{ID: 3, Name: "Overdrive", Parts: make([]KitPart, 3)},
+		{ID: 4, Name: "Boost", Parts: make([]KitPart, 2)},
+	}
+
+	ids := func(kits []Kit) []int64 {
+		out := []int64{}
+		for _, k := range kits {
+			out = append(out, k.ID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		key      string
+		desc     bool
+		expected []int64
+	}{
+		{"by id", SortByID, false, []int64{1, 2, 3, 4}},
+		{"by name", SortByName, false, []int64{4, 2, 1, 3}},
+		{"by part count", SortByPartCount, false, []int64{2, 1, 4, 3}},
+		{"by part count descending", SortByPartCount, true, []int64{3, 4, 1, 2}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sorted := append([]Kit{}, kits...)
+
+			SortKits(sorted, test.key, test.desc)
+
+			assert.Equal(t, test.expected, ids(sorted))
+		})
+	}
+}
@@ -37,9 +37,39 @@ type Part struct {
 	ID    int64    `json:"id"`
 	Kind  PartType `json:"kind"`
 	Name  string   `json:"name"`
+	Value string   `json:"value"`
 	Links []Link   `json:"links"`
 }
 
+// ParsedValue parses the part's name into a numeric Value. It returns
+// InvalidValue for parts that do not carry a value, such as ICs.
+func (p Part) ParsedValue() (Value, error) {
+	return ParseValue(p.Kind, p.Name)
+}
+
+// PartPatch holds the fields of a part to change. Nil fields are left
+// as they are.
+type PartPatch struct {
+	Name *string   `json:"name,omitempty"`
+	Kind *PartType `json:"kind,omitempty"`
+}
+
+// Apply returns a copy of part with the patch applied. The part's Value
+// is normalized again from its new name and kind.
+func (p PartPatch) Apply(part Part) Part {
+	if p.Name != nil {
+		part.Name = *p.Name
+	}
+
+	if p.Kind != nil {
+		part.Kind = *p.Kind
+	}
+
+	part.Value = NormalizeValue(part.Kind, part.Name)
+
+	return part
+}
+
 type PartNotFound struct {
 	PartID int64
 }
@@ -47,3 +77,50 @@ type PartNotFound struct {
 func (p PartNotFound) Error() string {
 	return fmt.Sprintf("Part %d not found", p.PartID)
 }
+
+type CannotMergeParts struct {
+	PartID, DuplicateID int64
+}
+
+func (p CannotMergeParts) Error() string {
+	return fmt.Sprintf("Cannot merge Part %d into Part %d", p.DuplicateID, p.PartID)
+}
+
+// IsDuplicateOf reports whether both parts are the same kind of
+// component with an equivalent value.
+func (p Part) IsDuplicateOf(other Part) bool {
+	return p.Kind == other.Kind &&
+		NormalizeValue(p.Kind, p.Name) == NormalizeValue(other.Kind, other.Name)
+}
+
+// FindDuplicates groups parts of the same PartType with equivalent
+// values. Only groups with more than one part are returned, each in
+// the order the parts were given.
+func FindDuplicates(parts []Part) [][]Part {
+	type key struct {
+		kind  PartType
+		value string
+	}
+
+	order := []key{}
+	groups := map[key][]Part{}
+
+	for _, p := range parts {
+		k := key{p.Kind, NormalizeValue(p.Kind, p.Name)}
+
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+
+		groups[k] = append(groups[k], p)
+	}
+
+	dupes := [][]Part{}
+	for _, k := range order {
+		if len(groups[k]) > 1 {
+			dupes = append(dupes, groups[k])
+		}
+	}
+
+	return dupes
+}
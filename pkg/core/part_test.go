@@ -39,3 +39,62 @@ func Test_PartType_IsValid(t *testing.T) {
 		})
 	}
 }
+
+func Test_FindDuplicates(t *testing.T) {
+	parts := []Part{
+		{ID: 1, Kind: Resistor, Name: "2.2M"},
+		{ID: 2, Kind: Resistor, Name: "1k"},
+		{ID: 3, Kind: Capacitor, Name: "1.5nf"},
+		{ID: 4, Kind: Potentiometer, Name: "1k"},
+		{ID: 5, Kind: Capacitor, Name: "1500pf"},
+		{ID: 6, Kind: Resistor, Name: "1K"},
+		{ID: 7, Kind: Resistor, Name: "1000r"},
+	}
+
+	expected := [][]Part{
+		{parts[1], parts[5], parts[6]},
+		{parts[2], parts[4]},
+	}
+
+	dupes := FindDuplicates(parts)
+
+	assert.Equal(t, expected, dupes)
+}
+
+func Test_FindDuplicates_None(t *testing.T) {
+	parts := []Part{
+		{ID: 1, Kind: Resistor, Name: "1k"},
+		{ID: 2, Kind: Potentiometer, Name: "1k"},
+	}
+
+	dupes := FindDuplicates(parts)
+
+	assert.Len(t, dupes, 0)
+}
+
+func Test_PartPatch_Apply(t *testing.T) {
+	part := Part{ID: 1, Kind: Resistor, Name: "1000r", Value: "1k"}
+
+	t.Run("should leave nil fields as they are", func(t *testing.T) {
+		assert.Equal(t, part, PartPatch{}.Apply(part))
+	})
+
+	t.Run("should renormalize the value", func(t *testing.T) {
+		name := "4700"
+
+		actual := PartPatch{Name: &name}.Apply(part)
+
+		assert.Equal(t, "4700", actual.Name)
+		assert.Equal(t, NormalizeValue(Resistor, "4700"), actual.Value)
+		assert.Equal(t, Resistor, actual.Kind)
+	})
+
+	t.Run("should change the kind", func(t *testing.T) {
+		kind := PartType(IC)
+		name := "TL072"
+
+		actual := PartPatch{Name: &name, Kind: &kind}.Apply(part)
+
+		assert.Equal(t, Part{ID: 1, Kind: IC, Name: "TL072", Value: NormalizeValue(IC, "TL072")}, actual)
+	})
+}
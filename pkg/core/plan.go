@@ -0,0 +1,98 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// KitBuild is a request to build Count copies of a kit.
+type KitBuild struct {
+	KitID int64  `json:"kitId"`
+	Count uint64 `json:"count"`
+}
+
+// PlanRequest describes a batch of builds and, optionally, how many
+// of each part are already on hand keyed by part ID.
+type PlanRequest struct {
+	Builds []KitBuild       `json:"builds"`
+	OnHand map[int64]uint64 `json:"onHand,omitempty"`
+}
+
+type PlanItem struct {
+	Part      Part   `json:"part"`
+	Required  uint64 `json:"required"`
+	OnHand    uint64 `json:"onHand"`
+	Shortfall uint64 `json:"shortfall"`
+}
+
+// Plan is the consolidated bill of materials for a batch of builds.
+type Plan struct {
+	Builds []KitBuild `json:"builds"`
+	Items  []PlanItem `json:"items"`
+}
+
+// NewPlan multiplies the parts of each kit by its build count and
+// merges parts shared between kits into a single item. Kits must
+// contain every kit referenced by builds, and each build must have a
+// count of at least one. A build whose count makes a part's requirement
+// overflow is rejected with a ValidationError. onHand may be nil.
+func NewPlan(builds []KitBuild, kits map[int64]Kit, onHand map[int64]uint64) (Plan, error) {
+	f := fieldErrors{}
+	for i, build := range builds {
+		f.checkQuantity(fmt.Sprintf("builds[%d].count", i), build.Count)
+	}
+
+	if err := f.err(); err != nil {
+		return Plan{}, err
+	}
+
+	plan := Plan{
+		Builds: builds,
+		Items:  []PlanItem{},
+	}
+
+	items := map[int64]*PlanItem{}
+
+	for i, build := range builds {
+		kit, ok := kits[build.KitID]
+		if !ok {
+			return Plan{}, KitNotFound{KitID: build.KitID}
+		}
+
+		for _, kp := range kit.Parts {
+			item, ok := items[kp.ID]
+			if !ok {
+				item = &PlanItem{Part: kp.Part}
+				items[kp.ID] = item
+			}
+
+			if kp.Quantity > 0 && build.Count > (math.MaxUint64-item.Required)/kp.Quantity {
+				return Plan{}, InvalidField(fmt.Sprintf("builds[%d].count", i), "requires more parts than can be counted")
+			}
+
+			item.Required += kp.Quantity * build.Count
+		}
+	}
+
+	for _, item := range items {
+		item.OnHand = onHand[item.Part.ID]
+
+		if item.Required > item.OnHand {
+			item.Shortfall = item.Required - item.OnHand
+		}
+
+		plan.Items = append(plan.Items, *item)
+	}
+
+	sort.Slice(plan.Items, func(i, j int) bool {
+		a, b := plan.Items[i].Part, plan.Items[j].Part
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+
+		return a.ID < b.ID
+	})
+
+	return plan, nil
+}
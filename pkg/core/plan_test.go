@@ -0,0 +1,88 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewPlan(t *testing.T) {
+	resistor := Part{ID: 1, Kind: Resistor, Name: "10k"}
+	transistor := Part{ID: 2, Kind: Transistor, Name: "2N3904"}
+	ic := Part{ID: 3, Kind: IC, Name: "TL072"}
+
+	kits := map[int64]Kit{
+		1: {
+			ID: 1,
+			Parts: []KitPart{
+				{Part: resistor, Quantity: 5},
+				{Part: transistor, Quantity: 3},
+			},
+		},
+		2: {
+			ID: 2,
+			Parts: []KitPart{
+				{Part: transistor, Quantity: 1},
+				{Part: ic, Quantity: 1},
+			},
+		},
+	}
+
+	t.Run("should multiply and merge kit parts", func(t *testing.T) {
+		builds := []KitBuild{{KitID: 1, Count: 2}, {KitID: 2, Count: 3}}
+
+		expected := []PlanItem{
+			{Part: ic, Required: 3, Shortfall: 3},
+			{Part: resistor, Required: 10, Shortfall: 10},
+			{Part: transistor, Required: 9, Shortfall: 9},
+		}
+
+		plan, err := NewPlan(builds, kits, nil)
+
+		assert.Nil(t, err)
+		assert.Equal(t, builds, plan.Builds)
+		assert.Equal(t, expected, plan.Items)
+	})
+
+	t.Run("should subtract parts on hand", func(t *testing.T) {
+		builds := []KitBuild{{KitID: 1, Count: 1}}
+		onHand := map[int64]uint64{1: 2, 2: 10}
+
+		expected := []PlanItem{
+			{Part: resistor, Required: 5, OnHand: 2, Shortfall: 3},
+			{Part: transistor, Required: 3, OnHand: 10, Shortfall: 0},
+		}
+
+		plan, err := NewPlan(builds, kits, onHand)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, plan.Items)
+	})
+
+	t.Run("should reject builds without a count", func(t *testing.T) {
+		builds := []KitBuild{{KitID: 1, Count: 1}, {KitID: 2}}
+
+		_, err := NewPlan(builds, kits, nil)
+
+		assert.Equal(t, InvalidField("builds[1].count", "must be greater than zero"), err)
+	})
+
+	t.Run("should reject counts which overflow the parts required", func(t *testing.T) {
+		builds := []KitBuild{{KitID: 2, Count: 1}, {KitID: 1, Count: math.MaxUint64 / 3}}
+
+		_, err := NewPlan(builds, kits, nil)
+
+		assert.Equal(t, InvalidField("builds[1].count", "requires more parts than can be counted"), err)
+	})
+
+	t.Run("should return KitNotFound when a kit is missing", func(t *testing.T) {
+		builds := []KitBuild{{KitID: 99, Count: 1}}
+
+		_, err := NewPlan(builds, kits, nil)
+
+		assert.NotNil(t, err)
+		assert.IsType(t, KitNotFound{}, err)
+		assert.Equal(t, int64(99), err.(KitNotFound).KitID)
+	})
+}
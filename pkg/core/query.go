@@ -0,0 +1,161 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+type InvalidPartQuery struct {
+	Reason string
+}
+
+func (q InvalidPartQuery) Error() string {
+	return fmt.Sprintf("Invalid part query: %s", q.Reason)
+}
+
+// PartQuery describes the parts to find. Zero fields match every part.
+type PartQuery struct {
+	// Kind matches parts of a single PartType.
+	Kind PartType `json:"kind,omitempty"`
+	// Text matches parts whose name or value contains it, ignoring case.
+	Text string `json:"text,omitempty"`
+	// Min and Max bound the parsed value of the part's name. Both are
+	// inclusive and require Kind to be a PartType that carries a value.
+	Min string `json:"min,omitempty"`
+	Max string `json:"max,omitempty"`
+	// InUse matches parts that are, or are not, used by any kit.
+	InUse *bool `json:"inUse,omitempty"`
+	// HasLinks matches parts that have, or lack, supplier links.
+	HasLinks *bool `json:"hasLinks,omitempty"`
+}
+
+type valueRange struct {
+	min, max *Value
+}
+
+func (q PartQuery) valueRange() (valueRange, error) {
+	r := valueRange{}
+
+	if q.Min == "" && q.Max == "" {
+		return r, nil
+	}
+
+	if q.Kind == "" {
+		return r, InvalidPartQuery{"a value range requires a part kind"}
+	}
+
+	if !q.Kind.HasValue() {
+		return r, InvalidPartQuery{fmt.Sprintf("%s parts do not have a value range", q.Kind)}
+	}
+
+	if q.Min != "" {
+		min, err := ParseValue(q.Kind, q.Min)
+		if err != nil {
+			return r, err
+		}
+
+		r.min = &min
+	}
+
+	if q.Max != "" {
+		max, err := ParseValue(q.Kind, q.Max)
+		if err != nil {
+			return r, err
+		}
+
+		r.max = &max
+	}
+
+	if r.min != nil && r.max != nil && r.min.Magnitude > r.max.Magnitude && !r.min.Equal(*r.max) {
+		return r, InvalidPartQuery{fmt.Sprintf("min %s is greater than max %s", q.Min, q.Max)}
+	}
+
+	return r, nil
+}
+
+func (r valueRange) contains(v Value) bool {
+	if r.min != nil && v.Magnitude < r.min.Magnitude && !v.Equal(*r.min) {
+		return false
+	}
+
+	if r.max != nil && v.Magnitude > r.max.Magnitude && !v.Equal(*r.max) {
+		return false
+	}
+
+	return true
+}
+
+// matches reports whether the part's value is within the range. Every
+// part matches an empty range.
+func (r valueRange) matches(p Part) bool {
+	if r.min == nil && r.max == nil {
+		return true
+	}
+
+	v, err := p.ParsedValue()
+
+	return err == nil && r.contains(v)
+}
+
+// InRange reports whether the part's value is within the query's Min
+// and Max, ignoring the query's other fields. It is false for queries
+// whose range is invalid.
+func (q PartQuery) InRange(p Part) bool {
+	r, err := q.valueRange()
+
+	return err == nil && r.matches(p)
+}
+
+// Validate reports whether the query can be run.
+func (q PartQuery) Validate() error {
+	if q.Kind != "" {
+		if err := q.Kind.IsValid(); err != nil {
+			return err
+		}
+	}
+
+	_, err := q.valueRange()
+
+	return err
+}
+
+// Filter returns the parts matching the query, in the order given.
+// inUse reports whether a part is used by any kit.
+func (q PartQuery) Filter(parts []Part, inUse func(partId int64) bool) ([]Part, error) {
+	err := q.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	r, _ := q.valueRange()
+	text := strings.ToLower(q.Text)
+	matched := []Part{}
+
+	for _, p := range parts {
+		if q.Kind != "" && p.Kind != q.Kind {
+			continue
+		}
+
+		if text != "" &&
+			!strings.Contains(strings.ToLower(p.Name), text) &&
+			!strings.Contains(strings.ToLower(p.Value), text) {
+			continue
+		}
+
+		if !r.matches(p) {
+			continue
+		}
+
+		if q.InUse != nil && inUse(p.ID) != *q.InUse {
+			continue
+		}
+
+		if q.HasLinks != nil && (len(p.Links) > 0) != *q.HasLinks {
+			continue
+		}
+
+		matched = append(matched, p)
+	}
+
+	return matched, nil
+}
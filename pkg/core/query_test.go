@@ -0,0 +1,88 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PartQuery_Filter(t *testing.T) {
+	yes, no := true, false
+	link := []Link{{ID: 1, URL: "example.com/10nf"}}
+
+	parts := []Part{
+		{ID: 1, Kind: Capacitor, Name: "10nf", Value: "10nF", Links: link},
+		{ID: 2, Kind: Capacitor, Name: "47nf", Value: "47nF"},
+		{ID: 3, Kind: Capacitor, Name: "0.1uf", Value: "100nF"},
+		{ID: 4, Kind: Capacitor, Name: "220nf", Value: "220nF"},
+		{ID: 5, Kind: Resistor, Name: "4k7", Value: "4.7k"},
+		{ID: 6, Kind: IC, Name: "TL072", Value: "TL072"},
+		{ID: 7, Kind: Capacitor, Name: "big electro", Value: "BIG ELECTRO"},
+	}
+	used := map[int64]bool{2: true, 6: true}
+	inUse := func(partId int64) bool { return used[partId] }
+
+	ids := func(parts []Part) []int64 {
+		out := []int64{}
+		for _, p := range parts {
+			out = append(out, p.ID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		query    PartQuery
+		expected []int64
+	}{
+		{"empty query", PartQuery{}, []int64{1, 2, 3, 4, 5, 6, 7}},
+		{"kind", PartQuery{Kind: Resistor}, []int64{5}},
+		{"text in name", PartQuery{Text: "tl0"}, []int64{6}},
+		{"text in value", PartQuery{Text: "4.7"}, []int64{5}},
+		{"inclusive range", PartQuery{Kind: Capacitor, Min: "10nf", Max: "100nf"}, []int64{1, 2, 3}},
+		{"range with other notation", PartQuery{Kind: Capacitor, Min: "0.047u"}, []int64{2, 3, 4}},
+		{"range upper bound only", PartQuery{Kind: Capacitor, Max: "47n"}, []int64{1, 2}},
+		{"in use", PartQuery{InUse: &yes}, []int64{2, 6}},
+		{"not in use", PartQuery{Kind: Capacitor, InUse: &no}, []int64{1, 3, 4, 7}},
+		{"has links", PartQuery{HasLinks: &yes}, []int64{1}},
+		{"lacks links", PartQuery{Kind: Capacitor, HasLinks: &no}, []int64{2, 3, 4, 7}},
+		{"combined", PartQuery{Kind: Capacitor, Min: "10n", Max: "220n", InUse: &no, HasLinks: &no}, []int64{3, 4}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := test.query.Filter(parts, inUse)
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, ids(actual))
+		})
+	}
+}
+
+func Test_PartQuery_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   PartQuery
+		errType error
+	}{
+		{"invalid kind", PartQuery{Kind: "Flux Capacitor"}, InvalidPartType{}},
+		{"range without kind", PartQuery{Min: "10n"}, InvalidPartQuery{}},
+		{"range on a kind without values", PartQuery{Kind: IC, Max: "10"}, InvalidPartQuery{}},
+		{"unparseable bound", PartQuery{Kind: Capacitor, Min: "lots"}, InvalidValue{}},
+		{"min greater than max", PartQuery{Kind: Resistor, Min: "10k", Max: "1k"}, InvalidPartQuery{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.query.Validate()
+
+			assert.IsType(t, test.errType, err)
+		})
+	}
+
+	t.Run("should allow equal bounds", func(t *testing.T) {
+		err := PartQuery{Kind: Capacitor, Min: "0.1u", Max: "100n"}.Validate()
+
+		assert.Nil(t, err)
+	})
+}
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// MaxNameLength is the longest name a part or kit may have.
+const MaxNameLength = 200
+
+// ValidationError lists the fields of an input which are invalid.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (v ValidationError) Error() string {
+	problems := make([]string, len(v.Fields))
+	for i, f := range v.Fields {
+		problems[i] = fmt.Sprintf("%s %s", f.Field, f.Message)
+	}
+
+	return fmt.Sprintf("Invalid input: %s", strings.Join(problems, "; "))
+}
+
+// InvalidField returns a ValidationError for a single field.
+func InvalidField(field, message string) ValidationError {
+	return ValidationError{Fields: []FieldError{{Field: field, Message: message}}}
+}
+
+// fieldErrors collects the problems found while validating an input.
+type fieldErrors []FieldError
+
+func (f *fieldErrors) add(field, message string) {
+	*f = append(*f, FieldError{Field: field, Message: message})
+}
+
+// err returns the collected problems as a ValidationError, or nil when
+// there are none.
+func (f fieldErrors) err() error {
+	if len(f) == 0 {
+		return nil
+	}
+
+	return ValidationError{Fields: f}
+}
+
+func (f *fieldErrors) checkName(field, name string) {
+	switch {
+	case strings.TrimSpace(name) == "":
+		f.add(field, "must not be empty")
+	case len(name) > MaxNameLength:
+		f.add(field, fmt.Sprintf("must be at most %d characters", MaxNameLength))
+	}
+}
+
+// checkURL accepts http and https URLs. The scheme may be left off, as
+// in "example.com/schematic.pdf".
+func (f *fieldErrors) checkURL(field, s string) {
+	if strings.TrimSpace(s) == "" {
+		f.add(field, "must not be empty")
+		return
+	}
+
+	if strings.IndexFunc(s, unicode.IsSpace) >= 0 {
+		f.add(field, "must not contain spaces")
+		return
+	}
+
+	raw := s
+	if !strings.Contains(s, "://") {
+		raw = "http://" + s
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		f.add(field, "must be an http or https URL")
+		return
+	}
+
+	host := u.Hostname()
+	if !strings.Contains(host, ".") && host != "localhost" {
+		f.add(field, "must include a host such as example.com")
+	}
+}
+
+func (f *fieldErrors) checkQuantity(field string, quantity uint64) {
+	if quantity == 0 {
+		f.add(field, "must be greater than zero")
+	}
+}
+
+// Validate reports whether the part can be saved. An unknown kind is
+// reported as InvalidPartType, any other problem as a ValidationError.
+func (p Part) Validate() error {
+	if err := p.Kind.IsValid(); err != nil {
+		return err
+	}
+
+	f := fieldErrors{}
+	f.checkName("name", p.Name)
+
+	for i, l := range p.Links {
+		f.checkURL(fmt.Sprintf("links[%d].url", i), l.URL)
+	}
+
+	return f.err()
+}
+
+// Validate reports whether the link can be saved.
+func (l Link) Validate() error {
+	f := fieldErrors{}
+	f.checkURL("url", l.URL)
+
+	return f.err()
+}
+
+// Validate reports whether the kit can be saved. Its schematic and
+// diagram are optional. Parts with designators may leave their
+// quantity at zero as it is taken from the number of designators.
+func (k Kit) Validate() error {
+	f := fieldErrors{}
+	f.checkName("name", k.Name)
+
+	if k.Schematic != "" {
+		f.checkURL("schematics", k.Schematic)
+	}
+
+	if k.Diagram != "" {
+		f.checkURL("diagram", k.Diagram)
+	}
+
+	for i, l := range k.Links {
+		f.checkURL(fmt.Sprintf("links[%d].url", i), l.URL)
+	}
+
+	for i, p := range k.Parts {
+		if len(p.Designators) == 0 {
+			f.checkQuantity(fmt.Sprintf("parts[%d].quantity", i), p.Quantity)
+		}
+	}
+
+	return f.err()
+}
+
+// ValidateQuantity reports whether quantity can be used for a part in a
+// kit.
+func ValidateQuantity(quantity uint64) error {
+	f := fieldErrors{}
+	f.checkQuantity("quantity", quantity)
+
+	return f.err()
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fieldNames(err error) []string {
+	names := []string{}
+
+	if v, ok := err.(ValidationError); ok {
+		for _, f := range v.Fields {
+			names = append(names, f.Field)
+		}
+	}
+
+	return names
+}
+
+func Test_Part_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		part     Part
+		expected []string
+	}{
+		{"valid part", Part{Name: "4k7", Kind: Resistor}, []string{}},
+		{"empty name", Part{Name: " ", Kind: Resistor}, []string{"name"}},
+		{"long name", Part{Name: strings.Repeat("x", MaxNameLength+1), Kind: IC}, []string{"name"}},
+		{"invalid link", Part{Name: "TL072", Kind: IC, Links: []Link{{URL: "example.com"}, {URL: "nowhere"}}}, []string{"links[1].url"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, fieldNames(test.part.Validate()))
+		})
+	}
+
+	t.Run("should return InvalidPartType for an unknown kind", func(t *testing.T) {
+		err := Part{Name: "", Kind: "Flux Capacitor"}.Validate()
+
+		assert.Equal(t, InvalidPartType{"Flux Capacitor"}, err)
+	})
+}
+
+func Test_Link_Validate(t *testing.T) {
+	tests := []struct {
+		url   string
+		valid bool
+	}{
+		{"example.com", true},
+		{"example.com/kits/ts808.pdf", true},
+		{"https://example.com/kits?id=3", true},
+		{"http://localhost:3000/kits", true},
+		{"", false},
+		{"ts808", false},
+		{"example.com/tube screamer", false},
+		{"ftp://example.com/ts808.pdf", false},
+		{"https://", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			err := Link{URL: test.url}.Validate()
+
+			if test.valid {
+				assert.Nil(t, err)
+			} else {
+				assert.Equal(t, []string{"url"}, fieldNames(err))
+			}
+		})
+	}
+}
+
+func Test_Kit_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		kit      Kit
+		expected []string
+	}{
+		{"name only", Kit{Name: "Tube Screamer"}, []string{}},
+		{"every field", Kit{
+			Name:      "Tube Screamer",
+			Schematic: "example.com/ts.pdf",
+			Diagram:   "example.com/ts-diagram.pdf",
+			Links:     []Link{{URL: "example.com/ts"}},
+			Parts:     []KitPart{{Quantity: 2}, {Designators: []string{"R1"}}},
+		}, []string{}},
+		{"every problem", Kit{
+			Schematic: "schematic",
+			Diagram:   "not a url",
+			Links:     []Link{{URL: "example.com"}, {URL: ""}},
+			Parts:     []KitPart{{Quantity: 1}, {Quantity: 0}},
+		}, []string{"name", "schematics", "diagram", "links[1].url", "parts[1].quantity"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, fieldNames(test.kit.Validate()))
+		})
+	}
+}
+
+func Test_ValidateQuantity(t *testing.T) {
+	assert.Nil(t, ValidateQuantity(1))
+	assert.Equal(t, InvalidField("quantity", "must be greater than zero"), ValidateQuantity(0))
+}
+
+func Test_ValidationError_Error(t *testing.T) {
+	err := ValidationError{Fields: []FieldError{
+		{Field: "name", Message: "must not be empty"},
+		{Field: "url", Message: "must be an http or https URL"},
+	}}
+
+	assert.Equal(t, "Invalid input: name must not be empty; url must be an http or https URL", err.Error())
+}
@@ -0,0 +1,232 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type Unit string
+
+const (
+	Ohms   Unit = "ohm"
+	Farads Unit = "F"
+)
+
+type InvalidValue struct {
+	Kind  PartType
+	Value string
+}
+
+func (v InvalidValue) Error() string {
+	return fmt.Sprintf("Invalid %s value '%s'", v.Kind, v.Value)
+}
+
+// Value is the numeric value of a passive component such as a
+// resistor, capacitor or potentiometer.
+type Value struct {
+	Magnitude float64 `json:"magnitude"`
+	Unit      Unit    `json:"unit"`
+	Taper     string  `json:"taper,omitempty"`
+}
+
+type prefix struct {
+	symbol   string
+	exponent int
+}
+
+var ohmPrefixes = []prefix{
+	{"", 0},
+	{"k", 3},
+	{"M", 6},
+	{"G", 9},
+}
+
+var faradPrefixes = []prefix{
+	{"p", -12},
+	{"n", -9},
+	{"u", -6},
+	{"m", -3},
+	{"", 0},
+}
+
+// String returns the canonical representation of the value, e.g.
+// "4.7k", "1.5nF" or "B100k".
+func (v Value) String() string {
+	prefixes := ohmPrefixes
+	unit := ""
+	if v.Unit == Farads {
+		prefixes = faradPrefixes
+		unit = string(Farads)
+	}
+
+	chosen := prefixes[0]
+	if v.Magnitude != 0 {
+		exp := int(math.Floor(math.Log10(math.Abs(v.Magnitude))))
+		for _, p := range prefixes {
+			if p.exponent <= exp {
+				chosen = p
+			}
+		}
+	}
+
+	mantissa := v.Magnitude / math.Pow10(chosen.exponent)
+
+	return v.Taper + strconv.FormatFloat(mantissa, 'g', 6, 64) + chosen.symbol + unit
+}
+
+// Equal reports whether both values describe the same component value.
+func (v Value) Equal(other Value) bool {
+	return v.String() == other.String()
+}
+
+// HasValue reports whether parts of this kind carry a numeric value.
+func (p PartType) HasValue() bool {
+	switch p {
+	case Resistor, Capacitor, Potentiometer:
+		return true
+	}
+
+	return false
+}
+
+func (p PartType) unit() Unit {
+	if p == Capacitor {
+		return Farads
+	}
+
+	return Ohms
+}
+
+// multiplier returns the power of ten represented by the given
+// suffix letter for the part kind. In resistor notation a lowercase
+// 'm' is commonly used for mega (2.2m), while for capacitors it is milli.
+func (p PartType) multiplier(r rune) (int, bool) {
+	switch r {
+	case 'p', 'P':
+		return -12, p == Capacitor
+	case 'n', 'N':
+		return -9, p == Capacitor
+	case 'u', 'U', 'µ', 'μ':
+		return -6, p == Capacitor
+	case 'm':
+		if p == Capacitor {
+			return -3, true
+		}
+		return 6, true
+	case 'M':
+		return 6, p != Capacitor
+	case 'k', 'K':
+		return 3, p != Capacitor
+	case 'G', 'g':
+		return 9, p != Capacitor
+	case 'r', 'R':
+		return 0, p != Capacitor
+	}
+
+	return 0, false
+}
+
+func (p PartType) isUnitSuffix(s string) bool {
+	s = strings.ToLower(s)
+
+	if p == Capacitor {
+		return s == "" || s == "f"
+	}
+
+	switch s {
+	case "", "r", "ohm", "ohms", "Ω":
+		return true
+	}
+
+	return false
+}
+
+func takeDigits(s []rune) (string, []rune) {
+	i := 0
+	for i < len(s) && unicode.IsDigit(s[i]) {
+		i++
+	}
+
+	return string(s[:i]), s[i:]
+}
+
+// ParseValue parses a component value written in RKM notation (4k7,
+// 470R, 2M2), with SI suffixes (2.2M, 47nf, 1500pf) or with an optional
+// potentiometer taper (B100k). Whitespace is ignored.
+func ParseValue(kind PartType, value string) (Value, error) {
+	invalid := InvalidValue{Kind: kind, Value: value}
+
+	if !kind.HasValue() {
+		return Value{}, invalid
+	}
+
+	s := []rune(strings.Join(strings.Fields(value), ""))
+	v := Value{Unit: kind.unit()}
+
+	if kind == Potentiometer && len(s) > 1 && !unicode.IsDigit(s[0]) && unicode.IsDigit(s[1]) {
+		switch unicode.ToUpper(s[0]) {
+		case 'A', 'B', 'C', 'W':
+			v.Taper = string(unicode.ToUpper(s[0]))
+			s = s[1:]
+		default:
+			return Value{}, invalid
+		}
+	}
+
+	whole, s := takeDigits(s)
+	frac := ""
+	exp := 0
+
+	if len(s) > 0 && (s[0] == '.' || s[0] == ',') {
+		frac, s = takeDigits(s[1:])
+	}
+
+	if whole == "" && frac == "" {
+		return Value{}, invalid
+	}
+
+	if len(s) > 0 {
+		if e, ok := kind.multiplier(s[0]); ok {
+			exp = e
+			s = s[1:]
+
+			// RKM notation places the multiplier where the decimal
+			// point would be, e.g. 4k7.
+			if frac == "" {
+				frac, s = takeDigits(s)
+			}
+		}
+	}
+
+	if !kind.isUnitSuffix(string(s)) {
+		return Value{}, invalid
+	}
+
+	if whole == "" {
+		whole = "0"
+	}
+
+	mag, err := strconv.ParseFloat(fmt.Sprintf("%s.%se%d", whole, frac, exp), 64)
+	if err != nil {
+		return Value{}, invalid
+	}
+
+	v.Magnitude = mag
+
+	return v, nil
+}
+
+// NormalizeValue returns the canonical form of a part's name used to
+// recognize equivalent parts. Values of passive components are parsed
+// ("1.5nf" and "1500pf" are both "1.5nF"); anything else has its
+// whitespace collapsed and is upper-cased.
+func NormalizeValue(kind PartType, name string) string {
+	if v, err := ParseValue(kind, name); err == nil {
+		return v.String()
+	}
+
+	return strings.ToUpper(strings.Join(strings.Fields(name), " "))
+}
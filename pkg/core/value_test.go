@@ -0,0 +1,103 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseValue(t *testing.T) {
+	tests := []struct {
+		kind     PartType
+		input    string
+		expected string
+	}{
+		{Resistor, "4k7", "4.7k"},
+		{Resistor, "4.7k", "4.7k"},
+		{Resistor, "470r", "470"},
+		{Resistor, "4R7", "4.7"},
+		{Resistor, "2.2M", "2.2M"},
+		{Resistor, "2.2 M", "2.2M"},
+		{Resistor, "2M2", "2.2M"},
+		{Resistor, "2.2m", "2.2M"},
+		{Resistor, "1000", "1k"},
+		{Resistor, "10 ohms", "10"},
+		{Capacitor, "47nf", "47nF"},
+		{Capacitor, "1.5nf", "1.5nF"},
+		{Capacitor, "1500pf", "1.5nF"},
+		{Capacitor, "4n7", "4.7nF"},
+		{Capacitor, "4.7uf", "4.7uF"},
+		{Capacitor, "0.1uF", "100nF"},
+		{Capacitor, "47 pF", "47pF"},
+		{Potentiometer, "B100k", "B100k"},
+		{Potentiometer, "a10k", "A10k"},
+		{Potentiometer, "10k", "10k"},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s '%s' should be %s", test.kind, test.input, test.expected), func(t *testing.T) {
+			v, err := ParseValue(test.kind, test.input)
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, v.String())
+		})
+	}
+}
+
+func Test_ParseValue_Errors(t *testing.T) {
+	tests := []struct {
+		kind  PartType
+		input string
+	}{
+		{Resistor, ""},
+		{Resistor, "k"},
+		{Resistor, "4.7nf"},
+		{Capacitor, "4k7"},
+		{Potentiometer, "1k 3362P Trim"},
+		{Potentiometer, "X10k"},
+		{IC, "TL072"},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s '%s' should be invalid", test.kind, test.input), func(t *testing.T) {
+			_, err := ParseValue(test.kind, test.input)
+
+			assert.NotNil(t, err)
+			assert.IsType(t, InvalidValue{}, err)
+			assert.Equal(t, test.input, err.(InvalidValue).Value)
+		})
+	}
+}
+
+func Test_Value_Equal(t *testing.T) {
+	a, err := ParseValue(Capacitor, "1.5nf")
+	assert.Nil(t, err)
+
+	b, err := ParseValue(Capacitor, "1500pf")
+	assert.Nil(t, err)
+
+	assert.True(t, a.Equal(b))
+	assert.Equal(t, a.Magnitude, b.Magnitude)
+	assert.Equal(t, Farads, b.Unit)
+}
+
+func Test_NormalizeValue(t *testing.T) {
+	tests := []struct {
+		kind     PartType
+		input    string
+		expected string
+	}{
+		{Resistor, "4k7", "4.7k"},
+		{Capacitor, "1500pf", "1.5nF"},
+		{IC, "tl072", "TL072"},
+		{Switch, "2P4T  Rotary", "2P4T ROTARY"},
+		{Potentiometer, "1k 3362P Trim", "1K 3362P TRIM"},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s '%s' should be %s", test.kind, test.input, test.expected), func(t *testing.T) {
+			assert.Equal(t, test.expected, NormalizeValue(test.kind, test.input))
+		})
+	}
+}
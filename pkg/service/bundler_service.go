@@ -1,23 +1,49 @@
 package service
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/sombrerosheep/partsbundler/pkg/core"
 )
 
 type IPartService interface {
 	GetAll() ([]core.Part, error)
 	Get(partId int64) (core.Part, error)
+	Find(query core.PartQuery) ([]core.Part, error)
+	List(query core.PartQuery, page core.PageRequest) (core.PartPage, error)
 
 	AddLink(partId int64, link string) (core.Link, error)
 	RemoveLink(partId int64, linkId int64) error
 
 	New(name string, kind core.PartType) (core.Part, error)
+	Update(partId int64, name string, kind core.PartType) (core.Part, error)
+	Patch(partId int64, patch core.PartPatch) (core.Part, error)
 	Delete(partId int64) error
+
+	FindDuplicates() ([][]core.Part, error)
+	Merge(partId int64, duplicateId int64) error
+
+	// The Context variants behave like the methods above but stop work
+	// and return an error once ctx is done.
+	GetAllContext(ctx context.Context) ([]core.Part, error)
+	GetContext(ctx context.Context, partId int64) (core.Part, error)
+	FindContext(ctx context.Context, query core.PartQuery) ([]core.Part, error)
+	ListContext(ctx context.Context, query core.PartQuery, page core.PageRequest) (core.PartPage, error)
+	AddLinkContext(ctx context.Context, partId int64, link string) (core.Link, error)
+	RemoveLinkContext(ctx context.Context, partId int64, linkId int64) error
+	NewContext(ctx context.Context, name string, kind core.PartType) (core.Part, error)
+	UpdateContext(ctx context.Context, partId int64, name string, kind core.PartType) (core.Part, error)
+	PatchContext(ctx context.Context, partId int64, patch core.PartPatch) (core.Part, error)
+	DeleteContext(ctx context.Context, partId int64) error
+	FindDuplicatesContext(ctx context.Context) ([][]core.Part, error)
+	MergeContext(ctx context.Context, partId int64, duplicateId int64) error
 }
 
 type IKitService interface {
 	GetAll() ([]core.Kit, error)
 	Get(kitId int64) (core.Kit, error)
+	List(page core.PageRequest) (core.KitPage, error)
 
 	AddLink(kitId int64, link string) (core.Link, error)
 	RemoveLink(kitId int64, linkId int64) error
@@ -25,13 +51,104 @@ type IKitService interface {
 	AddPart(kitId int64, partId int64, quantity uint64) error
 	GetPartUsage(partId int64) ([]int64, error)
 	SetPartQuantity(kitId int64, partId int64, quantity uint64) error
+	SetPartDesignators(kitId int64, partId int64, designators []string) error
 	RemovePart(kitId int64, partId int64) error
 
 	New(name string, schematic string, diagram string) (core.Kit, error)
+	Update(kitId int64, name string, schematic string, diagram string) (core.Kit, error)
+	Patch(kitId int64, patch core.KitPatch) (core.Kit, error)
 	Delete(kitId int64) error
+
+	Plan(builds []core.KitBuild, onHand map[int64]uint64) (core.Plan, error)
+	Import(spec core.KitSpec) (core.KitImport, error)
+
+	// The Context variants behave like the methods above but stop work
+	// and return an error once ctx is done.
+	GetAllContext(ctx context.Context) ([]core.Kit, error)
+	GetContext(ctx context.Context, kitId int64) (core.Kit, error)
+	ListContext(ctx context.Context, page core.PageRequest) (core.KitPage, error)
+	AddLinkContext(ctx context.Context, kitId int64, link string) (core.Link, error)
+	RemoveLinkContext(ctx context.Context, kitId int64, linkId int64) error
+	AddPartContext(ctx context.Context, kitId int64, partId int64, quantity uint64) error
+	GetPartUsageContext(ctx context.Context, partId int64) ([]int64, error)
+	SetPartQuantityContext(ctx context.Context, kitId int64, partId int64, quantity uint64) error
+	SetPartDesignatorsContext(ctx context.Context, kitId int64, partId int64, designators []string) error
+	RemovePartContext(ctx context.Context, kitId int64, partId int64) error
+	NewContext(ctx context.Context, name string, schematic string, diagram string) (core.Kit, error)
+	UpdateContext(ctx context.Context, kitId int64, name string, schematic string, diagram string) (core.Kit, error)
+	PatchContext(ctx context.Context, kitId int64, patch core.KitPatch) (core.Kit, error)
+	DeleteContext(ctx context.Context, kitId int64) error
+	PlanContext(ctx context.Context, builds []core.KitBuild, onHand map[int64]uint64) (core.Plan, error)
+	ImportContext(ctx context.Context, spec core.KitSpec) (core.KitImport, error)
+}
+
+type IInventoryService interface {
+	GetAll() ([]core.Stock, error)
+	Get(partId int64) (core.Stock, error)
+
+	Adjust(partId int64, kind core.AdjustmentKind, quantity uint64, note string) (core.Stock, error)
+	SetLocation(partId int64, location string) (core.Stock, error)
+	GetHistory(partId int64) ([]core.StockAdjustment, error)
+}
+
+// ITransactor runs a function as a single unit of work. Every change
+// made through the BundlerService passed to fn is committed when fn
+// returns nil and rolled back when it returns an error.
+type ITransactor interface {
+	Atomic(fn func(svc *BundlerService) error) error
+}
+
+// AtomicUnsupported is returned by a Transactor which cannot run a unit
+// of work atomically. fn is not called.
+type AtomicUnsupported struct {
+	Reason string
+}
+
+func (e AtomicUnsupported) Error() string {
+	return fmt.Sprintf("Atomic updates are not supported: %s", e.Reason)
+}
+
+// IStorage is the store behind a BundlerService.
+type IStorage interface {
+	// Ping reports whether the store can serve requests.
+	Ping(ctx context.Context) error
+	// Close releases the store. The service must not be used afterwards.
+	Close() error
 }
 
 type BundlerService struct {
-	Parts IPartService
-	Kits  IKitService
+	Parts      IPartService
+	Kits       IKitService
+	Inventory  IInventoryService
+	Transactor ITransactor
+	Storage    IStorage
+}
+
+// Atomic runs fn as a unit of work using the service's Transactor. When
+// the service has no Transactor fn runs directly against the service.
+func (s *BundlerService) Atomic(fn func(svc *BundlerService) error) error {
+	if s.Transactor == nil {
+		return fn(s)
+	}
+
+	return s.Transactor.Atomic(fn)
+}
+
+// Ping checks the service's Storage. A service without Storage is
+// always ready.
+func (s *BundlerService) Ping(ctx context.Context) error {
+	if s.Storage == nil {
+		return nil
+	}
+
+	return s.Storage.Ping(ctx)
+}
+
+// Close closes the service's Storage, if it has one.
+func (s *BundlerService) Close() error {
+	if s.Storage == nil {
+		return nil
+	}
+
+	return s.Storage.Close()
 }
@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/sombrerosheep/partsbundler/pkg/service"
+	"github.com/sombrerosheep/partsbundler/pkg/service/servicetest"
+)
+
+func Test_MemoryConformance(t *testing.T) {
+	servicetest.Run(t, func(t *testing.T) *service.BundlerService {
+		return CreateMemoryService()
+	})
+}
@@ -0,0 +1,301 @@
+// Package memory implements the bundler services in memory. It follows
+// the same rules and returns the same errors as the sqlite services but
+// keeps nothing once the process exits, which suits tests and throwaway
+// sessions.
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+	"github.com/sombrerosheep/partsbundler/pkg/service"
+)
+
+// kitPart is a part used by a kit. Its id orders the parts of a kit.
+type kitPart struct {
+	id          int64
+	partId      int64
+	quantity    uint64
+	designators []string
+}
+
+// data holds every record of a store. Parts and kits are kept without
+// their links and parts, which live in their own maps.
+type data struct {
+	parts       map[int64]core.Part
+	partLinks   map[int64][]core.Link
+	kits        map[int64]core.Kit
+	kitParts    map[int64][]kitPart
+	kitLinks    map[int64][]core.Link
+	stock       map[int64]core.Stock
+	adjustments []core.StockAdjustment
+
+	lastPartId       int64
+	lastPartLinkId   int64
+	lastKitId        int64
+	lastKitLinkId    int64
+	lastKitPartId    int64
+	lastAdjustmentId int64
+}
+
+func newData() *data {
+	return &data{
+		parts:       map[int64]core.Part{},
+		partLinks:   map[int64][]core.Link{},
+		kits:        map[int64]core.Kit{},
+		kitParts:    map[int64][]kitPart{},
+		kitLinks:    map[int64][]core.Link{},
+		stock:       map[int64]core.Stock{},
+		adjustments: []core.StockAdjustment{},
+	}
+}
+
+// clone returns a deep copy of d which can be changed without changing d.
+func (d *data) clone() *data {
+	c := *d
+
+	c.parts = make(map[int64]core.Part, len(d.parts))
+	for id, part := range d.parts {
+		c.parts[id] = part
+	}
+
+	c.partLinks = cloneLinks(d.partLinks)
+	c.kitLinks = cloneLinks(d.kitLinks)
+
+	c.kits = make(map[int64]core.Kit, len(d.kits))
+	for id, kit := range d.kits {
+		c.kits[id] = kit
+	}
+
+	c.kitParts = make(map[int64][]kitPart, len(d.kitParts))
+	for id, kps := range d.kitParts {
+		list := make([]kitPart, len(kps))
+		for i, kp := range kps {
+			kp.designators = copyStrings(kp.designators)
+			list[i] = kp
+		}
+		c.kitParts[id] = list
+	}
+
+	c.stock = make(map[int64]core.Stock, len(d.stock))
+	for id, s := range d.stock {
+		c.stock[id] = s
+	}
+
+	c.adjustments = append([]core.StockAdjustment{}, d.adjustments...)
+
+	return &c
+}
+
+func cloneLinks(links map[int64][]core.Link) map[int64][]core.Link {
+	c := make(map[int64][]core.Link, len(links))
+	for id, l := range links {
+		c[id] = append([]core.Link{}, l...)
+	}
+
+	return c
+}
+
+// copyStrings returns a copy of s, or nil when s is empty.
+func copyStrings(s []string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+
+	return append([]string{}, s...)
+}
+
+func sortIds(ids []int64) []int64 {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
+func (d *data) partIds() []int64 {
+	ids := make([]int64, 0, len(d.parts))
+	for id := range d.parts {
+		ids = append(ids, id)
+	}
+
+	return sortIds(ids)
+}
+
+func (d *data) kitIds() []int64 {
+	ids := make([]int64, 0, len(d.kits))
+	for id := range d.kits {
+		ids = append(ids, id)
+	}
+
+	return sortIds(ids)
+}
+
+// part returns the part with its links.
+func (d *data) part(partId int64) (core.Part, error) {
+	part, ok := d.parts[partId]
+	if !ok {
+		return core.Part{}, core.PartNotFound{PartID: partId}
+	}
+
+	part.Links = append([]core.Link{}, d.partLinks[partId]...)
+
+	return part, nil
+}
+
+// partUsage returns the ids of the kits using the part in the order the
+// part was added to them.
+func (d *data) partUsage(partId int64) []int64 {
+	type usage struct {
+		kitId, kitPartId int64
+	}
+
+	uses := []usage{}
+	for kitId, kps := range d.kitParts {
+		for _, kp := range kps {
+			if kp.partId == partId {
+				uses = append(uses, usage{kitId, kp.id})
+			}
+		}
+	}
+
+	sort.Slice(uses, func(i, j int) bool { return uses[i].kitPartId < uses[j].kitPartId })
+
+	kitIds := make([]int64, len(uses))
+	for i, u := range uses {
+		kitIds[i] = u.kitId
+	}
+
+	return kitIds
+}
+
+// kit returns the kit with its parts and links.
+func (d *data) kit(kitId int64) (core.Kit, error) {
+	kit, ok := d.kits[kitId]
+	if !ok {
+		return core.Kit{}, core.KitNotFound{KitID: kitId}
+	}
+
+	kit.Parts = make([]core.KitPart, len(d.kitParts[kitId]))
+	for i, kp := range d.kitParts[kitId] {
+		part, _ := d.part(kp.partId)
+
+		kit.Parts[i] = core.KitPart{
+			Part:        part,
+			Quantity:    kp.quantity,
+			Designators: copyStrings(kp.designators),
+		}
+	}
+
+	kit.Links = append([]core.Link{}, d.kitLinks[kitId]...)
+
+	return kit, nil
+}
+
+// findKitPart returns the index of the part within the kit's parts, or
+// -1 when the kit does not use it.
+func (d *data) findKitPart(kitId, partId int64) int {
+	for i, kp := range d.kitParts[kitId] {
+		if kp.partId == partId {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// removeLink removes the link from links, reporting whether it was there.
+func removeLink(links []core.Link, linkId int64) ([]core.Link, bool) {
+	for i, l := range links {
+		if l.ID == linkId {
+			return append(links[:i], links[i+1:]...), true
+		}
+	}
+
+	return links, false
+}
+
+// store guards the data shared by the services of a BundlerService.
+// Writes change the data in place, so a write must check everything
+// which can fail before it changes anything. Only a transaction works
+// on a copy of the data, which replaces it when the transaction
+// succeeds. A store without a mutex belongs to a transaction and is
+// changed in place.
+type store struct {
+	mu   *sync.RWMutex
+	data *data
+}
+
+func (s *store) read(fn func(d *data) error) error {
+	if s.mu != nil {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+	}
+
+	return fn(s.data)
+}
+
+func (s *store) write(fn func(d *data) error) error {
+	if s.mu != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+
+	return fn(s.data)
+}
+
+// atomic runs fn against a copy of the data which replaces it when fn
+// returns nil. Within a transaction fn joins the outer unit of work.
+func (s *store) atomic(fn func(d *data) error) error {
+	if s.mu == nil {
+		return fn(s.data)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d := s.data.clone()
+
+	err := fn(d)
+	if err != nil {
+		return err
+	}
+
+	s.data = d
+
+	return nil
+}
+
+// CreateMemoryService returns an empty BundlerService kept in memory.
+// The service is safe for concurrent use.
+func CreateMemoryService() *service.BundlerService {
+	return newMemoryService(&store{
+		mu:   &sync.RWMutex{},
+		data: newData(),
+	})
+}
+
+func newMemoryService(stor *store) *service.BundlerService {
+	parts := MemoryPartService{
+		store: stor,
+	}
+	kits := MemoryKitService{
+		store: stor,
+	}
+	inventory := MemoryInventoryService{
+		store: stor,
+	}
+	transactor := MemoryTransactor{
+		store: stor,
+	}
+	storage := MemoryStorage{}
+
+	svc := &service.BundlerService{
+		Parts:      parts,
+		Kits:       kits,
+		Inventory:  inventory,
+		Transactor: transactor,
+		Storage:    storage,
+	}
+
+	return svc
+}
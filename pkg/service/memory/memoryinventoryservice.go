@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+)
+
+type MemoryInventoryService struct {
+	store *store
+}
+
+func (service MemoryInventoryService) GetAll() ([]core.Stock, error) {
+	stock := []core.Stock{}
+
+	err := service.store.read(func(d *data) error {
+		for _, id := range d.partIds() {
+			if s, ok := d.stock[id]; ok {
+				stock = append(stock, s)
+			}
+		}
+
+		return nil
+	})
+
+	return stock, err
+}
+
+// partStock returns the part's stock, which is empty for parts that have
+// never been stocked.
+func (d *data) partStock(partId int64) (core.Stock, error) {
+	if _, ok := d.parts[partId]; !ok {
+		return core.Stock{}, core.PartNotFound{PartID: partId}
+	}
+
+	stock, ok := d.stock[partId]
+	if !ok {
+		stock = core.Stock{PartID: partId}
+	}
+
+	return stock, nil
+}
+
+func (service MemoryInventoryService) Get(partId int64) (core.Stock, error) {
+	var stock core.Stock
+
+	err := service.store.read(func(d *data) (err error) {
+		stock, err = d.partStock(partId)
+
+		return err
+	})
+	if err != nil {
+		return core.Stock{}, err
+	}
+
+	return stock, nil
+}
+
+func (service MemoryInventoryService) Adjust(partId int64, kind core.AdjustmentKind, quantity uint64, note string) (core.Stock, error) {
+	var stock core.Stock
+
+	err := service.store.write(func(d *data) error {
+		current, err := d.partStock(partId)
+		if err != nil {
+			return err
+		}
+
+		stock, err = current.Apply(kind, quantity)
+		if err != nil {
+			return err
+		}
+
+		d.stock[partId] = stock
+
+		d.lastAdjustmentId++
+		d.adjustments = append(d.adjustments, core.StockAdjustment{
+			ID:        d.lastAdjustmentId,
+			PartID:    partId,
+			Kind:      kind,
+			Quantity:  quantity,
+			Note:      note,
+			CreatedAt: time.Now().UTC(),
+		})
+
+		return nil
+	})
+	if err != nil {
+		return core.Stock{}, err
+	}
+
+	return stock, nil
+}
+
+func (service MemoryInventoryService) SetLocation(partId int64, location string) (core.Stock, error) {
+	var stock core.Stock
+
+	err := service.store.write(func(d *data) (err error) {
+		stock, err = d.partStock(partId)
+		if err != nil {
+			return err
+		}
+
+		stock.Location = location
+		d.stock[partId] = stock
+
+		return nil
+	})
+	if err != nil {
+		return core.Stock{}, err
+	}
+
+	return stock, nil
+}
+
+func (service MemoryInventoryService) GetHistory(partId int64) ([]core.StockAdjustment, error) {
+	adjustments := []core.StockAdjustment{}
+
+	err := service.store.read(func(d *data) error {
+		if _, ok := d.parts[partId]; !ok {
+			return core.PartNotFound{PartID: partId}
+		}
+
+		for _, a := range d.adjustments {
+			if a.PartID == partId {
+				adjustments = append(adjustments, a)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return adjustments, nil
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_memoryinventoryservice_Adjust(t *testing.T) {
+	t.Run("should track stock and its history", func(t *testing.T) {
+		svc := CreateMemoryService()
+		part, _ := svc.Parts.New("1k", core.Resistor)
+
+		stock, err := svc.Inventory.Get(part.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, core.Stock{PartID: part.ID}, stock)
+
+		svc.Inventory.SetLocation(part.ID, "drawer A1")
+
+		stock, err = svc.Inventory.Adjust(part.ID, core.Received, 10, "order 12")
+		assert.Nil(t, err)
+		assert.Equal(t, core.Stock{PartID: part.ID, Quantity: 10, Location: "drawer A1"}, stock)
+
+		_, err = svc.Inventory.Adjust(part.ID, core.Consumed, 11, "")
+		assert.Equal(t, core.InsufficientStock{PartID: part.ID, Available: 10, Requested: 11}, err)
+
+		stock, err = svc.Inventory.Adjust(part.ID, core.Consumed, 4, "")
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(6), stock.Quantity)
+
+		history, err := svc.Inventory.GetHistory(part.ID)
+		assert.Nil(t, err)
+		if assert.Len(t, history, 2) {
+			assert.Equal(t, core.StockAdjustment{ID: 1, PartID: part.ID, Kind: core.Received, Quantity: 10, Note: "order 12", CreatedAt: history[0].CreatedAt}, history[0])
+			assert.Equal(t, int64(2), history[1].ID)
+		}
+
+		all, err := svc.Inventory.GetAll()
+		assert.Nil(t, err)
+		assert.Equal(t, []core.Stock{stock}, all)
+	})
+
+	t.Run("should return PartNotFound for a missing part", func(t *testing.T) {
+		sut := CreateMemoryService().Inventory
+
+		_, err := sut.Get(42)
+		assert.Equal(t, core.PartNotFound{PartID: 42}, err)
+
+		_, err = sut.Adjust(42, core.Received, 1, "")
+		assert.Equal(t, core.PartNotFound{PartID: 42}, err)
+
+		_, err = sut.SetLocation(42, "drawer A1")
+		assert.Equal(t, core.PartNotFound{PartID: 42}, err)
+
+		_, err = sut.GetHistory(42)
+		assert.Equal(t, core.PartNotFound{PartID: 42}, err)
+	})
+
+	t.Run("should drop the stock of deleted parts", func(t *testing.T) {
+		svc := CreateMemoryService()
+		part, _ := svc.Parts.New("1k", core.Resistor)
+		svc.Inventory.Adjust(part.ID, core.Received, 10, "")
+
+		svc.Parts.Delete(part.ID)
+
+		all, err := svc.Inventory.GetAll()
+		assert.Nil(t, err)
+		assert.Empty(t, all)
+	})
+}
@@ -0,0 +1,628 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+)
+
+type MemoryKitService struct {
+	store *store
+}
+
+func (service MemoryKitService) GetAll() ([]core.Kit, error) {
+	kits := []core.Kit{}
+
+	err := service.store.read(func(d *data) error {
+		for _, id := range d.kitIds() {
+			kit, _ := d.kit(id)
+			kits = append(kits, kit)
+		}
+
+		return nil
+	})
+
+	return kits, err
+}
+
+func (service MemoryKitService) List(page core.PageRequest) (core.KitPage, error) {
+	err := page.Validate(core.KitSortKeys)
+	if err != nil {
+		return core.KitPage{}, err
+	}
+
+	kits, err := service.GetAll()
+	if err != nil {
+		return core.KitPage{}, err
+	}
+
+	core.SortKits(kits, page.Sort, page.Desc)
+	start, end := page.Bounds(len(kits))
+
+	return core.KitPage{
+		Kits:   kits[start:end],
+		Total:  len(kits),
+		Offset: page.Offset,
+		Limit:  page.Limit,
+	}, nil
+}
+
+func (service MemoryKitService) Get(kitId int64) (core.Kit, error) {
+	var kit core.Kit
+
+	err := service.store.read(func(d *data) (err error) {
+		kit, err = d.kit(kitId)
+
+		return err
+	})
+	if err != nil {
+		return core.Kit{}, err
+	}
+
+	return kit, nil
+}
+
+func (service MemoryKitService) AddLink(kitId int64, link string) (core.Link, error) {
+	l := core.Link{
+		URL: link,
+	}
+
+	err := l.Validate()
+	if err != nil {
+		return core.Link{}, err
+	}
+
+	err = service.store.write(func(d *data) error {
+		if _, ok := d.kits[kitId]; !ok {
+			return core.KitNotFound{KitID: kitId}
+		}
+
+		d.lastKitLinkId++
+		l.ID = d.lastKitLinkId
+		d.kitLinks[kitId] = append(d.kitLinks[kitId], l)
+
+		return nil
+	})
+	if err != nil {
+		return core.Link{}, err
+	}
+
+	return l, nil
+}
+
+func (service MemoryKitService) RemoveLink(kitId int64, linkId int64) error {
+	return service.store.write(func(d *data) error {
+		if _, ok := d.kits[kitId]; !ok {
+			return core.KitNotFound{KitID: kitId}
+		}
+
+		links, ok := removeLink(d.kitLinks[kitId], linkId)
+		if !ok {
+			return core.KitLinkNotFound{LinkID: linkId, KitID: kitId}
+		}
+
+		d.kitLinks[kitId] = links
+
+		return nil
+	})
+}
+
+func (service MemoryKitService) AddPart(kitId, partId int64, quantity uint64) error {
+	err := core.ValidateQuantity(quantity)
+	if err != nil {
+		return err
+	}
+
+	return service.store.write(func(d *data) error {
+		if _, ok := d.kits[kitId]; !ok {
+			return core.KitNotFound{KitID: kitId}
+		}
+
+		if _, ok := d.parts[partId]; !ok {
+			return core.PartNotFound{PartID: partId}
+		}
+
+		if d.findKitPart(kitId, partId) != -1 {
+			return core.PartAlreadyInKit{KitID: kitId, PartID: partId}
+		}
+
+		d.addKitPart(kitId, partId, quantity, nil)
+
+		return nil
+	})
+}
+
+func (d *data) addKitPart(kitId, partId int64, quantity uint64, designators []string) {
+	d.lastKitPartId++
+
+	d.kitParts[kitId] = append(d.kitParts[kitId], kitPart{
+		id:          d.lastKitPartId,
+		partId:      partId,
+		quantity:    quantity,
+		designators: copyStrings(designators),
+	})
+}
+
+func (service MemoryKitService) GetPartUsage(partId int64) ([]int64, error) {
+	var kitIds []int64
+
+	err := service.store.read(func(d *data) error {
+		if _, ok := d.parts[partId]; !ok {
+			return core.PartNotFound{PartID: partId}
+		}
+
+		kitIds = d.partUsage(partId)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return kitIds, nil
+}
+
+// SetPartQuantity sets the quantity of a part in a kit. A part with
+// designators must keep one designator for each part. Nothing changes
+// when the kit does not use the part.
+func (service MemoryKitService) SetPartQuantity(kitId int64, partId int64, quantity uint64) error {
+	err := core.ValidateQuantity(quantity)
+	if err != nil {
+		return err
+	}
+
+	return service.store.write(func(d *data) error {
+		if _, ok := d.parts[partId]; !ok {
+			return core.PartNotFound{PartID: partId}
+		}
+
+		if _, ok := d.kits[kitId]; !ok {
+			return core.KitNotFound{KitID: kitId}
+		}
+
+		i := d.findKitPart(kitId, partId)
+		if i == -1 {
+			return nil
+		}
+
+		kp := &d.kitParts[kitId][i]
+
+		designators := len(kp.designators)
+		if designators > 0 && uint64(designators) != quantity {
+			return core.DesignatorMismatch{
+				KitID:       kitId,
+				PartID:      partId,
+				Designators: designators,
+				Quantity:    quantity,
+			}
+		}
+
+		kp.quantity = quantity
+
+		return nil
+	})
+}
+
+// SetPartDesignators replaces the designators of a part in a kit and
+// sets its quantity to match. An empty list removes the designators and
+// leaves the quantity as it was.
+func (service MemoryKitService) SetPartDesignators(kitId, partId int64, designators []string) error {
+	designators = core.NormalizeDesignators(designators)
+
+	return service.store.write(func(d *data) error {
+		if _, ok := d.kits[kitId]; !ok {
+			return core.KitNotFound{KitID: kitId}
+		}
+
+		if _, ok := d.parts[partId]; !ok {
+			return core.PartNotFound{PartID: partId}
+		}
+
+		i := d.findKitPart(kitId, partId)
+		if i == -1 {
+			return core.PartNotInKit{KitID: kitId, PartID: partId}
+		}
+
+		kps := d.kitParts[kitId]
+
+		for _, designator := range designators {
+			for j, kp := range kps {
+				if j == i {
+					continue
+				}
+
+				for _, used := range kp.designators {
+					if used == designator {
+						return core.DesignatorInUse{KitID: kitId, Designator: designator}
+					}
+				}
+			}
+		}
+
+		kps[i].designators = copyStrings(designators)
+		if len(designators) > 0 {
+			kps[i].quantity = uint64(len(designators))
+		}
+
+		return nil
+	})
+}
+
+func (service MemoryKitService) RemovePart(kitId, partId int64) error {
+	return service.store.write(func(d *data) error {
+		if _, ok := d.kits[kitId]; !ok {
+			return core.KitNotFound{KitID: kitId}
+		}
+
+		i := d.findKitPart(kitId, partId)
+		if i == -1 {
+			return core.PartNotInKit{KitID: kitId, PartID: partId}
+		}
+
+		kps := d.kitParts[kitId]
+		d.kitParts[kitId] = append(kps[:i], kps[i+1:]...)
+
+		return nil
+	})
+}
+
+func (service MemoryKitService) New(name string, schematic string, diagram string) (core.Kit, error) {
+	kit := core.Kit{
+		ID:        0,
+		Parts:     []core.KitPart{},
+		Name:      name,
+		Schematic: schematic,
+		Diagram:   diagram,
+		Links:     []core.Link{},
+	}
+
+	err := kit.Validate()
+	if err != nil {
+		return kit, err
+	}
+
+	err = service.store.write(func(d *data) error {
+		kit.ID = d.createKit(name, schematic, diagram)
+
+		return nil
+	})
+
+	return kit, err
+}
+
+func (d *data) createKit(name, schematic, diagram string) int64 {
+	d.lastKitId++
+
+	d.kits[d.lastKitId] = core.Kit{
+		ID:        d.lastKitId,
+		Name:      name,
+		Schematic: schematic,
+		Diagram:   diagram,
+	}
+
+	return d.lastKitId
+}
+
+// updateKit sets the kit's fields after validating them.
+func (d *data) updateKit(kitId int64, name, schematic, diagram string) error {
+	err := core.Kit{Name: name, Schematic: schematic, Diagram: diagram}.Validate()
+	if err != nil {
+		return err
+	}
+
+	kit, ok := d.kits[kitId]
+	if !ok {
+		return core.KitNotFound{KitID: kitId}
+	}
+
+	kit.Name = name
+	kit.Schematic = schematic
+	kit.Diagram = diagram
+	d.kits[kitId] = kit
+
+	return nil
+}
+
+func (service MemoryKitService) Update(kitId int64, name, schematic, diagram string) (core.Kit, error) {
+	var kit core.Kit
+
+	err := service.store.write(func(d *data) error {
+		err := d.updateKit(kitId, name, schematic, diagram)
+		if err != nil {
+			return err
+		}
+
+		kit, err = d.kit(kitId)
+
+		return err
+	})
+	if err != nil {
+		return core.Kit{}, err
+	}
+
+	return kit, nil
+}
+
+func (service MemoryKitService) Patch(kitId int64, patch core.KitPatch) (core.Kit, error) {
+	var kit core.Kit
+
+	err := service.store.write(func(d *data) error {
+		current, ok := d.kits[kitId]
+		if !ok {
+			return core.KitNotFound{KitID: kitId}
+		}
+
+		patched := patch.Apply(current)
+
+		err := d.updateKit(kitId, patched.Name, patched.Schematic, patched.Diagram)
+		if err != nil {
+			return err
+		}
+
+		kit, err = d.kit(kitId)
+
+		return err
+	})
+	if err != nil {
+		return core.Kit{}, err
+	}
+
+	return kit, nil
+}
+
+// Delete removes the kit along with its parts and links. Deleting a kit
+// which does not exist is not an error.
+func (service MemoryKitService) Delete(kitId int64) error {
+	return service.store.write(func(d *data) error {
+		delete(d.kits, kitId)
+		delete(d.kitParts, kitId)
+		delete(d.kitLinks, kitId)
+
+		return nil
+	})
+}
+
+func (service MemoryKitService) Plan(builds []core.KitBuild, onHand map[int64]uint64) (core.Plan, error) {
+	kits := map[int64]core.Kit{}
+
+	err := service.store.read(func(d *data) error {
+		for _, build := range builds {
+			if _, ok := kits[build.KitID]; ok {
+				continue
+			}
+
+			kit, err := d.kit(build.KitID)
+			if err != nil {
+				return err
+			}
+
+			kits[kit.ID] = kit
+		}
+
+		return nil
+	})
+	if err != nil {
+		return core.Plan{}, err
+	}
+
+	return core.NewPlan(builds, kits, onHand)
+}
+
+// Import creates the kit described by spec along with any parts that do
+// not already exist. Parts are matched on kind and normalized value.
+func (service MemoryKitService) Import(spec core.KitSpec) (core.KitImport, error) {
+	err := spec.Validate()
+	if err != nil {
+		return core.KitImport{}, err
+	}
+
+	result := core.KitImport{
+		Matched: []core.Part{},
+		Created: []core.Part{},
+	}
+
+	err = service.store.write(func(d *data) error {
+		kitId := d.createKit(spec.Name, spec.Schematic, spec.Diagram)
+
+		for _, link := range spec.Links {
+			d.lastKitLinkId++
+			d.kitLinks[kitId] = append(d.kitLinks[kitId], core.Link{ID: d.lastKitLinkId, URL: link})
+		}
+
+		matched := []int64{}
+		created := []int64{}
+
+		for _, p := range spec.Merged() {
+			partId := d.findPart(p.Kind, p.Value())
+
+			if partId != 0 {
+				matched = append(matched, partId)
+			} else {
+				d.lastPartId++
+				partId = d.lastPartId
+				d.parts[partId] = core.Part{ID: partId, Name: p.Name, Kind: p.Kind, Value: p.Value()}
+
+				created = append(created, partId)
+			}
+
+			for _, link := range p.Links {
+				if !hasLink(d.partLinks[partId], link) {
+					d.lastPartLinkId++
+					d.partLinks[partId] = append(d.partLinks[partId], core.Link{ID: d.lastPartLinkId, URL: link})
+				}
+			}
+
+			d.addKitPart(kitId, partId, p.Quantity, p.Designators)
+		}
+
+		result.Kit, _ = d.kit(kitId)
+
+		for _, id := range matched {
+			part, _ := d.part(id)
+			result.Matched = append(result.Matched, part)
+		}
+
+		for _, id := range created {
+			part, _ := d.part(id)
+			result.Created = append(result.Created, part)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return core.KitImport{}, err
+	}
+
+	return result, nil
+}
+
+// findPart returns the id of the first part of kind with the normalized
+// value, or 0 when there is none.
+func (d *data) findPart(kind core.PartType, value string) int64 {
+	for _, id := range d.partIds() {
+		part := d.parts[id]
+		if part.Kind == kind && part.Value == value {
+			return id
+		}
+	}
+
+	return 0
+}
+
+func hasLink(links []core.Link, url string) bool {
+	for _, l := range links {
+		if l.URL == url {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (service MemoryKitService) GetAllContext(ctx context.Context) ([]core.Kit, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return service.GetAll()
+}
+
+func (service MemoryKitService) ListContext(ctx context.Context, page core.PageRequest) (core.KitPage, error) {
+	if err := ctx.Err(); err != nil {
+		return core.KitPage{}, err
+	}
+
+	return service.List(page)
+}
+
+func (service MemoryKitService) GetContext(ctx context.Context, kitId int64) (core.Kit, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Kit{}, err
+	}
+
+	return service.Get(kitId)
+}
+
+func (service MemoryKitService) AddLinkContext(ctx context.Context, kitId int64, link string) (core.Link, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Link{}, err
+	}
+
+	return service.AddLink(kitId, link)
+}
+
+func (service MemoryKitService) RemoveLinkContext(ctx context.Context, kitId int64, linkId int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return service.RemoveLink(kitId, linkId)
+}
+
+func (service MemoryKitService) AddPartContext(ctx context.Context, kitId, partId int64, quantity uint64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return service.AddPart(kitId, partId, quantity)
+}
+
+func (service MemoryKitService) GetPartUsageContext(ctx context.Context, partId int64) ([]int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return service.GetPartUsage(partId)
+}
+
+func (service MemoryKitService) SetPartQuantityContext(ctx context.Context, kitId int64, partId int64, quantity uint64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return service.SetPartQuantity(kitId, partId, quantity)
+}
+
+func (service MemoryKitService) SetPartDesignatorsContext(ctx context.Context, kitId, partId int64, designators []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return service.SetPartDesignators(kitId, partId, designators)
+}
+
+func (service MemoryKitService) RemovePartContext(ctx context.Context, kitId, partId int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return service.RemovePart(kitId, partId)
+}
+
+func (service MemoryKitService) NewContext(ctx context.Context, name string, schematic string, diagram string) (core.Kit, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Kit{}, err
+	}
+
+	return service.New(name, schematic, diagram)
+}
+
+func (service MemoryKitService) UpdateContext(ctx context.Context, kitId int64, name, schematic, diagram string) (core.Kit, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Kit{}, err
+	}
+
+	return service.Update(kitId, name, schematic, diagram)
+}
+
+func (service MemoryKitService) PatchContext(ctx context.Context, kitId int64, patch core.KitPatch) (core.Kit, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Kit{}, err
+	}
+
+	return service.Patch(kitId, patch)
+}
+
+func (service MemoryKitService) DeleteContext(ctx context.Context, kitId int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return service.Delete(kitId)
+}
+
+func (service MemoryKitService) PlanContext(ctx context.Context, builds []core.KitBuild, onHand map[int64]uint64) (core.Plan, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Plan{}, err
+	}
+
+	return service.Plan(builds, onHand)
+}
+
+func (service MemoryKitService) ImportContext(ctx context.Context, spec core.KitSpec) (core.KitImport, error) {
+	if err := ctx.Err(); err != nil {
+		return core.KitImport{}, err
+	}
+
+	return service.Import(spec)
+}
@@ -0,0 +1,238 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_memorykitservice_New(t *testing.T) {
+	t.Run("should create, update and delete kits", func(t *testing.T) {
+		sut := CreateMemoryService().Kits
+
+		kit, err := sut.New("Fuzz", "example.com/fuzz", "")
+		assert.Nil(t, err)
+		assert.Equal(t, core.Kit{ID: 1, Name: "Fuzz", Schematic: "example.com/fuzz", Parts: []core.KitPart{}, Links: []core.Link{}}, kit)
+
+		kit, err = sut.Update(kit.ID, "Big Fuzz", "example.com/fuzz", "example.com/diagram")
+		assert.Nil(t, err)
+		assert.Equal(t, "example.com/diagram", kit.Diagram)
+
+		name := "Bigger Fuzz"
+		kit, err = sut.Patch(kit.ID, core.KitPatch{Name: &name})
+		assert.Nil(t, err)
+		assert.Equal(t, "Bigger Fuzz", kit.Name)
+
+		err = sut.Delete(kit.ID)
+		assert.Nil(t, err)
+
+		_, err = sut.Get(kit.ID)
+		assert.Equal(t, core.KitNotFound{KitID: kit.ID}, err)
+
+		err = sut.Delete(kit.ID)
+		assert.Nil(t, err)
+	})
+
+	t.Run("should reject invalid kits", func(t *testing.T) {
+		sut := CreateMemoryService().Kits
+
+		_, err := sut.New("", "", "")
+		assert.IsType(t, core.ValidationError{}, err)
+
+		_, err = sut.Update(42, "Fuzz", "", "")
+		assert.Equal(t, core.KitNotFound{KitID: 42}, err)
+	})
+}
+
+func Test_memorykitservice_Parts(t *testing.T) {
+	t.Run("should add, change and remove parts", func(t *testing.T) {
+		svc := CreateMemoryService()
+		part, _ := svc.Parts.New("1k", core.Resistor)
+		kit, _ := svc.Kits.New("Fuzz", "", "")
+
+		err := svc.Kits.AddPart(kit.ID, part.ID, 2)
+		assert.Nil(t, err)
+
+		err = svc.Kits.AddPart(kit.ID, part.ID, 2)
+		assert.Equal(t, core.PartAlreadyInKit{KitID: kit.ID, PartID: part.ID}, err)
+
+		err = svc.Kits.SetPartDesignators(kit.ID, part.ID, []string{"r1", "R3", "r1"})
+		assert.Nil(t, err)
+
+		kit, _ = svc.Kits.Get(kit.ID)
+		assert.Equal(t, []core.KitPart{{Part: part, Quantity: 2, Designators: []string{"R1", "R3"}}}, kit.Parts)
+
+		err = svc.Kits.SetPartQuantity(kit.ID, part.ID, 3)
+		assert.Equal(t, core.DesignatorMismatch{KitID: kit.ID, PartID: part.ID, Designators: 2, Quantity: 3}, err)
+
+		err = svc.Kits.SetPartDesignators(kit.ID, part.ID, nil)
+		assert.Nil(t, err)
+
+		err = svc.Kits.SetPartQuantity(kit.ID, part.ID, 3)
+		assert.Nil(t, err)
+
+		kit, _ = svc.Kits.Get(kit.ID)
+		assert.Equal(t, []core.KitPart{{Part: part, Quantity: 3}}, kit.Parts)
+
+		err = svc.Kits.RemovePart(kit.ID, part.ID)
+		assert.Nil(t, err)
+
+		err = svc.Kits.RemovePart(kit.ID, part.ID)
+		assert.Equal(t, core.PartNotInKit{KitID: kit.ID, PartID: part.ID}, err)
+	})
+
+	t.Run("should not share designators between parts of a kit", func(t *testing.T) {
+		svc := CreateMemoryService()
+		one, _ := svc.Parts.New("1k", core.Resistor)
+		two, _ := svc.Parts.New("2k", core.Resistor)
+		kit, _ := svc.Kits.New("Fuzz", "", "")
+		svc.Kits.AddPart(kit.ID, one.ID, 1)
+		svc.Kits.AddPart(kit.ID, two.ID, 1)
+		svc.Kits.SetPartDesignators(kit.ID, one.ID, []string{"R1"})
+
+		err := svc.Kits.SetPartDesignators(kit.ID, two.ID, []string{"R2", "R1"})
+		assert.Equal(t, core.DesignatorInUse{KitID: kit.ID, Designator: "R1"}, err)
+
+		kit, _ = svc.Kits.Get(kit.ID)
+		assert.Nil(t, kit.Parts[1].Designators)
+	})
+
+	t.Run("should check the kit and part exist", func(t *testing.T) {
+		svc := CreateMemoryService()
+		part, _ := svc.Parts.New("1k", core.Resistor)
+		kit, _ := svc.Kits.New("Fuzz", "", "")
+
+		err := svc.Kits.AddPart(42, part.ID, 1)
+		assert.Equal(t, core.KitNotFound{KitID: 42}, err)
+
+		err = svc.Kits.AddPart(kit.ID, 42, 1)
+		assert.Equal(t, core.PartNotFound{PartID: 42}, err)
+
+		err = svc.Kits.AddPart(kit.ID, part.ID, 0)
+		assert.IsType(t, core.ValidationError{}, err)
+
+		_, err = svc.Kits.GetPartUsage(42)
+		assert.Equal(t, core.PartNotFound{PartID: 42}, err)
+
+		err = svc.Kits.SetPartQuantity(kit.ID, part.ID, 2)
+		assert.Nil(t, err)
+	})
+
+	t.Run("should list the kits using a part", func(t *testing.T) {
+		svc := CreateMemoryService()
+		part, _ := svc.Parts.New("1k", core.Resistor)
+		one, _ := svc.Kits.New("One", "", "")
+		two, _ := svc.Kits.New("Two", "", "")
+
+		usage, err := svc.Kits.GetPartUsage(part.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, []int64{}, usage)
+
+		svc.Kits.AddPart(two.ID, part.ID, 1)
+		svc.Kits.AddPart(one.ID, part.ID, 1)
+
+		usage, err = svc.Kits.GetPartUsage(part.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, []int64{two.ID, one.ID}, usage)
+	})
+}
+
+func Test_memorykitservice_List(t *testing.T) {
+	t.Run("should sort and page kits", func(t *testing.T) {
+		svc := CreateMemoryService()
+		part, _ := svc.Parts.New("1k", core.Resistor)
+		fuzz, _ := svc.Kits.New("Fuzz", "", "")
+		boost, _ := svc.Kits.New("Boost", "", "")
+		svc.Kits.AddPart(boost.ID, part.ID, 1)
+
+		page, err := svc.Kits.List(core.PageRequest{Sort: core.SortByName})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, page.Total)
+		assert.Equal(t, boost.ID, page.Kits[0].ID)
+		assert.Equal(t, fuzz.ID, page.Kits[1].ID)
+
+		page, err = svc.Kits.List(core.PageRequest{Offset: 1, Limit: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, boost.ID, page.Kits[0].ID)
+
+		_, err = svc.Kits.List(core.PageRequest{Sort: "value"})
+		assert.IsType(t, core.InvalidPageRequest{}, err)
+	})
+}
+
+func Test_memorykitservice_Links(t *testing.T) {
+	t.Run("should add and remove links", func(t *testing.T) {
+		sut := CreateMemoryService().Kits
+		one, _ := sut.New("One", "", "")
+		two, _ := sut.New("Two", "", "")
+
+		link, err := sut.AddLink(one.ID, "example.com/one")
+		assert.Nil(t, err)
+		assert.Equal(t, core.Link{ID: 1, URL: "example.com/one"}, link)
+
+		err = sut.RemoveLink(two.ID, link.ID)
+		assert.Equal(t, core.KitLinkNotFound{LinkID: link.ID, KitID: two.ID}, err)
+
+		err = sut.RemoveLink(one.ID, link.ID)
+		assert.Nil(t, err)
+
+		_, err = sut.AddLink(42, "example.com/one")
+		assert.Equal(t, core.KitNotFound{KitID: 42}, err)
+	})
+}
+
+func Test_memorykitservice_Import(t *testing.T) {
+	t.Run("should match existing parts and create the rest", func(t *testing.T) {
+		svc := CreateMemoryService()
+		existing, _ := svc.Parts.New("10k", core.Resistor)
+
+		result, err := svc.Kits.Import(core.KitSpec{
+			Name:  "Fuzz",
+			Links: []string{"example.com/fuzz"},
+			Parts: []core.KitPartSpec{
+				{Kind: core.Resistor, Name: "10K", Designators: []string{"R1", "R2"}},
+				{Kind: core.IC, Name: "TL072", Quantity: 1, Links: []string{"example.com/tl072"}},
+			},
+		})
+		assert.Nil(t, err)
+
+		created, _ := svc.Parts.Get(2)
+		assert.Equal(t, []core.Part{existing}, result.Matched)
+		assert.Equal(t, []core.Part{created}, result.Created)
+		assert.Equal(t, []core.Link{{ID: 1, URL: "example.com/tl072"}}, created.Links)
+
+		kit, _ := svc.Kits.Get(result.Kit.ID)
+		assert.Equal(t, kit, result.Kit)
+		assert.Equal(t, []core.Link{{ID: 1, URL: "example.com/fuzz"}}, kit.Links)
+		assert.Equal(t, []core.KitPart{
+			{Part: existing, Quantity: 2, Designators: []string{"R1", "R2"}},
+			{Part: created, Quantity: 1},
+		}, kit.Parts)
+	})
+
+	t.Run("should reject invalid specs", func(t *testing.T) {
+		svc := CreateMemoryService()
+
+		_, err := svc.Kits.Import(core.KitSpec{})
+		assert.IsType(t, core.InvalidKitSpec{}, err)
+
+		kits, _ := svc.Kits.GetAll()
+		assert.Empty(t, kits)
+	})
+}
+
+func Test_memorykitservice_Plan(t *testing.T) {
+	t.Run("should plan the builds of existing kits", func(t *testing.T) {
+		svc := CreateMemoryService()
+		part, _ := svc.Parts.New("1k", core.Resistor)
+		kit, _ := svc.Kits.New("Fuzz", "", "")
+		svc.Kits.AddPart(kit.ID, part.ID, 2)
+
+		_, err := svc.Kits.Plan([]core.KitBuild{{KitID: kit.ID, Count: 2}}, map[int64]uint64{})
+		assert.Nil(t, err)
+
+		_, err = svc.Kits.Plan([]core.KitBuild{{KitID: 42, Count: 1}}, nil)
+		assert.Equal(t, core.KitNotFound{KitID: 42}, err)
+	})
+}
@@ -0,0 +1,467 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+)
+
+type MemoryPartService struct {
+	store *store
+}
+
+func (service MemoryPartService) GetAll() ([]core.Part, error) {
+	parts := []core.Part{}
+
+	err := service.store.read(func(d *data) error {
+		for _, id := range d.partIds() {
+			part, _ := d.part(id)
+			parts = append(parts, part)
+		}
+
+		return nil
+	})
+
+	return parts, err
+}
+
+func (service MemoryPartService) Get(partId int64) (core.Part, error) {
+	var part core.Part
+
+	err := service.store.read(func(d *data) (err error) {
+		part, err = d.part(partId)
+
+		return err
+	})
+	if err != nil {
+		return core.Part{}, err
+	}
+
+	return part, nil
+}
+
+func (service MemoryPartService) Find(query core.PartQuery) ([]core.Part, error) {
+	page, err := service.List(query, core.PageRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return page.Parts, nil
+}
+
+func (service MemoryPartService) List(query core.PartQuery, page core.PageRequest) (core.PartPage, error) {
+	err := query.Validate()
+	if err != nil {
+		return core.PartPage{}, err
+	}
+
+	err = page.Validate(core.PartSortKeys)
+	if err != nil {
+		return core.PartPage{}, err
+	}
+
+	var parts []core.Part
+
+	err = service.store.read(func(d *data) error {
+		all := make([]core.Part, 0, len(d.parts))
+		for _, id := range d.partIds() {
+			part, _ := d.part(id)
+			all = append(all, part)
+		}
+
+		parts, err = query.Filter(all, func(partId int64) bool {
+			return len(d.partUsage(partId)) > 0
+		})
+
+		return err
+	})
+	if err != nil {
+		return core.PartPage{}, err
+	}
+
+	core.SortParts(parts, page.Sort, page.Desc)
+	start, end := page.Bounds(len(parts))
+
+	return core.PartPage{
+		Parts:  parts[start:end],
+		Total:  len(parts),
+		Offset: page.Offset,
+		Limit:  page.Limit,
+	}, nil
+}
+
+func (service MemoryPartService) AddLink(partId int64, link string) (core.Link, error) {
+	l := core.Link{
+		URL: link,
+	}
+
+	err := l.Validate()
+	if err != nil {
+		return core.Link{}, err
+	}
+
+	err = service.store.write(func(d *data) error {
+		if _, ok := d.parts[partId]; !ok {
+			return core.PartNotFound{PartID: partId}
+		}
+
+		d.lastPartLinkId++
+		l.ID = d.lastPartLinkId
+		d.partLinks[partId] = append(d.partLinks[partId], l)
+
+		return nil
+	})
+	if err != nil {
+		return core.Link{}, err
+	}
+
+	return l, nil
+}
+
+func (service MemoryPartService) RemoveLink(partId int64, linkId int64) error {
+	return service.store.write(func(d *data) error {
+		if _, ok := d.parts[partId]; !ok {
+			return core.PartNotFound{PartID: partId}
+		}
+
+		links, ok := removeLink(d.partLinks[partId], linkId)
+		if !ok {
+			return core.LinkNotFound{LinkID: linkId, OwnerID: partId}
+		}
+
+		d.partLinks[partId] = links
+
+		return nil
+	})
+}
+
+func (service MemoryPartService) New(name string, kind core.PartType) (core.Part, error) {
+	part := core.Part{
+		Name:  name,
+		Kind:  kind,
+		Value: core.NormalizeValue(kind, name),
+		Links: []core.Link{},
+	}
+
+	err := part.Validate()
+	if err != nil {
+		return part, err
+	}
+
+	err = service.store.write(func(d *data) error {
+		d.lastPartId++
+		part.ID = d.lastPartId
+
+		stored := part
+		stored.Links = nil
+		d.parts[part.ID] = stored
+
+		return nil
+	})
+
+	return part, err
+}
+
+// updatePart sets the part's name and kind after validating them.
+func (d *data) updatePart(partId int64, name string, kind core.PartType) error {
+	err := core.Part{Name: name, Kind: kind}.Validate()
+	if err != nil {
+		return err
+	}
+
+	part, ok := d.parts[partId]
+	if !ok {
+		return core.PartNotFound{PartID: partId}
+	}
+
+	part.Name = name
+	part.Kind = kind
+	part.Value = core.NormalizeValue(kind, name)
+	d.parts[partId] = part
+
+	return nil
+}
+
+func (service MemoryPartService) Update(partId int64, name string, kind core.PartType) (core.Part, error) {
+	var part core.Part
+
+	err := service.store.write(func(d *data) error {
+		err := d.updatePart(partId, name, kind)
+		if err != nil {
+			return err
+		}
+
+		part, err = d.part(partId)
+
+		return err
+	})
+	if err != nil {
+		return core.Part{}, err
+	}
+
+	return part, nil
+}
+
+func (service MemoryPartService) Patch(partId int64, patch core.PartPatch) (core.Part, error) {
+	var part core.Part
+
+	err := service.store.write(func(d *data) error {
+		current, err := d.part(partId)
+		if err != nil {
+			return err
+		}
+
+		patched := patch.Apply(current)
+
+		err = d.updatePart(partId, patched.Name, patched.Kind)
+		if err != nil {
+			return err
+		}
+
+		part, err = d.part(partId)
+
+		return err
+	})
+	if err != nil {
+		return core.Part{}, err
+	}
+
+	return part, nil
+}
+
+func (service MemoryPartService) Delete(partId int64) error {
+	return service.store.write(func(d *data) error {
+		if _, ok := d.parts[partId]; !ok {
+			return core.PartNotFound{PartID: partId}
+		}
+
+		if len(d.partUsage(partId)) > 0 {
+			return core.PartInUse{PartID: partId}
+		}
+
+		d.removePart(partId)
+
+		return nil
+	})
+}
+
+// removePart deletes the part along with its links, stock and stock
+// history.
+func (d *data) removePart(partId int64) {
+	delete(d.parts, partId)
+	delete(d.partLinks, partId)
+	delete(d.stock, partId)
+
+	adjustments := []core.StockAdjustment{}
+	for _, a := range d.adjustments {
+		if a.PartID != partId {
+			adjustments = append(adjustments, a)
+		}
+	}
+
+	d.adjustments = adjustments
+}
+
+func (service MemoryPartService) FindDuplicates() ([][]core.Part, error) {
+	parts, err := service.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	return core.FindDuplicates(parts), nil
+}
+
+// Merge folds the duplicate into the part. Kits using both parts keep
+// the part with the quantities summed, links the part already has are
+// dropped and the stock of both parts is added together. The merge is
+// refused with DesignatorMismatch when a kit using both parts would be
+// left with a quantity other than its number of designators.
+func (service MemoryPartService) Merge(partId int64, duplicateId int64) error {
+	if partId == duplicateId {
+		return core.CannotMergeParts{PartID: partId, DuplicateID: duplicateId}
+	}
+
+	return service.store.write(func(d *data) error {
+		part, err := d.part(partId)
+		if err != nil {
+			return err
+		}
+
+		duplicate, err := d.part(duplicateId)
+		if err != nil {
+			return err
+		}
+
+		if !part.IsDuplicateOf(duplicate) {
+			return core.CannotMergeParts{PartID: partId, DuplicateID: duplicateId}
+		}
+
+		kitIds := []int64{}
+		for kitId := range d.kitParts {
+			kitIds = append(kitIds, kitId)
+		}
+		sort.Slice(kitIds, func(i, j int) bool { return kitIds[i] < kitIds[j] })
+
+		for _, kitId := range kitIds {
+			i, dup := d.findKitPart(kitId, partId), d.findKitPart(kitId, duplicateId)
+			if i == -1 || dup == -1 {
+				continue
+			}
+
+			kps := d.kitParts[kitId]
+			designators := len(kps[i].designators) + len(kps[dup].designators)
+			quantity := kps[i].quantity + kps[dup].quantity
+
+			if designators > 0 && uint64(designators) != quantity {
+				return core.DesignatorMismatch{KitID: kitId, PartID: partId, Designators: designators, Quantity: quantity}
+			}
+		}
+
+		for kitId, kps := range d.kitParts {
+			dup := d.findKitPart(kitId, duplicateId)
+			if dup == -1 {
+				continue
+			}
+
+			if i := d.findKitPart(kitId, partId); i != -1 {
+				kps[i].quantity += kps[dup].quantity
+				kps[i].designators = append(kps[i].designators, kps[dup].designators...)
+				d.kitParts[kitId] = append(kps[:dup], kps[dup+1:]...)
+			} else {
+				kps[dup].partId = partId
+			}
+		}
+
+		urls := map[string]bool{}
+		for _, l := range part.Links {
+			urls[l.URL] = true
+		}
+
+		links := d.partLinks[partId]
+		for _, l := range duplicate.Links {
+			if !urls[l.URL] {
+				links = append(links, l)
+			}
+		}
+
+		sort.Slice(links, func(i, j int) bool { return links[i].ID < links[j].ID })
+		d.partLinks[partId] = links
+
+		if dupStock, ok := d.stock[duplicateId]; ok {
+			stock, ok := d.stock[partId]
+			if !ok {
+				stock = core.Stock{PartID: partId, Location: dupStock.Location}
+			}
+
+			stock.Quantity += dupStock.Quantity
+			d.stock[partId] = stock
+		}
+
+		for i := range d.adjustments {
+			if d.adjustments[i].PartID == duplicateId {
+				d.adjustments[i].PartID = partId
+			}
+		}
+
+		d.removePart(duplicateId)
+
+		return nil
+	})
+}
+
+func (service MemoryPartService) GetAllContext(ctx context.Context) ([]core.Part, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return service.GetAll()
+}
+
+func (service MemoryPartService) GetContext(ctx context.Context, partId int64) (core.Part, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Part{}, err
+	}
+
+	return service.Get(partId)
+}
+
+func (service MemoryPartService) FindContext(ctx context.Context, query core.PartQuery) ([]core.Part, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return service.Find(query)
+}
+
+func (service MemoryPartService) ListContext(ctx context.Context, query core.PartQuery, page core.PageRequest) (core.PartPage, error) {
+	if err := ctx.Err(); err != nil {
+		return core.PartPage{}, err
+	}
+
+	return service.List(query, page)
+}
+
+func (service MemoryPartService) AddLinkContext(ctx context.Context, partId int64, link string) (core.Link, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Link{}, err
+	}
+
+	return service.AddLink(partId, link)
+}
+
+func (service MemoryPartService) RemoveLinkContext(ctx context.Context, partId int64, linkId int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return service.RemoveLink(partId, linkId)
+}
+
+func (service MemoryPartService) NewContext(ctx context.Context, name string, kind core.PartType) (core.Part, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Part{}, err
+	}
+
+	return service.New(name, kind)
+}
+
+func (service MemoryPartService) UpdateContext(ctx context.Context, partId int64, name string, kind core.PartType) (core.Part, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Part{}, err
+	}
+
+	return service.Update(partId, name, kind)
+}
+
+func (service MemoryPartService) PatchContext(ctx context.Context, partId int64, patch core.PartPatch) (core.Part, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Part{}, err
+	}
+
+	return service.Patch(partId, patch)
+}
+
+func (service MemoryPartService) DeleteContext(ctx context.Context, partId int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return service.Delete(partId)
+}
+
+func (service MemoryPartService) FindDuplicatesContext(ctx context.Context) ([][]core.Part, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return service.FindDuplicates()
+}
+
+func (service MemoryPartService) MergeContext(ctx context.Context, partId int64, duplicateId int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return service.Merge(partId, duplicateId)
+}
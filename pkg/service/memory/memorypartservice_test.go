@@ -0,0 +1,246 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_memorypartservice_New(t *testing.T) {
+	t.Run("should number parts and normalize their value", func(t *testing.T) {
+		sut := CreateMemoryService().Parts
+
+		first, err := sut.New("10K", core.Resistor)
+		assert.Nil(t, err)
+
+		second, err := sut.New("TL072", core.IC)
+		assert.Nil(t, err)
+
+		assert.Equal(t, core.Part{ID: 1, Name: "10K", Kind: core.Resistor, Value: "10k", Links: []core.Link{}}, first)
+		assert.Equal(t, int64(2), second.ID)
+
+		parts, err := sut.GetAll()
+		assert.Nil(t, err)
+		assert.Equal(t, []core.Part{first, second}, parts)
+	})
+
+	t.Run("should reject invalid parts", func(t *testing.T) {
+		sut := CreateMemoryService().Parts
+
+		_, err := sut.New("", core.Resistor)
+		assert.IsType(t, core.ValidationError{}, err)
+
+		_, err = sut.New("1k", "Widget")
+		assert.Equal(t, core.InvalidPartType{InvalidType: "Widget"}, err)
+
+		parts, _ := sut.GetAll()
+		assert.Empty(t, parts)
+	})
+}
+
+func Test_memorypartservice_Links(t *testing.T) {
+	t.Run("should add and remove links", func(t *testing.T) {
+		sut := CreateMemoryService().Parts
+		part, _ := sut.New("1k", core.Resistor)
+
+		link, err := sut.AddLink(part.ID, "example.com/1k")
+		assert.Nil(t, err)
+		assert.Equal(t, core.Link{ID: 1, URL: "example.com/1k"}, link)
+
+		part, _ = sut.Get(part.ID)
+		assert.Equal(t, []core.Link{link}, part.Links)
+
+		err = sut.RemoveLink(part.ID, link.ID)
+		assert.Nil(t, err)
+
+		part, _ = sut.Get(part.ID)
+		assert.Equal(t, []core.Link{}, part.Links)
+	})
+
+	t.Run("should not remove the link of another part", func(t *testing.T) {
+		sut := CreateMemoryService().Parts
+		one, _ := sut.New("1k", core.Resistor)
+		two, _ := sut.New("2k", core.Resistor)
+		link, _ := sut.AddLink(one.ID, "example.com/1k")
+
+		err := sut.RemoveLink(two.ID, link.ID)
+		assert.Equal(t, core.LinkNotFound{LinkID: link.ID, OwnerID: two.ID}, err)
+
+		err = sut.RemoveLink(42, link.ID)
+		assert.Equal(t, core.PartNotFound{PartID: 42}, err)
+
+		_, err = sut.AddLink(42, "example.com/1k")
+		assert.Equal(t, core.PartNotFound{PartID: 42}, err)
+	})
+
+	t.Run("should not share links with callers", func(t *testing.T) {
+		sut := CreateMemoryService().Parts
+		part, _ := sut.New("1k", core.Resistor)
+		sut.AddLink(part.ID, "example.com/1k")
+
+		part, _ = sut.Get(part.ID)
+		part.Links[0].URL = "example.com/changed"
+
+		part, _ = sut.Get(part.ID)
+		assert.Equal(t, "example.com/1k", part.Links[0].URL)
+	})
+}
+
+func Test_memorypartservice_List(t *testing.T) {
+	t.Run("should filter, sort and page parts", func(t *testing.T) {
+		svc := CreateMemoryService()
+		small, _ := svc.Parts.New("1k", core.Resistor)
+		large, _ := svc.Parts.New("100k", core.Resistor)
+		svc.Parts.New("TL072", core.IC)
+		kit, _ := svc.Kits.New("Fuzz", "", "")
+		svc.Kits.AddPart(kit.ID, large.ID, 1)
+
+		page, err := svc.Parts.List(core.PartQuery{Kind: core.Resistor}, core.PageRequest{Sort: core.SortByValue, Desc: true, Limit: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, page.Total)
+		assert.Equal(t, []core.Part{large}, page.Parts)
+
+		unused := false
+		parts, err := svc.Parts.Find(core.PartQuery{Kind: core.Resistor, InUse: &unused})
+		assert.Nil(t, err)
+		assert.Equal(t, []core.Part{small}, parts)
+	})
+
+	t.Run("should reject invalid queries and pages", func(t *testing.T) {
+		sut := CreateMemoryService().Parts
+
+		_, err := sut.List(core.PartQuery{Min: "1k"}, core.PageRequest{})
+		assert.IsType(t, core.InvalidPartQuery{}, err)
+
+		_, err = sut.List(core.PartQuery{}, core.PageRequest{Sort: "color"})
+		assert.IsType(t, core.InvalidPageRequest{}, err)
+	})
+}
+
+func Test_memorypartservice_Update(t *testing.T) {
+	t.Run("should update and patch parts", func(t *testing.T) {
+		sut := CreateMemoryService().Parts
+		part, _ := sut.New("1k", core.Resistor)
+
+		part, err := sut.Update(part.ID, "4.7K", core.Resistor)
+		assert.Nil(t, err)
+		assert.Equal(t, "4.7k", part.Value)
+
+		name := "4k7"
+		part, err = sut.Patch(part.ID, core.PartPatch{Name: &name})
+		assert.Nil(t, err)
+		assert.Equal(t, core.Part{ID: part.ID, Name: "4k7", Kind: core.Resistor, Value: "4.7k", Links: []core.Link{}}, part)
+	})
+
+	t.Run("should return PartNotFound for a missing part", func(t *testing.T) {
+		sut := CreateMemoryService().Parts
+
+		_, err := sut.Update(42, "1k", core.Resistor)
+		assert.Equal(t, core.PartNotFound{PartID: 42}, err)
+
+		_, err = sut.Patch(42, core.PartPatch{})
+		assert.Equal(t, core.PartNotFound{PartID: 42}, err)
+	})
+}
+
+func Test_memorypartservice_Delete(t *testing.T) {
+	t.Run("should not delete parts used by a kit", func(t *testing.T) {
+		svc := CreateMemoryService()
+		part, _ := svc.Parts.New("1k", core.Resistor)
+		kit, _ := svc.Kits.New("Fuzz", "", "")
+		svc.Kits.AddPart(kit.ID, part.ID, 1)
+
+		err := svc.Parts.Delete(part.ID)
+		assert.Equal(t, core.PartInUse{PartID: part.ID}, err)
+
+		svc.Kits.RemovePart(kit.ID, part.ID)
+
+		err = svc.Parts.Delete(part.ID)
+		assert.Nil(t, err)
+
+		err = svc.Parts.Delete(part.ID)
+		assert.Equal(t, core.PartNotFound{PartID: part.ID}, err)
+	})
+}
+
+func Test_memorypartservice_Merge(t *testing.T) {
+	t.Run("should fold the duplicate into the part", func(t *testing.T) {
+		svc := CreateMemoryService()
+		part, _ := svc.Parts.New("10k", core.Resistor)
+		duplicate, _ := svc.Parts.New("10K", core.Resistor)
+		svc.Parts.AddLink(part.ID, "example.com/10k")
+		svc.Parts.AddLink(duplicate.ID, "example.com/10k")
+		other, _ := svc.Parts.AddLink(duplicate.ID, "example.com/10K")
+
+		shared, _ := svc.Kits.New("Shared", "", "")
+		svc.Kits.AddPart(shared.ID, part.ID, 2)
+		svc.Kits.AddPart(shared.ID, duplicate.ID, 3)
+
+		moved, _ := svc.Kits.New("Moved", "", "")
+		svc.Kits.AddPart(moved.ID, duplicate.ID, 1)
+
+		svc.Inventory.Adjust(duplicate.ID, core.Received, 5, "")
+		svc.Inventory.SetLocation(duplicate.ID, "drawer B2")
+
+		dupes, err := svc.Parts.FindDuplicates()
+		assert.Nil(t, err)
+		assert.Len(t, dupes, 1)
+
+		err = svc.Parts.Merge(part.ID, duplicate.ID)
+		assert.Nil(t, err)
+
+		_, err = svc.Parts.Get(duplicate.ID)
+		assert.Equal(t, core.PartNotFound{PartID: duplicate.ID}, err)
+
+		part, _ = svc.Parts.Get(part.ID)
+		assert.Equal(t, []core.Link{{ID: 1, URL: "example.com/10k"}, other}, part.Links)
+
+		shared, _ = svc.Kits.Get(shared.ID)
+		assert.Equal(t, []core.KitPart{{Part: part, Quantity: 5}}, shared.Parts)
+
+		moved, _ = svc.Kits.Get(moved.ID)
+		assert.Equal(t, []core.KitPart{{Part: part, Quantity: 1}}, moved.Parts)
+
+		stock, _ := svc.Inventory.Get(part.ID)
+		assert.Equal(t, core.Stock{PartID: part.ID, Quantity: 5, Location: "drawer B2"}, stock)
+
+		history, _ := svc.Inventory.GetHistory(part.ID)
+		assert.Len(t, history, 1)
+	})
+
+	t.Run("should not merge a part into itself, another kind or another value", func(t *testing.T) {
+		svc := CreateMemoryService()
+		part, _ := svc.Parts.New("10k", core.Resistor)
+		pot, _ := svc.Parts.New("10k", core.Potentiometer)
+		small, _ := svc.Parts.New("1k", core.Resistor)
+
+		err := svc.Parts.Merge(part.ID, part.ID)
+		assert.Equal(t, core.CannotMergeParts{PartID: part.ID, DuplicateID: part.ID}, err)
+
+		err = svc.Parts.Merge(part.ID, pot.ID)
+		assert.Equal(t, core.CannotMergeParts{PartID: part.ID, DuplicateID: pot.ID}, err)
+
+		err = svc.Parts.Merge(part.ID, small.ID)
+		assert.Equal(t, core.CannotMergeParts{PartID: part.ID, DuplicateID: small.ID}, err)
+
+		err = svc.Parts.Merge(part.ID, 42)
+		assert.Equal(t, core.PartNotFound{PartID: 42}, err)
+	})
+}
+
+func Test_memorypartservice_Context(t *testing.T) {
+	t.Run("should return the error of a done context", func(t *testing.T) {
+		sut := CreateMemoryService().Parts
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := sut.NewContext(ctx, "1k", core.Resistor)
+		assert.Equal(t, context.Canceled, err)
+
+		parts, _ := sut.GetAllContext(context.Background())
+		assert.Empty(t, parts)
+	})
+}
@@ -0,0 +1,18 @@
+package memory
+
+import (
+	"context"
+)
+
+type MemoryStorage struct{}
+
+// Ping always succeeds while ctx is not done.
+func (s MemoryStorage) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// Close does nothing. The data is dropped once the service is no longer
+// referenced.
+func (s MemoryStorage) Close() error {
+	return nil
+}
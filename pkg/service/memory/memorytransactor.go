@@ -0,0 +1,19 @@
+package memory
+
+import (
+	"github.com/sombrerosheep/partsbundler/pkg/service"
+)
+
+type MemoryTransactor struct {
+	store *store
+}
+
+// Atomic runs fn against a copy of the store which replaces it when fn
+// returns nil. Other callers wait until fn returns, so fn must only use
+// the service it is given. Calls to Atomic made from within fn join the
+// outer unit of work.
+func (t MemoryTransactor) Atomic(fn func(svc *service.BundlerService) error) error {
+	return t.store.atomic(func(d *data) error {
+		return fn(newMemoryService(&store{data: d}))
+	})
+}
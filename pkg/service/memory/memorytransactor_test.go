@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+	"github.com/sombrerosheep/partsbundler/pkg/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_memorytransactor_Atomic(t *testing.T) {
+	t.Run("should keep the changes of fn when it succeeds", func(t *testing.T) {
+		svc := CreateMemoryService()
+
+		err := svc.Atomic(func(tx *service.BundlerService) error {
+			part, err := tx.Parts.New("1k", core.Resistor)
+			if err != nil {
+				return err
+			}
+
+			return tx.Atomic(func(inner *service.BundlerService) error {
+				_, err := inner.Parts.AddLink(part.ID, "example.com/1k")
+
+				return err
+			})
+		})
+		assert.Nil(t, err)
+
+		part, err := svc.Parts.Get(1)
+		assert.Nil(t, err)
+		assert.Len(t, part.Links, 1)
+	})
+
+	t.Run("should drop the changes of fn when it fails", func(t *testing.T) {
+		svc := CreateMemoryService()
+
+		expected := errors.New("failed")
+		err := svc.Atomic(func(tx *service.BundlerService) error {
+			tx.Parts.New("1k", core.Resistor)
+			tx.Kits.New("Fuzz", "", "")
+
+			return expected
+		})
+		assert.Equal(t, expected, err)
+
+		parts, _ := svc.Parts.GetAll()
+		assert.Empty(t, parts)
+
+		kit, err := svc.Kits.New("Fuzz", "", "")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), kit.ID)
+	})
+
+	t.Run("should leave nothing behind when a call fails", func(t *testing.T) {
+		svc := CreateMemoryService()
+		part, _ := svc.Parts.New("1k", core.Resistor)
+
+		_, err := svc.Inventory.Adjust(part.ID, core.Consumed, 1, "")
+		assert.IsType(t, core.InsufficientStock{}, err)
+
+		history, _ := svc.Inventory.GetHistory(part.ID)
+		assert.Empty(t, history)
+
+		kit, _ := svc.Kits.New("Fuzz", "", "")
+		other, _ := svc.Parts.New("10k", core.Resistor)
+		svc.Kits.AddPart(kit.ID, part.ID, 1)
+		svc.Kits.AddPart(kit.ID, other.ID, 1)
+		svc.Kits.SetPartDesignators(kit.ID, part.ID, []string{"R1"})
+
+		err = svc.Kits.SetPartDesignators(kit.ID, other.ID, []string{"R2", "R1"})
+		assert.IsType(t, core.DesignatorInUse{}, err)
+
+		kit, _ = svc.Kits.Get(kit.ID)
+		assert.Equal(t, []string{"R1"}, kit.Parts[0].Designators)
+		assert.Empty(t, kit.Parts[1].Designators)
+		assert.Equal(t, uint64(1), kit.Parts[1].Quantity)
+	})
+}
+
+func Test_memoryservice_Concurrency(t *testing.T) {
+	t.Run("should serve concurrent callers", func(t *testing.T) {
+		svc := CreateMemoryService()
+		kit, _ := svc.Kits.New("Fuzz", "", "")
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				part, err := svc.Parts.New(fmt.Sprintf("%dk", i+1), core.Resistor)
+				assert.Nil(t, err)
+
+				assert.Nil(t, svc.Kits.AddPart(kit.ID, part.ID, 1))
+
+				_, err = svc.Kits.Get(kit.ID)
+				assert.Nil(t, err)
+
+				_, err = svc.Parts.List(core.PartQuery{}, core.PageRequest{Limit: 5})
+				assert.Nil(t, err)
+			}(i)
+		}
+		wg.Wait()
+
+		kit, _ = svc.Kits.Get(kit.ID)
+		assert.Len(t, kit.Parts, 20)
+		assert.Nil(t, svc.Ping(context.Background()))
+		assert.Nil(t, svc.Close())
+	})
+}
@@ -0,0 +1,14 @@
+package mock
+
+import (
+	"testing"
+
+	"github.com/sombrerosheep/partsbundler/pkg/service"
+	"github.com/sombrerosheep/partsbundler/pkg/service/servicetest"
+)
+
+func Test_StubConformance(t *testing.T) {
+	servicetest.RunFixed(t, func(t *testing.T) *service.BundlerService {
+		return StubBundlerService
+	})
+}
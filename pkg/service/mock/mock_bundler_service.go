@@ -1,6 +1,8 @@
 package mock
 
 import (
+	"context"
+
 	"github.com/sombrerosheep/partsbundler/pkg/core"
 	"github.com/sombrerosheep/partsbundler/pkg/service"
 )
@@ -18,12 +20,14 @@ var FakeParts = [...]core.Part{
 		ID:    1,
 		Kind:  "Resistor",
 		Name:  "1k",
+		Value: "1k",
 		Links: FakeLinks[:],
 	},
 	{
 		ID:    2,
 		Kind:  "Capacitor",
 		Name:  "47pf",
+		Value: "47pF",
 		Links: FakeLinks[:],
 	},
 }
@@ -45,20 +49,37 @@ var FakeKits = [...]core.Kit{
 	},
 }
 
+var FakeStock = [...]core.Stock{
+	{
+		PartID:   1,
+		Quantity: 10,
+		Location: "drawer A1",
+	},
+}
+
 type stubPartService struct {
 	service.IPartService
 }
 
 type stubKitService struct {
 	service.IKitService
+
+	// created holds kits made by New so later calls can find them.
+	created map[int64]core.Kit
+}
+
+type stubInventoryService struct {
+	service.IInventoryService
 }
 
 var stubParts = stubPartService{}
-var stubKits = stubKitService{}
+var stubKits = stubKitService{created: map[int64]core.Kit{}}
+var stubInventory = stubInventoryService{}
 
 var StubBundlerService = &service.BundlerService{
-	Parts: &stubParts,
-	Kits:  &stubKits,
+	Parts:     &stubParts,
+	Kits:      &stubKits,
+	Inventory: &stubInventory,
 }
 
 func (s *stubPartService) GetAll() ([]core.Part, error) {
@@ -66,6 +87,10 @@ func (s *stubPartService) GetAll() ([]core.Part, error) {
 }
 
 func (s *stubPartService) New(name string, kind core.PartType) (core.Part, error) {
+	if err := (core.Part{Name: name, Kind: kind}).Validate(); err != nil {
+		return core.Part{}, err
+	}
+
 	id := partIdCounter
 	partIdCounter += 1
 
@@ -73,6 +98,7 @@ func (s *stubPartService) New(name string, kind core.PartType) (core.Part, error
 		ID:    id,
 		Kind:  kind,
 		Name:  name,
+		Value: core.NormalizeValue(kind, name),
 		Links: []core.Link{},
 	}
 
@@ -89,7 +115,40 @@ func (s *stubPartService) Get(partId int64) (core.Part, error) {
 	return core.Part{}, core.PartNotFound{PartID: partId}
 }
 
+func (s *stubPartService) Find(query core.PartQuery) ([]core.Part, error) {
+	return query.Filter(FakeParts[:], func(partId int64) bool {
+		kitIds, _ := stubKits.GetPartUsage(partId)
+		return len(kitIds) > 0
+	})
+}
+
+func (s *stubPartService) List(query core.PartQuery, page core.PageRequest) (core.PartPage, error) {
+	err := page.Validate(core.PartSortKeys)
+	if err != nil {
+		return core.PartPage{}, err
+	}
+
+	parts, err := s.Find(query)
+	if err != nil {
+		return core.PartPage{}, err
+	}
+
+	core.SortParts(parts, page.Sort, page.Desc)
+	start, end := page.Bounds(len(parts))
+
+	return core.PartPage{
+		Parts:  parts[start:end],
+		Total:  len(parts),
+		Offset: page.Offset,
+		Limit:  page.Limit,
+	}, nil
+}
+
 func (s *stubPartService) AddLink(partId int64, link string) (core.Link, error) {
+	if err := (core.Link{URL: link}).Validate(); err != nil {
+		return core.Link{}, err
+	}
+
 	_, err := s.Get(partId)
 	if err != nil {
 		return core.Link{}, err
@@ -106,11 +165,85 @@ func (s *stubPartService) AddLink(partId int64, link string) (core.Link, error)
 	return newLink, nil
 }
 
-func (s *stubPartService) RemoveLink(linkId, partId int64) error {
-	return nil
+func (s *stubPartService) RemoveLink(partId, linkId int64) error {
+	part, err := s.Get(partId)
+	if err != nil {
+		return err
+	}
+
+	for _, l := range part.Links {
+		if l.ID == linkId {
+			return nil
+		}
+	}
+
+	return core.LinkNotFound{LinkID: linkId, OwnerID: partId}
+}
+
+func (s *stubPartService) Update(partId int64, name string, kind core.PartType) (core.Part, error) {
+	if err := (core.Part{Name: name, Kind: kind}).Validate(); err != nil {
+		return core.Part{}, err
+	}
+
+	part, err := s.Get(partId)
+	if err != nil {
+		return core.Part{}, err
+	}
+
+	part.Name = name
+	part.Kind = kind
+	part.Value = core.NormalizeValue(kind, name)
+
+	return part, nil
+}
+
+func (s *stubPartService) Patch(partId int64, patch core.PartPatch) (core.Part, error) {
+	part, err := s.Get(partId)
+	if err != nil {
+		return core.Part{}, err
+	}
+
+	part = patch.Apply(part)
+
+	return s.Update(partId, part.Name, part.Kind)
 }
 
 func (s *stubPartService) Delete(partId int64) error {
+	kitIds, err := stubKits.GetPartUsage(partId)
+	if err != nil {
+		return err
+	}
+
+	if len(kitIds) > 0 {
+		return core.PartInUse{PartID: partId}
+	}
+
+	return nil
+}
+
+func (s *stubPartService) FindDuplicates() ([][]core.Part, error) {
+	return core.FindDuplicates(FakeParts[:]), nil
+}
+
+func (s *stubPartService) Merge(partId, duplicateId int64) error {
+	if partId == duplicateId {
+		return core.CannotMergeParts{PartID: partId, DuplicateID: duplicateId}
+	}
+
+	part, err := s.Get(partId)
+	if err != nil {
+		return err
+	}
+
+	duplicate, err := s.Get(duplicateId)
+	if err != nil {
+		return err
+	}
+
+	if !part.IsDuplicateOf(duplicate) {
+		return core.CannotMergeParts{PartID: partId, DuplicateID: duplicateId}
+	}
+
 	return nil
 }
 
@@ -118,6 +251,25 @@ func (s *stubKitService) GetAll() ([]core.Kit, error) {
 	return FakeKits[:], nil
 }
 
+func (s *stubKitService) List(page core.PageRequest) (core.KitPage, error) {
+	err := page.Validate(core.KitSortKeys)
+	if err != nil {
+		return core.KitPage{}, err
+	}
+
+	kits := append([]core.Kit{}, FakeKits[:]...)
+
+	core.SortKits(kits, page.Sort, page.Desc)
+	start, end := page.Bounds(len(kits))
+
+	return core.KitPage{
+		Kits:   kits[start:end],
+		Total:  len(kits),
+		Offset: page.Offset,
+		Limit:  page.Limit,
+	}, nil
+}
+
 func (s *stubKitService) Get(kitId int64) (core.Kit, error) {
 	for _, v := range FakeKits {
 		if v.ID == kitId {
@@ -125,6 +277,10 @@ func (s *stubKitService) Get(kitId int64) (core.Kit, error) {
 		}
 	}
 
+	if kit, ok := s.created[kitId]; ok {
+		return kit, nil
+	}
+
 	return core.Kit{}, core.KitNotFound{KitID: kitId}
 }
 
@@ -144,6 +300,10 @@ func (s *stubKitService) GetPartUsage(partId int64) ([]int64, error) {
 }
 
 func (s *stubKitService) New(name, schematic, diagram string) (core.Kit, error) {
+	if err := (core.Kit{Name: name, Schematic: schematic, Diagram: diagram}).Validate(); err != nil {
+		return core.Kit{}, err
+	}
+
 	kitId := kitIdCounter
 	kitIdCounter += 1
 
@@ -156,10 +316,16 @@ func (s *stubKitService) New(name, schematic, diagram string) (core.Kit, error)
 		Links:     []core.Link{},
 	}
 
+	s.created[kitId] = kit
+
 	return kit, nil
 }
 
 func (s *stubKitService) AddLink(kitId int64, link string) (core.Link, error) {
+	if err := (core.Link{URL: link}).Validate(); err != nil {
+		return core.Link{}, err
+	}
+
 	_, err := s.Get(kitId)
 	if err != nil {
 		return core.Link{}, err
@@ -176,21 +342,455 @@ func (s *stubKitService) AddLink(kitId int64, link string) (core.Link, error) {
 }
 
 func (s *stubKitService) RemoveLink(kitId int64, linkId int64) error {
-	return nil
+	kit, err := s.Get(kitId)
+	if err != nil {
+		return err
+	}
+
+	for _, l := range kit.Links {
+		if l.ID == linkId {
+			return nil
+		}
+	}
+
+	return core.KitLinkNotFound{LinkID: linkId, KitID: kitId}
 }
 
 func (s *stubKitService) AddPart(kitId, partId int64, quantity uint64) error {
+	if err := core.ValidateQuantity(quantity); err != nil {
+		return err
+	}
+
+	kit, err := s.Get(kitId)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range kit.Parts {
+		if p.ID == partId {
+			return core.PartAlreadyInKit{KitID: kitId, PartID: partId}
+		}
+	}
+
 	return nil
 }
 
 func (s *stubKitService) SetPartQuantity(kitId, partId int64, quantity uint64) error {
-	return nil
+	if err := core.ValidateQuantity(quantity); err != nil {
+		return err
+	}
+
+	if _, err := stubParts.Get(partId); err != nil {
+		return err
+	}
+
+	_, err := s.Get(kitId)
+
+	return err
+}
+
+func (s *stubKitService) SetPartDesignators(kitId, partId int64, designators []string) error {
+	kit, err := s.Get(kitId)
+	if err != nil {
+		return err
+	}
+
+	designators = core.NormalizeDesignators(designators)
+
+	for i, p := range kit.Parts {
+		if p.ID == partId {
+			kit.Parts[i].Designators = nil
+			if len(designators) > 0 {
+				kit.Parts[i].Designators = designators
+				kit.Parts[i].Quantity = uint64(len(designators))
+			}
+
+			return nil
+		}
+	}
+
+	return core.PartNotInKit{KitID: kitId, PartID: partId}
 }
 
 func (s *stubKitService) RemovePart(kitId, partId int64) error {
-	return nil
+	kit, err := s.Get(kitId)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range kit.Parts {
+		if p.ID == partId {
+			return nil
+		}
+	}
+
+	return core.PartNotInKit{KitID: kitId, PartID: partId}
+}
+
+func (s *stubKitService) Update(kitId int64, name, schematic, diagram string) (core.Kit, error) {
+	if err := (core.Kit{Name: name, Schematic: schematic, Diagram: diagram}).Validate(); err != nil {
+		return core.Kit{}, err
+	}
+
+	kit, err := s.Get(kitId)
+	if err != nil {
+		return core.Kit{}, err
+	}
+
+	kit.Name = name
+	kit.Schematic = schematic
+	kit.Diagram = diagram
+
+	return kit, nil
+}
+
+func (s *stubKitService) Patch(kitId int64, patch core.KitPatch) (core.Kit, error) {
+	kit, err := s.Get(kitId)
+	if err != nil {
+		return core.Kit{}, err
+	}
+
+	return patch.Apply(kit), nil
 }
 
 func (s *stubKitService) Delete(kitId int64) error {
 	return nil
 }
+
+func (s *stubKitService) Plan(builds []core.KitBuild, onHand map[int64]uint64) (core.Plan, error) {
+	kits := map[int64]core.Kit{}
+
+	for _, build := range builds {
+		kit, err := s.Get(build.KitID)
+		if err != nil {
+			return core.Plan{}, err
+		}
+
+		kits[kit.ID] = kit
+	}
+
+	return core.NewPlan(builds, kits, onHand)
+}
+
+func (s *stubKitService) Import(spec core.KitSpec) (core.KitImport, error) {
+	if err := spec.Validate(); err != nil {
+		return core.KitImport{}, err
+	}
+
+	kit, err := s.New(spec.Name, spec.Schematic, spec.Diagram)
+	if err != nil {
+		return core.KitImport{}, err
+	}
+
+	result := core.KitImport{
+		Matched: []core.Part{},
+		Created: []core.Part{},
+	}
+
+	for _, link := range spec.Links {
+		kit.Links = append(kit.Links, core.Link{ID: linkIdCounter, URL: link})
+		linkIdCounter += 1
+	}
+
+	for _, p := range spec.Merged() {
+		var part core.Part
+		found := false
+
+		for _, v := range FakeParts {
+			if v.Kind == p.Kind && v.Value == p.Value() {
+				part = v
+				found = true
+				break
+			}
+		}
+
+		if found {
+			result.Matched = append(result.Matched, part)
+		} else {
+			part, err = stubParts.New(p.Name, p.Kind)
+			if err != nil {
+				return core.KitImport{}, err
+			}
+
+			result.Created = append(result.Created, part)
+		}
+
+		kit.Parts = append(kit.Parts, core.KitPart{Part: part, Quantity: p.Quantity})
+	}
+
+	result.Kit = kit
+
+	return result, nil
+}
+
+func (s *stubInventoryService) GetAll() ([]core.Stock, error) {
+	return FakeStock[:], nil
+}
+
+func (s *stubInventoryService) Get(partId int64) (core.Stock, error) {
+	_, err := stubParts.Get(partId)
+	if err != nil {
+		return core.Stock{}, err
+	}
+
+	for _, v := range FakeStock {
+		if v.PartID == partId {
+			return v, nil
+		}
+	}
+
+	return core.Stock{PartID: partId}, nil
+}
+
+func (s *stubInventoryService) Adjust(partId int64, kind core.AdjustmentKind, quantity uint64, note string) (core.Stock, error) {
+	stock, err := s.Get(partId)
+	if err != nil {
+		return core.Stock{}, err
+	}
+
+	return stock.Apply(kind, quantity)
+}
+
+func (s *stubInventoryService) SetLocation(partId int64, location string) (core.Stock, error) {
+	stock, err := s.Get(partId)
+	if err != nil {
+		return core.Stock{}, err
+	}
+
+	stock.Location = location
+
+	return stock, nil
+}
+
+func (s *stubInventoryService) GetHistory(partId int64) ([]core.StockAdjustment, error) {
+	_, err := s.Get(partId)
+	if err != nil {
+		return nil, err
+	}
+
+	return []core.StockAdjustment{}, nil
+}
+
+func (s *stubPartService) GetAllContext(ctx context.Context) ([]core.Part, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.GetAll()
+}
+
+func (s *stubPartService) GetContext(ctx context.Context, partId int64) (core.Part, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Part{}, err
+	}
+
+	return s.Get(partId)
+}
+
+func (s *stubPartService) FindContext(ctx context.Context, query core.PartQuery) ([]core.Part, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.Find(query)
+}
+
+func (s *stubPartService) ListContext(ctx context.Context, query core.PartQuery, page core.PageRequest) (core.PartPage, error) {
+	if err := ctx.Err(); err != nil {
+		return core.PartPage{}, err
+	}
+
+	return s.List(query, page)
+}
+
+func (s *stubPartService) AddLinkContext(ctx context.Context, partId int64, link string) (core.Link, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Link{}, err
+	}
+
+	return s.AddLink(partId, link)
+}
+
+func (s *stubPartService) RemoveLinkContext(ctx context.Context, partId int64, linkId int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.RemoveLink(partId, linkId)
+}
+
+func (s *stubPartService) NewContext(ctx context.Context, name string, kind core.PartType) (core.Part, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Part{}, err
+	}
+
+	return s.New(name, kind)
+}
+
+func (s *stubPartService) UpdateContext(ctx context.Context, partId int64, name string, kind core.PartType) (core.Part, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Part{}, err
+	}
+
+	return s.Update(partId, name, kind)
+}
+
+func (s *stubPartService) PatchContext(ctx context.Context, partId int64, patch core.PartPatch) (core.Part, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Part{}, err
+	}
+
+	return s.Patch(partId, patch)
+}
+
+func (s *stubPartService) DeleteContext(ctx context.Context, partId int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.Delete(partId)
+}
+
+func (s *stubPartService) FindDuplicatesContext(ctx context.Context) ([][]core.Part, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.FindDuplicates()
+}
+
+func (s *stubPartService) MergeContext(ctx context.Context, partId int64, duplicateId int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.Merge(partId, duplicateId)
+}
+
+func (s *stubKitService) GetAllContext(ctx context.Context) ([]core.Kit, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.GetAll()
+}
+
+func (s *stubKitService) ListContext(ctx context.Context, page core.PageRequest) (core.KitPage, error) {
+	if err := ctx.Err(); err != nil {
+		return core.KitPage{}, err
+	}
+
+	return s.List(page)
+}
+
+func (s *stubKitService) GetContext(ctx context.Context, kitId int64) (core.Kit, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Kit{}, err
+	}
+
+	return s.Get(kitId)
+}
+
+func (s *stubKitService) AddLinkContext(ctx context.Context, kitId int64, link string) (core.Link, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Link{}, err
+	}
+
+	return s.AddLink(kitId, link)
+}
+
+func (s *stubKitService) RemoveLinkContext(ctx context.Context, kitId int64, linkId int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.RemoveLink(kitId, linkId)
+}
+
+func (s *stubKitService) AddPartContext(ctx context.Context, kitId, partId int64, quantity uint64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.AddPart(kitId, partId, quantity)
+}
+
+func (s *stubKitService) GetPartUsageContext(ctx context.Context, partId int64) ([]int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.GetPartUsage(partId)
+}
+
+func (s *stubKitService) SetPartQuantityContext(ctx context.Context, kitId, partId int64, quantity uint64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.SetPartQuantity(kitId, partId, quantity)
+}
+
+func (s *stubKitService) SetPartDesignatorsContext(ctx context.Context, kitId, partId int64, designators []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.SetPartDesignators(kitId, partId, designators)
+}
+
+func (s *stubKitService) RemovePartContext(ctx context.Context, kitId, partId int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.RemovePart(kitId, partId)
+}
+
+func (s *stubKitService) NewContext(ctx context.Context, name, schematic, diagram string) (core.Kit, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Kit{}, err
+	}
+
+	return s.New(name, schematic, diagram)
+}
+
+func (s *stubKitService) UpdateContext(ctx context.Context, kitId int64, name, schematic, diagram string) (core.Kit, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Kit{}, err
+	}
+
+	return s.Update(kitId, name, schematic, diagram)
+}
+
+func (s *stubKitService) PatchContext(ctx context.Context, kitId int64, patch core.KitPatch) (core.Kit, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Kit{}, err
+	}
+
+	return s.Patch(kitId, patch)
+}
+
+func (s *stubKitService) DeleteContext(ctx context.Context, kitId int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.Delete(kitId)
+}
+
+func (s *stubKitService) PlanContext(ctx context.Context, builds []core.KitBuild, onHand map[int64]uint64) (core.Plan, error) {
+	if err := ctx.Err(); err != nil {
+		return core.Plan{}, err
+	}
+
+	return s.Plan(builds, onHand)
+}
+
+func (s *stubKitService) ImportContext(ctx context.Context, spec core.KitSpec) (core.KitImport, error) {
+	if err := ctx.Err(); err != nil {
+		return core.KitImport{}, err
+	}
+
+	return s.Import(spec)
+}
@@ -0,0 +1,533 @@
+package servicetest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+	"github.com/sombrerosheep/partsbundler/pkg/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// runChanges runs the cases which change the service and check the
+// result, starting each from an empty service.
+func runChanges(t *testing.T, newService Factory) {
+	t.Run("Parts", func(t *testing.T) {
+		t.Run("New should save the part with a normalized value", func(t *testing.T) {
+			svc := newService(t)
+
+			part, err := svc.Parts.New("4K7", core.Resistor)
+			assert.Nil(t, err)
+			assert.Equal(t, core.Part{ID: part.ID, Name: "4K7", Kind: core.Resistor, Value: "4.7k", Links: []core.Link{}}, part)
+
+			saved, err := svc.Parts.Get(part.ID)
+			assert.Nil(t, err)
+			assert.Equal(t, part, saved)
+
+			all, err := svc.Parts.GetAll()
+			assert.Nil(t, err)
+			assert.Equal(t, []core.Part{part}, all)
+		})
+
+		t.Run("Update and Patch should return the changed part", func(t *testing.T) {
+			svc := newService(t)
+			part, _ := svc.Parts.New("1k", core.Resistor)
+			link, _ := svc.Parts.AddLink(part.ID, "example.com/1k")
+
+			part, err := svc.Parts.Update(part.ID, "100nF", core.Capacitor)
+			assert.Nil(t, err)
+			assert.Equal(t, core.Part{ID: part.ID, Name: "100nF", Kind: core.Capacitor, Value: "100nF", Links: []core.Link{link}}, part)
+
+			name := "0.1uF"
+			patched, err := svc.Parts.Patch(part.ID, core.PartPatch{Name: &name})
+			assert.Nil(t, err)
+			assert.EqualValues(t, core.Capacitor, patched.Kind)
+			assert.Equal(t, core.NormalizeValue(core.Capacitor, name), patched.Value)
+		})
+
+		t.Run("RemoveLink should remove only the part's link", func(t *testing.T) {
+			svc := newService(t)
+			one, _ := svc.Parts.New("1k", core.Resistor)
+			two, _ := svc.Parts.New("2k", core.Resistor)
+			link, _ := svc.Parts.AddLink(one.ID, "example.com/shared")
+			kept, _ := svc.Parts.AddLink(two.ID, "example.com/shared")
+
+			err := svc.Parts.RemoveLink(two.ID, link.ID)
+			assert.Equal(t, core.LinkNotFound{LinkID: link.ID, OwnerID: two.ID}, err)
+
+			err = svc.Parts.RemoveLink(one.ID, link.ID)
+			assert.Nil(t, err)
+
+			err = svc.Parts.RemoveLink(one.ID, link.ID)
+			assert.Equal(t, core.LinkNotFound{LinkID: link.ID, OwnerID: one.ID}, err)
+
+			one, _ = svc.Parts.Get(one.ID)
+			two, _ = svc.Parts.Get(two.ID)
+			assert.Equal(t, []core.Link{}, one.Links)
+			assert.Equal(t, []core.Link{kept}, two.Links)
+		})
+
+		t.Run("Delete should remove the part and its stock", func(t *testing.T) {
+			svc := newService(t)
+			part, _ := svc.Parts.New("1k", core.Resistor)
+			svc.Inventory.Adjust(part.ID, core.Received, 10, "")
+
+			err := svc.Parts.Delete(part.ID)
+			assert.Nil(t, err)
+
+			_, err = svc.Parts.Get(part.ID)
+			assert.Equal(t, core.PartNotFound{PartID: part.ID}, err)
+
+			err = svc.Parts.Delete(part.ID)
+			assert.Equal(t, core.PartNotFound{PartID: part.ID}, err)
+
+			stock, err := svc.Inventory.GetAll()
+			assert.Nil(t, err)
+			assert.Empty(t, stock)
+		})
+
+		t.Run("Merge should fold the duplicate into the part", func(t *testing.T) {
+			svc := newService(t)
+			part, _ := svc.Parts.New("10k", core.Resistor)
+			duplicate, _ := svc.Parts.New("10K", core.Resistor)
+			kept, _ := svc.Parts.AddLink(part.ID, "example.com/10k")
+			svc.Parts.AddLink(duplicate.ID, "example.com/10k")
+			moved, _ := svc.Parts.AddLink(duplicate.ID, "example.com/10k-alt")
+
+			shared, _ := svc.Kits.New("Shared", "", "")
+			svc.Kits.AddPart(shared.ID, part.ID, 2)
+			svc.Kits.AddPart(shared.ID, duplicate.ID, 3)
+
+			other, _ := svc.Kits.New("Other", "", "")
+			svc.Kits.AddPart(other.ID, duplicate.ID, 1)
+
+			svc.Inventory.Adjust(part.ID, core.Received, 4, "")
+			svc.Inventory.Adjust(duplicate.ID, core.Received, 6, "")
+
+			dupes, err := svc.Parts.FindDuplicates()
+			assert.Nil(t, err)
+			assert.Len(t, dupes, 1)
+
+			err = svc.Parts.Merge(part.ID, duplicate.ID)
+			assert.Nil(t, err)
+
+			_, err = svc.Parts.Get(duplicate.ID)
+			assert.Equal(t, core.PartNotFound{PartID: duplicate.ID}, err)
+
+			part, _ = svc.Parts.Get(part.ID)
+			assert.Equal(t, []core.Link{kept, moved}, part.Links)
+
+			shared, _ = svc.Kits.Get(shared.ID)
+			assert.Equal(t, []core.KitPart{{Part: part, Quantity: 5}}, shared.Parts)
+
+			other, _ = svc.Kits.Get(other.ID)
+			assert.Equal(t, []core.KitPart{{Part: part, Quantity: 1}}, other.Parts)
+
+			stock, _ := svc.Inventory.Get(part.ID)
+			assert.Equal(t, uint64(10), stock.Quantity)
+
+			history, _ := svc.Inventory.GetHistory(part.ID)
+			assert.Len(t, history, 2)
+
+			dupes, _ = svc.Parts.FindDuplicates()
+			assert.Empty(t, dupes)
+		})
+
+		t.Run("Merge should combine the designators of a kit using both parts", func(t *testing.T) {
+			svc := newService(t)
+			part, _ := svc.Parts.New("10k", core.Resistor)
+			duplicate, _ := svc.Parts.New("10K", core.Resistor)
+			kit, _ := svc.Kits.New("Fuzz", "", "")
+			svc.Kits.AddPart(kit.ID, part.ID, 2)
+			svc.Kits.SetPartDesignators(kit.ID, part.ID, []string{"R1", "R2"})
+			svc.Kits.AddPart(kit.ID, duplicate.ID, 1)
+			svc.Kits.SetPartDesignators(kit.ID, duplicate.ID, []string{"R3"})
+
+			err := svc.Parts.Merge(part.ID, duplicate.ID)
+			assert.Nil(t, err)
+
+			kit, _ = svc.Kits.Get(kit.ID)
+			if assert.Len(t, kit.Parts, 1) {
+				assert.Equal(t, uint64(3), kit.Parts[0].Quantity)
+				assert.ElementsMatch(t, []string{"R1", "R2", "R3"}, kit.Parts[0].Designators)
+			}
+		})
+
+		t.Run("Merge should refuse to leave a kit with fewer designators than parts", func(t *testing.T) {
+			svc := newService(t)
+			part, _ := svc.Parts.New("10k", core.Resistor)
+			duplicate, _ := svc.Parts.New("10K", core.Resistor)
+			kit, _ := svc.Kits.New("Fuzz", "", "")
+			svc.Kits.AddPart(kit.ID, part.ID, 2)
+			svc.Kits.SetPartDesignators(kit.ID, part.ID, []string{"R1", "R2"})
+			svc.Kits.AddPart(kit.ID, duplicate.ID, 1)
+
+			err := svc.Parts.Merge(part.ID, duplicate.ID)
+			assert.Equal(t, core.DesignatorMismatch{KitID: kit.ID, PartID: part.ID, Designators: 2, Quantity: 3}, err)
+
+			_, err = svc.Parts.Get(duplicate.ID)
+			assert.Nil(t, err)
+
+			kit, _ = svc.Kits.Get(kit.ID)
+			assert.Len(t, kit.Parts, 2)
+		})
+
+		t.Run("Merge should refuse a part of the same kind with another value", func(t *testing.T) {
+			svc := newService(t)
+			part, _ := svc.Parts.New("10k", core.Resistor)
+			other, _ := svc.Parts.New("1k", core.Resistor)
+			kit, _ := svc.Kits.New("Fuzz", "", "")
+			svc.Kits.AddPart(kit.ID, other.ID, 1)
+
+			err := svc.Parts.Merge(part.ID, other.ID)
+			assert.Equal(t, core.CannotMergeParts{PartID: part.ID, DuplicateID: other.ID}, err)
+
+			kit, _ = svc.Kits.Get(kit.ID)
+			assert.Equal(t, []core.KitPart{{Part: other, Quantity: 1}}, kit.Parts)
+		})
+
+		t.Run("List should filter, sort and page parts", func(t *testing.T) {
+			svc := newService(t)
+			small, _ := svc.Parts.New("1k", core.Resistor)
+			large, _ := svc.Parts.New("100k", core.Resistor)
+			middle, _ := svc.Parts.New("10k", core.Resistor)
+			svc.Parts.New("TL072", core.IC)
+
+			page, err := svc.Parts.List(core.PartQuery{Kind: core.Resistor}, core.PageRequest{Sort: core.SortByValue, Offset: 1, Limit: 1})
+			assert.Nil(t, err)
+			assert.Equal(t, core.PartPage{Parts: []core.Part{middle}, Total: 3, Offset: 1, Limit: 1}, page)
+
+			parts, err := svc.Parts.Find(core.PartQuery{Kind: core.Resistor, Min: "5k"})
+			assert.Nil(t, err)
+			assert.Equal(t, []core.Part{large, middle}, parts)
+
+			parts, err = svc.Parts.Find(core.PartQuery{Text: "10"})
+			assert.Nil(t, err)
+			assert.Equal(t, []core.Part{large, middle}, parts)
+
+			unused := false
+			parts, err = svc.Parts.Find(core.PartQuery{Kind: core.Resistor, InUse: &unused})
+			assert.Nil(t, err)
+			assert.Equal(t, []core.Part{small, large, middle}, parts)
+		})
+	})
+
+	t.Run("Kits", func(t *testing.T) {
+		t.Run("New should save the kit without parts or links", func(t *testing.T) {
+			svc := newService(t)
+
+			kit, err := svc.Kits.New("Fuzz", "example.com/fuzz", "")
+			assert.Nil(t, err)
+			assert.Equal(t, core.Kit{ID: kit.ID, Name: "Fuzz", Schematic: "example.com/fuzz", Parts: []core.KitPart{}, Links: []core.Link{}}, kit)
+
+			saved, err := svc.Kits.Get(kit.ID)
+			assert.Nil(t, err)
+			assert.Equal(t, kit, saved)
+		})
+
+		t.Run("AddPart should add the part with its links", func(t *testing.T) {
+			svc := newService(t)
+			part, _ := svc.Parts.New("1k", core.Resistor)
+			svc.Parts.AddLink(part.ID, "example.com/1k")
+			part, _ = svc.Parts.Get(part.ID)
+			kit, _ := svc.Kits.New("Fuzz", "", "")
+
+			err := svc.Kits.AddPart(kit.ID, missingId, 2)
+			assert.Equal(t, core.PartNotFound{PartID: missingId}, err)
+
+			err = svc.Kits.AddPart(kit.ID, part.ID, 2)
+			assert.Nil(t, err)
+
+			kit, _ = svc.Kits.Get(kit.ID)
+			assert.Equal(t, []core.KitPart{{Part: part, Quantity: 2}}, kit.Parts)
+		})
+
+		t.Run("GetPartUsage should list the kits using the part", func(t *testing.T) {
+			svc := newService(t)
+			part, _ := svc.Parts.New("1k", core.Resistor)
+			one, _ := svc.Kits.New("One", "", "")
+			two, _ := svc.Kits.New("Two", "", "")
+
+			_, err := svc.Kits.GetPartUsage(missingId)
+			assert.Equal(t, core.PartNotFound{PartID: missingId}, err)
+
+			usage, err := svc.Kits.GetPartUsage(part.ID)
+			assert.Nil(t, err)
+			assert.Equal(t, []int64{}, usage)
+
+			svc.Kits.AddPart(two.ID, part.ID, 1)
+			svc.Kits.AddPart(one.ID, part.ID, 1)
+
+			usage, err = svc.Kits.GetPartUsage(part.ID)
+			assert.Nil(t, err)
+			assert.ElementsMatch(t, []int64{one.ID, two.ID}, usage)
+		})
+
+		t.Run("SetPartDesignators should return PartNotFound for a missing part", func(t *testing.T) {
+			svc := newService(t)
+			kit, _ := svc.Kits.New("Fuzz", "", "")
+
+			err := svc.Kits.SetPartDesignators(kit.ID, missingId, []string{"R1"})
+			assert.Equal(t, core.PartNotFound{PartID: missingId}, err)
+		})
+
+		t.Run("SetPartDesignators should keep the quantity in step", func(t *testing.T) {
+			svc := newService(t)
+			one, _ := svc.Parts.New("1k", core.Resistor)
+			two, _ := svc.Parts.New("2k", core.Resistor)
+			kit, _ := svc.Kits.New("Fuzz", "", "")
+			svc.Kits.AddPart(kit.ID, one.ID, 1)
+			svc.Kits.AddPart(kit.ID, two.ID, 1)
+
+			err := svc.Kits.SetPartDesignators(kit.ID, one.ID, []string{"r2", "R1", "r2", " "})
+			assert.Nil(t, err)
+
+			kit, _ = svc.Kits.Get(kit.ID)
+			assert.Equal(t, core.KitPart{Part: one, Quantity: 2, Designators: []string{"R2", "R1"}}, kit.Parts[0])
+
+			err = svc.Kits.SetPartDesignators(kit.ID, two.ID, []string{"R3", "R1"})
+			assert.Equal(t, core.DesignatorInUse{KitID: kit.ID, Designator: "R1"}, err)
+
+			err = svc.Kits.SetPartQuantity(kit.ID, one.ID, 3)
+			assert.Equal(t, core.DesignatorMismatch{KitID: kit.ID, PartID: one.ID, Designators: 2, Quantity: 3}, err)
+
+			err = svc.Kits.SetPartQuantity(kit.ID, one.ID, 2)
+			assert.Nil(t, err)
+
+			err = svc.Kits.SetPartDesignators(kit.ID, one.ID, []string{})
+			assert.Nil(t, err)
+
+			err = svc.Kits.SetPartQuantity(kit.ID, one.ID, 5)
+			assert.Nil(t, err)
+
+			kit, _ = svc.Kits.Get(kit.ID)
+			assert.Equal(t, []core.KitPart{{Part: one, Quantity: 5}, {Part: two, Quantity: 1}}, kit.Parts)
+		})
+
+		t.Run("RemovePart should let the part be deleted", func(t *testing.T) {
+			svc := newService(t)
+			part, _ := svc.Parts.New("1k", core.Resistor)
+			kit, _ := svc.Kits.New("Fuzz", "", "")
+			svc.Kits.AddPart(kit.ID, part.ID, 1)
+
+			err := svc.Kits.RemovePart(kit.ID, part.ID)
+			assert.Nil(t, err)
+
+			kit, _ = svc.Kits.Get(kit.ID)
+			assert.Equal(t, []core.KitPart{}, kit.Parts)
+
+			err = svc.Parts.Delete(part.ID)
+			assert.Nil(t, err)
+		})
+
+		t.Run("Delete should remove the kit but not its parts", func(t *testing.T) {
+			svc := newService(t)
+			part, _ := svc.Parts.New("1k", core.Resistor)
+			kit, _ := svc.Kits.New("Fuzz", "", "")
+			svc.Kits.AddLink(kit.ID, "example.com/fuzz")
+			svc.Kits.AddPart(kit.ID, part.ID, 1)
+
+			err := svc.Kits.Delete(kit.ID)
+			assert.Nil(t, err)
+
+			_, err = svc.Kits.Get(kit.ID)
+			assert.Equal(t, core.KitNotFound{KitID: kit.ID}, err)
+
+			usage, err := svc.Kits.GetPartUsage(part.ID)
+			assert.Nil(t, err)
+			assert.Equal(t, []int64{}, usage)
+		})
+
+		t.Run("Update and Patch should return the changed kit", func(t *testing.T) {
+			svc := newService(t)
+			kit, _ := svc.Kits.New("Fuzz", "", "")
+			link, _ := svc.Kits.AddLink(kit.ID, "example.com/fuzz")
+
+			kit, err := svc.Kits.Update(kit.ID, "Big Fuzz", "example.com/schematic", "")
+			assert.Nil(t, err)
+			assert.Equal(t, core.Kit{ID: kit.ID, Name: "Big Fuzz", Schematic: "example.com/schematic", Parts: []core.KitPart{}, Links: []core.Link{link}}, kit)
+
+			diagram := "example.com/diagram"
+			patched, err := svc.Kits.Patch(kit.ID, core.KitPatch{Diagram: &diagram})
+			assert.Nil(t, err)
+			assert.Equal(t, "Big Fuzz", patched.Name)
+			assert.Equal(t, diagram, patched.Diagram)
+		})
+
+		t.Run("List should sort and page kits", func(t *testing.T) {
+			svc := newService(t)
+			part, _ := svc.Parts.New("1k", core.Resistor)
+			fuzz, _ := svc.Kits.New("Fuzz", "", "")
+			boost, _ := svc.Kits.New("Boost", "", "")
+			svc.Kits.AddPart(fuzz.ID, part.ID, 1)
+
+			page, err := svc.Kits.List(core.PageRequest{Sort: core.SortByName})
+			assert.Nil(t, err)
+			assert.Equal(t, 2, page.Total)
+			assert.Equal(t, []int64{boost.ID, fuzz.ID}, kitIds(page.Kits))
+
+			page, err = svc.Kits.List(core.PageRequest{Sort: core.SortByPartCount, Desc: true, Limit: 1})
+			assert.Nil(t, err)
+			assert.Equal(t, []int64{fuzz.ID}, kitIds(page.Kits))
+		})
+
+		t.Run("Import should match existing parts and create the rest", func(t *testing.T) {
+			svc := newService(t)
+			existing, _ := svc.Parts.New("10k", core.Resistor)
+
+			result, err := svc.Kits.Import(core.KitSpec{
+				Name:  "Fuzz",
+				Links: []string{"example.com/fuzz"},
+				Parts: []core.KitPartSpec{
+					{Kind: core.Resistor, Name: "10K", Designators: []string{"R1", "R2"}, Links: []string{"example.com/10k"}},
+					{Kind: core.IC, Name: "TL072", Quantity: 1},
+				},
+			})
+			assert.Nil(t, err)
+
+			existing, _ = svc.Parts.Get(existing.ID)
+			assert.Equal(t, []core.Part{existing}, result.Matched)
+			assert.Len(t, existing.Links, 1)
+
+			if assert.Len(t, result.Created, 1) {
+				created := result.Created[0]
+				assert.Equal(t, core.Part{ID: created.ID, Name: "TL072", Kind: core.IC, Value: "TL072", Links: []core.Link{}}, created)
+
+				kit, _ := svc.Kits.Get(result.Kit.ID)
+				assert.Equal(t, kit, result.Kit)
+				assert.Equal(t, []core.KitPart{
+					{Part: existing, Quantity: 2, Designators: []string{"R1", "R2"}},
+					{Part: created, Quantity: 1},
+				}, kit.Parts)
+			}
+		})
+
+		t.Run("Plan should use the kits' parts", func(t *testing.T) {
+			svc := newService(t)
+			part, _ := svc.Parts.New("1k", core.Resistor)
+			kit, _ := svc.Kits.New("Fuzz", "", "")
+			svc.Kits.AddPart(kit.ID, part.ID, 2)
+			kit, _ = svc.Kits.Get(kit.ID)
+
+			builds := []core.KitBuild{{KitID: kit.ID, Count: 3}}
+			onHand := map[int64]uint64{part.ID: 1}
+
+			plan, err := svc.Kits.Plan(builds, onHand)
+			assert.Nil(t, err)
+
+			expected, _ := core.NewPlan(builds, map[int64]core.Kit{kit.ID: kit}, onHand)
+			assert.Equal(t, expected, plan)
+		})
+	})
+
+	t.Run("Inventory", func(t *testing.T) {
+		t.Run("Adjust should change the stock and record it", func(t *testing.T) {
+			svc := newService(t)
+			part, _ := svc.Parts.New("1k", core.Resistor)
+
+			stock, err := svc.Inventory.SetLocation(part.ID, "drawer A1")
+			assert.Nil(t, err)
+			assert.Equal(t, core.Stock{PartID: part.ID, Location: "drawer A1"}, stock)
+
+			stock, err = svc.Inventory.Adjust(part.ID, core.Received, 10, "order")
+			assert.Nil(t, err)
+			assert.Equal(t, core.Stock{PartID: part.ID, Quantity: 10, Location: "drawer A1"}, stock)
+
+			_, err = svc.Inventory.Adjust(part.ID, core.Lost, 11, "")
+			assert.Equal(t, core.InsufficientStock{PartID: part.ID, Available: 10, Requested: 11}, err)
+
+			stock, err = svc.Inventory.Adjust(part.ID, core.Counted, 7, "")
+			assert.Nil(t, err)
+			assert.Equal(t, uint64(7), stock.Quantity)
+
+			history, err := svc.Inventory.GetHistory(part.ID)
+			assert.Nil(t, err)
+			if assert.Len(t, history, 2) {
+				assert.EqualValues(t, core.Received, history[0].Kind)
+				assert.Equal(t, "order", history[0].Note)
+				assert.EqualValues(t, core.Counted, history[1].Kind)
+				assert.Equal(t, uint64(7), history[1].Quantity)
+			}
+
+			all, err := svc.Inventory.GetAll()
+			assert.Nil(t, err)
+			assert.Equal(t, []core.Stock{stock}, all)
+		})
+
+		t.Run("GetHistory should be empty for a part never adjusted", func(t *testing.T) {
+			svc := newService(t)
+			part, _ := svc.Parts.New("1k", core.Resistor)
+
+			history, err := svc.Inventory.GetHistory(part.ID)
+
+			assert.Nil(t, err)
+			assert.Equal(t, []core.StockAdjustment{}, history)
+		})
+	})
+
+	t.Run("Atomic", func(t *testing.T) {
+		t.Run("should keep the changes of fn when it succeeds", func(t *testing.T) {
+			svc := newService(t)
+			skipUnlessAtomic(t, svc)
+
+			var kit core.Kit
+			err := svc.Atomic(func(tx *service.BundlerService) (err error) {
+				kit, err = tx.Kits.New("Fuzz", "", "")
+
+				return err
+			})
+			assert.Nil(t, err)
+
+			_, err = svc.Kits.Get(kit.ID)
+			assert.Nil(t, err)
+		})
+
+		t.Run("should drop the changes of fn when it fails", func(t *testing.T) {
+			svc := newService(t)
+			if svc.Transactor == nil {
+				t.Skip("the service has no Transactor")
+			}
+			skipUnlessAtomic(t, svc)
+
+			failed := errors.New("failed")
+			err := svc.Atomic(func(tx *service.BundlerService) error {
+				kit, err := tx.Kits.New("Fuzz", "", "")
+				if err != nil {
+					return err
+				}
+
+				err = tx.Kits.AddPart(kit.ID, missingId, 1)
+				assert.Equal(t, core.PartNotFound{PartID: missingId}, err)
+
+				return failed
+			})
+			assert.Equal(t, failed, err)
+
+			kits, err := svc.Kits.GetAll()
+			assert.Nil(t, err)
+			assert.Empty(t, kits)
+		})
+	})
+}
+
+// skipUnlessAtomic skips the test when the service's Transactor reports
+// that it cannot run a unit of work atomically.
+func skipUnlessAtomic(t *testing.T, svc *service.BundlerService) {
+	t.Helper()
+
+	err := svc.Atomic(func(tx *service.BundlerService) error {
+		return nil
+	})
+	if _, ok := err.(service.AtomicUnsupported); ok {
+		t.Skip(err)
+	}
+}
+
+func kitIds(kits []core.Kit) []int64 {
+	ids := make([]int64, len(kits))
+	for i, kit := range kits {
+		ids[i] = kit.ID
+	}
+
+	return ids
+}
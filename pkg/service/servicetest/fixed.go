@@ -0,0 +1,444 @@
+package servicetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+// runFixed runs the cases which only read the fixture or expect a call
+// to fail, so they hold for services that keep no changes.
+func runFixed(t *testing.T, setup func(t *testing.T) fixture) {
+	t.Run("Parts", func(t *testing.T) {
+		t.Run("Get should return PartNotFound for a missing part", func(t *testing.T) {
+			f := setup(t)
+
+			_, err := f.svc.Parts.Get(missingId)
+
+			assert.Equal(t, core.PartNotFound{PartID: missingId}, err)
+		})
+
+		t.Run("Get should return the part with its links", func(t *testing.T) {
+			f := setup(t)
+
+			part, err := f.svc.Parts.Get(f.part.ID)
+
+			assert.Nil(t, err)
+			assert.Equal(t, f.part, part)
+			assert.NotNil(t, part.Links)
+		})
+
+		t.Run("New should validate the part before saving it", func(t *testing.T) {
+			f := setup(t)
+
+			_, err := f.svc.Parts.New("", core.Resistor)
+			assert.IsType(t, core.ValidationError{}, err)
+
+			_, err = f.svc.Parts.New("1k", "Widget")
+			assert.Equal(t, core.InvalidPartType{InvalidType: "Widget"}, err)
+		})
+
+		t.Run("Update and Patch should return PartNotFound for a missing part", func(t *testing.T) {
+			f := setup(t)
+
+			_, err := f.svc.Parts.Update(missingId, "1k", core.Resistor)
+			assert.Equal(t, core.PartNotFound{PartID: missingId}, err)
+
+			_, err = f.svc.Parts.Patch(missingId, core.PartPatch{})
+			assert.Equal(t, core.PartNotFound{PartID: missingId}, err)
+		})
+
+		t.Run("Update should validate before looking up the part", func(t *testing.T) {
+			f := setup(t)
+
+			_, err := f.svc.Parts.Update(missingId, "", core.Resistor)
+
+			assert.IsType(t, core.ValidationError{}, err)
+		})
+
+		t.Run("AddLink should validate before looking up the part", func(t *testing.T) {
+			f := setup(t)
+
+			_, err := f.svc.Parts.AddLink(missingId, "not a url")
+			assert.IsType(t, core.ValidationError{}, err)
+
+			_, err = f.svc.Parts.AddLink(missingId, "example.com/part")
+			assert.Equal(t, core.PartNotFound{PartID: missingId}, err)
+		})
+
+		t.Run("RemoveLink should return LinkNotFound for a link the part does not have", func(t *testing.T) {
+			f := setup(t)
+
+			err := f.svc.Parts.RemoveLink(f.part.ID, missingId)
+			assert.Equal(t, core.LinkNotFound{LinkID: missingId, OwnerID: f.part.ID}, err)
+
+			err = f.svc.Parts.RemoveLink(missingId, missingId)
+			assert.Equal(t, core.PartNotFound{PartID: missingId}, err)
+		})
+
+		t.Run("RemoveLink should not remove the link of another part", func(t *testing.T) {
+			f := setup(t)
+
+			link, ok := linkOnlyOn(f.other, f.part)
+			if !ok {
+				t.Skip("no link belongs only to the other part")
+			}
+
+			err := f.svc.Parts.RemoveLink(f.part.ID, link.ID)
+			assert.Equal(t, core.LinkNotFound{LinkID: link.ID, OwnerID: f.part.ID}, err)
+
+			other, err := f.svc.Parts.Get(f.other.ID)
+			assert.Nil(t, err)
+			assert.Contains(t, other.Links, link)
+		})
+
+		t.Run("Delete should not delete a part used by a kit", func(t *testing.T) {
+			f := setup(t)
+
+			err := f.svc.Parts.Delete(f.part.ID)
+			assert.Equal(t, core.PartInUse{PartID: f.part.ID}, err)
+
+			_, err = f.svc.Parts.Get(f.part.ID)
+			assert.Nil(t, err)
+		})
+
+		t.Run("Merge should refuse to merge a part into itself or another kind", func(t *testing.T) {
+			f := setup(t)
+
+			err := f.svc.Parts.Merge(f.part.ID, f.part.ID)
+			assert.Equal(t, core.CannotMergeParts{PartID: f.part.ID, DuplicateID: f.part.ID}, err)
+
+			err = f.svc.Parts.Merge(missingId, missingId)
+			assert.Equal(t, core.CannotMergeParts{PartID: missingId, DuplicateID: missingId}, err)
+
+			err = f.svc.Parts.Merge(f.part.ID, f.other.ID)
+			assert.Equal(t, core.CannotMergeParts{PartID: f.part.ID, DuplicateID: f.other.ID}, err)
+		})
+
+		t.Run("Merge should look up the part before the duplicate", func(t *testing.T) {
+			f := setup(t)
+
+			err := f.svc.Parts.Merge(missingId, f.part.ID)
+			assert.Equal(t, core.PartNotFound{PartID: missingId}, err)
+
+			err = f.svc.Parts.Merge(f.part.ID, missingId)
+			assert.Equal(t, core.PartNotFound{PartID: missingId}, err)
+		})
+
+		t.Run("List should reject invalid queries and pages", func(t *testing.T) {
+			f := setup(t)
+
+			_, err := f.svc.Parts.List(core.PartQuery{Min: "1k"}, core.PageRequest{})
+			assert.IsType(t, core.InvalidPartQuery{}, err)
+
+			_, err = f.svc.Parts.List(core.PartQuery{Kind: "Widget"}, core.PageRequest{})
+			assert.Equal(t, core.InvalidPartType{InvalidType: "Widget"}, err)
+
+			_, err = f.svc.Parts.List(core.PartQuery{}, core.PageRequest{Sort: "color"})
+			assert.IsType(t, core.InvalidPageRequest{}, err)
+		})
+
+		t.Run("List should count every matching part", func(t *testing.T) {
+			f := setup(t)
+
+			all, err := f.svc.Parts.GetAll()
+			assert.Nil(t, err)
+
+			page, err := f.svc.Parts.List(core.PartQuery{}, core.PageRequest{Limit: 1})
+			assert.Nil(t, err)
+			assert.Equal(t, len(all), page.Total)
+			assert.Len(t, page.Parts, 1)
+
+			page, err = f.svc.Parts.List(core.PartQuery{}, core.PageRequest{Offset: len(all)})
+			assert.Nil(t, err)
+			assert.Equal(t, []core.Part{}, page.Parts)
+		})
+
+		t.Run("Find should match parts by kind and use", func(t *testing.T) {
+			f := setup(t)
+
+			parts, err := f.svc.Parts.Find(core.PartQuery{Kind: f.part.Kind})
+			assert.Nil(t, err)
+			assert.Contains(t, parts, f.part)
+			assert.NotContains(t, parts, f.other)
+
+			used := true
+			parts, err = f.svc.Parts.Find(core.PartQuery{InUse: &used})
+			assert.Nil(t, err)
+			assert.Contains(t, parts, f.part)
+			assert.NotContains(t, parts, f.other)
+		})
+	})
+
+	t.Run("Kits", func(t *testing.T) {
+		t.Run("Get should return KitNotFound for a missing kit", func(t *testing.T) {
+			f := setup(t)
+
+			_, err := f.svc.Kits.Get(missingId)
+
+			assert.Equal(t, core.KitNotFound{KitID: missingId}, err)
+		})
+
+		t.Run("Get should return the kit with its parts and links", func(t *testing.T) {
+			f := setup(t)
+
+			kit, err := f.svc.Kits.Get(f.kit.ID)
+
+			assert.Nil(t, err)
+			assert.Equal(t, f.kit, kit)
+			assert.NotNil(t, kit.Links)
+			assert.True(t, usesPart(kit, f.part.ID))
+		})
+
+		t.Run("New should validate the kit before saving it", func(t *testing.T) {
+			f := setup(t)
+
+			_, err := f.svc.Kits.New("", "", "")
+			assert.IsType(t, core.ValidationError{}, err)
+
+			_, err = f.svc.Kits.New("Fuzz", "not a url", "")
+			assert.IsType(t, core.ValidationError{}, err)
+		})
+
+		t.Run("Update and Patch should return KitNotFound for a missing kit", func(t *testing.T) {
+			f := setup(t)
+
+			_, err := f.svc.Kits.Update(missingId, "Fuzz", "", "")
+			assert.Equal(t, core.KitNotFound{KitID: missingId}, err)
+
+			_, err = f.svc.Kits.Patch(missingId, core.KitPatch{})
+			assert.Equal(t, core.KitNotFound{KitID: missingId}, err)
+		})
+
+		t.Run("Delete should ignore a missing kit", func(t *testing.T) {
+			f := setup(t)
+
+			err := f.svc.Kits.Delete(missingId)
+
+			assert.Nil(t, err)
+		})
+
+		t.Run("AddLink and RemoveLink should return KitNotFound for a missing kit", func(t *testing.T) {
+			f := setup(t)
+
+			_, err := f.svc.Kits.AddLink(missingId, "example.com/kit")
+			assert.Equal(t, core.KitNotFound{KitID: missingId}, err)
+
+			err = f.svc.Kits.RemoveLink(missingId, missingId)
+			assert.Equal(t, core.KitNotFound{KitID: missingId}, err)
+		})
+
+		t.Run("RemoveLink should return KitLinkNotFound for a link the kit does not have", func(t *testing.T) {
+			f := setup(t)
+
+			err := f.svc.Kits.RemoveLink(f.kit.ID, missingId)
+
+			assert.Equal(t, core.KitLinkNotFound{LinkID: missingId, KitID: f.kit.ID}, err)
+		})
+
+		t.Run("AddPart should refuse a part the kit already uses", func(t *testing.T) {
+			f := setup(t)
+
+			err := f.svc.Kits.AddPart(f.kit.ID, f.part.ID, 1)
+			assert.Equal(t, core.PartAlreadyInKit{KitID: f.kit.ID, PartID: f.part.ID}, err)
+
+			kit, err := f.svc.Kits.Get(f.kit.ID)
+			assert.Nil(t, err)
+			assert.Equal(t, f.kit.Parts, kit.Parts)
+		})
+
+		t.Run("AddPart should check the quantity before the kit", func(t *testing.T) {
+			f := setup(t)
+
+			err := f.svc.Kits.AddPart(missingId, missingId, 0)
+			assert.IsType(t, core.ValidationError{}, err)
+
+			err = f.svc.Kits.AddPart(missingId, missingId, 1)
+			assert.Equal(t, core.KitNotFound{KitID: missingId}, err)
+		})
+
+		t.Run("GetPartUsage should list the kits using the part", func(t *testing.T) {
+			f := setup(t)
+
+			usage, err := f.svc.Kits.GetPartUsage(f.part.ID)
+			assert.Nil(t, err)
+			assert.Contains(t, usage, f.kit.ID)
+
+			usage, err = f.svc.Kits.GetPartUsage(f.other.ID)
+			assert.Nil(t, err)
+			assert.NotNil(t, usage)
+			assert.NotContains(t, usage, f.kit.ID)
+		})
+
+		t.Run("SetPartQuantity should check the quantity, part and kit in turn", func(t *testing.T) {
+			f := setup(t)
+
+			err := f.svc.Kits.SetPartQuantity(missingId, missingId, 0)
+			assert.IsType(t, core.ValidationError{}, err)
+
+			err = f.svc.Kits.SetPartQuantity(missingId, missingId, 1)
+			assert.Equal(t, core.PartNotFound{PartID: missingId}, err)
+
+			err = f.svc.Kits.SetPartQuantity(missingId, f.part.ID, 1)
+			assert.Equal(t, core.KitNotFound{KitID: missingId}, err)
+		})
+
+		t.Run("SetPartQuantity should ignore a part the kit does not use", func(t *testing.T) {
+			f := setup(t)
+
+			err := f.svc.Kits.SetPartQuantity(f.kit.ID, f.other.ID, 1)
+			assert.Nil(t, err)
+
+			kit, err := f.svc.Kits.Get(f.kit.ID)
+			assert.Nil(t, err)
+			assert.False(t, usesPart(kit, f.other.ID))
+		})
+
+		t.Run("SetPartDesignators should check the kit and kit part", func(t *testing.T) {
+			f := setup(t)
+
+			err := f.svc.Kits.SetPartDesignators(missingId, missingId, []string{"R1"})
+			assert.Equal(t, core.KitNotFound{KitID: missingId}, err)
+
+			err = f.svc.Kits.SetPartDesignators(f.kit.ID, f.other.ID, []string{"R1"})
+			assert.Equal(t, core.PartNotInKit{KitID: f.kit.ID, PartID: f.other.ID}, err)
+		})
+
+		t.Run("RemovePart should return PartNotInKit for a part the kit does not use", func(t *testing.T) {
+			f := setup(t)
+
+			err := f.svc.Kits.RemovePart(f.kit.ID, f.other.ID)
+			assert.Equal(t, core.PartNotInKit{KitID: f.kit.ID, PartID: f.other.ID}, err)
+
+			err = f.svc.Kits.RemovePart(f.kit.ID, missingId)
+			assert.Equal(t, core.PartNotInKit{KitID: f.kit.ID, PartID: missingId}, err)
+
+			err = f.svc.Kits.RemovePart(missingId, f.part.ID)
+			assert.Equal(t, core.KitNotFound{KitID: missingId}, err)
+		})
+
+		t.Run("List should reject unknown sort keys", func(t *testing.T) {
+			f := setup(t)
+
+			_, err := f.svc.Kits.List(core.PageRequest{Sort: core.SortByValue})
+
+			assert.IsType(t, core.InvalidPageRequest{}, err)
+		})
+
+		t.Run("List should count every kit", func(t *testing.T) {
+			f := setup(t)
+
+			all, err := f.svc.Kits.GetAll()
+			assert.Nil(t, err)
+
+			page, err := f.svc.Kits.List(core.PageRequest{Limit: 1})
+			assert.Nil(t, err)
+			assert.Equal(t, len(all), page.Total)
+			assert.Len(t, page.Kits, 1)
+		})
+
+		t.Run("Plan should return KitNotFound for a missing kit", func(t *testing.T) {
+			f := setup(t)
+
+			_, err := f.svc.Kits.Plan([]core.KitBuild{{KitID: missingId, Count: 1}}, nil)
+
+			assert.Equal(t, core.KitNotFound{KitID: missingId}, err)
+		})
+
+		t.Run("Import should reject an invalid spec", func(t *testing.T) {
+			f := setup(t)
+
+			_, err := f.svc.Kits.Import(core.KitSpec{})
+
+			assert.IsType(t, core.InvalidKitSpec{}, err)
+		})
+	})
+
+	t.Run("Inventory", func(t *testing.T) {
+		t.Run("should return PartNotFound for a missing part", func(t *testing.T) {
+			f := setup(t)
+
+			_, err := f.svc.Inventory.Get(missingId)
+			assert.Equal(t, core.PartNotFound{PartID: missingId}, err)
+
+			_, err = f.svc.Inventory.Adjust(missingId, core.Received, 1, "")
+			assert.Equal(t, core.PartNotFound{PartID: missingId}, err)
+
+			_, err = f.svc.Inventory.SetLocation(missingId, "drawer A1")
+			assert.Equal(t, core.PartNotFound{PartID: missingId}, err)
+
+			_, err = f.svc.Inventory.GetHistory(missingId)
+			assert.Equal(t, core.PartNotFound{PartID: missingId}, err)
+		})
+
+		t.Run("Adjust should reject unknown kinds", func(t *testing.T) {
+			f := setup(t)
+
+			_, err := f.svc.Inventory.Adjust(f.part.ID, "borrowed", 1, "")
+
+			assert.Equal(t, core.InvalidAdjustmentKind{InvalidKind: "borrowed"}, err)
+		})
+
+		t.Run("Get should return the stock of a part", func(t *testing.T) {
+			f := setup(t)
+
+			stock, err := f.svc.Inventory.Get(f.other.ID)
+
+			assert.Nil(t, err)
+			assert.Equal(t, f.other.ID, stock.PartID)
+		})
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		t.Run("should stop once the context is done", func(t *testing.T) {
+			f := setup(t)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := f.svc.Parts.GetAllContext(ctx)
+			assert.ErrorIs(t, err, context.Canceled)
+
+			_, err = f.svc.Parts.GetContext(ctx, f.part.ID)
+			assert.ErrorIs(t, err, context.Canceled)
+
+			_, err = f.svc.Kits.GetAllContext(ctx)
+			assert.ErrorIs(t, err, context.Canceled)
+
+			_, err = f.svc.Kits.NewContext(ctx, "Cancelled", "", "")
+			assert.ErrorIs(t, err, context.Canceled)
+		})
+
+		t.Run("should behave as without a context while it is live", func(t *testing.T) {
+			f := setup(t)
+			ctx := context.Background()
+
+			part, err := f.svc.Parts.GetContext(ctx, f.part.ID)
+			assert.Nil(t, err)
+			assert.Equal(t, f.part, part)
+
+			_, err = f.svc.Kits.GetContext(ctx, missingId)
+			assert.Equal(t, core.KitNotFound{KitID: missingId}, err)
+
+			assert.Nil(t, f.svc.Ping(ctx))
+		})
+	})
+}
+
+// linkOnlyOn returns a link of part that other does not have.
+func linkOnlyOn(part, other core.Part) (core.Link, bool) {
+	for _, link := range part.Links {
+		shared := false
+		for _, l := range other.Links {
+			shared = shared || l.ID == link.ID
+		}
+
+		if !shared {
+			return link, true
+		}
+	}
+
+	return core.Link{}, false
+}
@@ -0,0 +1,144 @@
+// Package servicetest is a conformance suite for implementations of the
+// bundler services. It pins down how a service.BundlerService behaves,
+// following the sqlite services, so that every implementation, and the
+// code written against them, can rely on the same errors and results.
+//
+// A backend runs the suite from one of its tests:
+//
+//	func Test_Conformance(t *testing.T) {
+//		servicetest.Run(t, func(t *testing.T) *service.BundlerService {
+//			return memory.CreateMemoryService()
+//		})
+//	}
+package servicetest
+
+import (
+	"testing"
+
+	"github.com/sombrerosheep/partsbundler/pkg/core"
+	"github.com/sombrerosheep/partsbundler/pkg/service"
+)
+
+// missingId is an id no part, kit or link is expected to have.
+const missingId int64 = 1 << 40
+
+// Factory returns the service under test. It is called once for every
+// test, which may change the service it is given.
+type Factory func(t *testing.T) *service.BundlerService
+
+// fixture holds the records the cases common to Run and RunFixed work
+// with. kit uses part but not other, and other is of another kind.
+type fixture struct {
+	svc   *service.BundlerService
+	kit   core.Kit
+	part  core.Part
+	other core.Part
+}
+
+// Run checks the services made by newService. Each service must start
+// empty and keep the changes made to it.
+func Run(t *testing.T, newService Factory) {
+	runFixed(t, func(t *testing.T) fixture {
+		return createFixture(t, newService(t))
+	})
+
+	t.Run("Changes", func(t *testing.T) {
+		runChanges(t, newService)
+	})
+}
+
+// RunFixed checks services which hold a fixed set of records and do not
+// keep changes, such as the stub in pkg/service/mock. Only the cases
+// which can be checked against the records the service already holds
+// are run; those which depend on the service knowing every part it
+// was given, such as PartNotFound from AddPart, are left to Run. The
+// service must hold a kit using a part, and a part of another kind
+// which that kit does not use.
+func RunFixed(t *testing.T, newService Factory) {
+	runFixed(t, func(t *testing.T) fixture {
+		return findFixture(t, newService(t))
+	})
+}
+
+// createFixture adds the fixture's records to an empty service.
+func createFixture(t *testing.T, svc *service.BundlerService) fixture {
+	t.Helper()
+
+	part, err := svc.Parts.New("10k", core.Resistor)
+	must(t, err)
+
+	_, err = svc.Parts.AddLink(part.ID, "example.com/10k")
+	must(t, err)
+
+	other, err := svc.Parts.New("TL072", core.IC)
+	must(t, err)
+
+	_, err = svc.Parts.AddLink(other.ID, "example.com/tl072")
+	must(t, err)
+
+	kit, err := svc.Kits.New("Fuzz", "example.com/fuzz", "")
+	must(t, err)
+
+	_, err = svc.Kits.AddLink(kit.ID, "example.com/fuzz/build")
+	must(t, err)
+
+	must(t, svc.Kits.AddPart(kit.ID, part.ID, 2))
+
+	kit, err = svc.Kits.Get(kit.ID)
+	must(t, err)
+
+	part, err = svc.Parts.Get(part.ID)
+	must(t, err)
+
+	other, err = svc.Parts.Get(other.ID)
+	must(t, err)
+
+	return fixture{svc: svc, kit: kit, part: part, other: other}
+}
+
+// findFixture picks the fixture's records from those the service holds.
+func findFixture(t *testing.T, svc *service.BundlerService) fixture {
+	t.Helper()
+
+	kits, err := svc.Kits.GetAll()
+	must(t, err)
+
+	parts, err := svc.Parts.GetAll()
+	must(t, err)
+
+	for _, kit := range kits {
+		if len(kit.Parts) == 0 {
+			continue
+		}
+
+		part := kit.Parts[0].Part
+
+		for _, other := range parts {
+			if other.Kind != part.Kind && !usesPart(kit, other.ID) {
+				return fixture{svc: svc, kit: kit, part: part, other: other}
+			}
+		}
+	}
+
+	t.Fatal("servicetest: the service needs a kit using a part and a part of another kind the kit does not use")
+
+	return fixture{}
+}
+
+func usesPart(kit core.Kit, partId int64) bool {
+	for _, kp := range kit.Parts {
+		if kp.Part.ID == partId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatalf("servicetest: setting up: %s", err)
+	}
+}